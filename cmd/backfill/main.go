@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/PavelDonchenko/sensor-go/db"
+	"github.com/PavelDonchenko/sensor-go/internal/storage"
+	"github.com/PavelDonchenko/sensor-go/pkg/generations"
+	"github.com/PavelDonchenko/sensor-go/pkg/logging"
+	"github.com/PavelDonchenko/sensor-go/pkg/postgres"
+	"github.com/PavelDonchenko/sensor-go/pkg/utils"
+	"github.com/PavelDonchenko/sensor-go/workers"
+)
+
+// backfill generates the history of the simulated sensors, e.g. a week of readings:
+//
+//	go run cmd/backfill/main.go -from 2023-07-07T00:00:00Z -till 2023-07-14T00:00:00Z
+func main() {
+	configPath := flag.String("config", "config.yaml", "path of the config file")
+	fromFlag := flag.String("from", "", "start of the period, RFC 3339 or UNIX timestamp, a day before till by default")
+	tillFlag := flag.String("till", "", "end of the period, RFC 3339 or UNIX timestamp, now by default")
+	seed := flag.Int64("seed", 0, "seed of the simulation, simulation.seed from the config by default")
+	scenarioPath := flag.String("scenario", "", "path of the scenario file, its events happen since the start of the period")
+	flag.Parse()
+
+	cfg := config.GetConfig(*configPath)
+
+	logger := logging.GetLogger()
+
+	var scenario *generations.Scenario
+	if *scenarioPath != "" {
+		var err error
+
+		scenario, err = generations.LoadScenario(*scenarioPath, *cfg)
+		if err != nil {
+			logger.Fatal(err)
+		}
+
+		scenario.Apply(cfg)
+	}
+
+	till, err := parseTime(*tillFlag, time.Now().UTC())
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	from, err := parseTime(*fromFlag, till.Add(-24*time.Hour))
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	if *seed != 0 {
+		cfg.Simulation.Seed = *seed
+	}
+
+	if cfg.Simulation.Seed == 0 {
+		cfg.Simulation.Seed = generations.RandomSeed()
+	}
+
+	logger.Infof("simulation seed %d", cfg.Simulation.Seed)
+
+	ctx := context.Background()
+
+	pool, err := postgres.NewClient(ctx, cfg)
+	if err != nil {
+		logger.Panic("error open postgres connection", err)
+	}
+
+	err = postgres.Migrate(db.Migrations, cfg)
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	sensorStorage := storage.NewDatabase(pool, *cfg, logger)
+
+	sensors, err := sensorStorage.GetAllSensors(ctx)
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	// the history starts with the sensors, they are created as of the start of the period
+	if len(sensors) == 0 {
+		logger.Info("Starting create new sensor and sensors group...")
+		sensorStorage.Clock = generations.NewManualClock(from)
+
+		if scenario != nil {
+			err = utils.GenerateScenarioSensors(ctx, *sensorStorage, scenario)
+		} else {
+			err = utils.GenerateSensors(ctx, *sensorStorage)
+		}
+		if err != nil {
+			logger.Panic(err)
+		}
+	}
+
+	species, err := generations.LoadSpecies(cfg.Species.Catalogue)
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	// the enabled fault rules of the config are injected into the history as well
+	faults, err := generations.NewFaults(*cfg)
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	logger.Infof("Starting backfill from %s till %s...", from.Format(time.RFC3339), till.Format(time.RFC3339))
+
+	started := time.Now()
+
+	count, err := workers.NewBackfill(ctx, sensorStorage, logger, *cfg, species, faults, scenario).Run(from, till)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	logger.Infof("backfilled %d measurements in %s", count, time.Since(started).Round(time.Millisecond))
+}
+
+// parseTime parses a RFC 3339 time or a UNIX timestamp, the empty value is the fallback.
+func parseTime(value string, fallback time.Time) (time.Time, error) {
+	if value == "" {
+		return fallback, nil
+	}
+
+	if unix, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(unix, 0).UTC(), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither a RFC 3339 time nor a UNIX timestamp", value)
+	}
+
+	return t.UTC(), nil
+}
@@ -2,17 +2,22 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/PavelDonchenko/sensor-go/config"
 	"github.com/PavelDonchenko/sensor-go/db"
 	"github.com/PavelDonchenko/sensor-go/internal/handler"
 	"github.com/PavelDonchenko/sensor-go/internal/service"
 	"github.com/PavelDonchenko/sensor-go/internal/storage"
+	"github.com/PavelDonchenko/sensor-go/internal/stream"
 	"github.com/PavelDonchenko/sensor-go/pkg/cache"
+	"github.com/PavelDonchenko/sensor-go/pkg/generations"
 	"github.com/PavelDonchenko/sensor-go/pkg/logging"
 	"github.com/PavelDonchenko/sensor-go/pkg/postgres"
 	"github.com/PavelDonchenko/sensor-go/pkg/utils"
@@ -27,13 +32,42 @@ import (
 // @description TEST API.
 // @contact.email przmld033@gmail.com
 // @BasePath /api
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
 func main() {
+	scenarioPath := flag.String("scenario", "", "path of the scenario file declaring the sensors and the events of the simulation")
+	replayPath := flag.String("replay", "", "path of the NDJSON recording to replay instead of simulating the sensors")
+	flag.Parse()
+
 	cfg := config.GetConfig("config.yaml")
 
+	logger := logging.GetLogger()
+
+	// the scenario replaces the configured groups, its seed and temperature models override the config
+	var scenario *generations.Scenario
+	if *scenarioPath != "" {
+		var err error
+
+		scenario, err = generations.LoadScenario(*scenarioPath, *cfg)
+		if err != nil {
+			logger.Fatal(err)
+		}
+
+		scenario.Apply(cfg)
+
+		logger.Infof("simulating scenario %q from %s", scenario.Name, *scenarioPath)
+	}
+
+	// the seed is fixed for the whole run, a random one is logged below to reproduce the run
+	if cfg.Simulation.Seed == 0 {
+		cfg.Simulation.Seed = generations.RandomSeed()
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	logger := logging.GetLogger()
+	logger.Infof("simulation seed %d", cfg.Simulation.Seed)
 
 	logger.Info("postgres initializing...")
 	pool, err := postgres.NewClient(ctx, cfg)
@@ -61,26 +95,162 @@ func main() {
 		logger.Panic(err)
 	}
 
-	if len(sensors) == 0 {
+	switch {
+	case *replayPath != "":
+		// the replay creates the sensors of the recording missing in the database
+	case len(sensors) == 0 && scenario != nil:
+		logger.Info("Starting place the scenario sensors...")
+		err = utils.GenerateScenarioSensors(ctx, *sensorStorage, scenario)
+		if err != nil {
+			logger.Panic(err)
+		}
+	case len(sensors) == 0:
 		logger.Info("Starting create new sensor and sensors group...")
 		err = utils.GenerateSensors(ctx, *sensorStorage)
 		if err != nil {
 			logger.Panic("maybe need change config.yaml sensor_generated to true", err)
 		}
+	case scenario != nil:
+		logger.Warnf("the database has %d sensors already, the scenario sensors are not placed", len(sensors))
+	}
+
+	species, err := generations.LoadSpecies(cfg.Species.Catalogue)
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	// hub fans the new readings out to the live streams
+	hub := stream.NewHub()
+
+	var clock generations.Clock = generations.RealClock{}
+	if cfg.Simulation.Speed != 1 {
+		logger.Infof("simulation runs %v times faster than the wall clock", cfg.Simulation.Speed)
+		clock = generations.NewAcceleratedClock(time.Now(), cfg.Simulation.Speed)
+	}
+
+	// faults are injected into the simulated readings by the enabled rules, the admin API toggles them
+	faults, err := generations.NewFaults(*cfg)
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	// supervisor restarts the workers which fail and lets them drain on the shutdown
+	supervisor := workers.NewSupervisor(ctx, logger, *cfg)
+
+	if cfg.Recorder.Path != "" {
+		recorder := workers.NewRecorder(ctx, logger, *cfg, hub)
+
+		// recorder is using to append every reading to the recording which the replay feeds back,
+		// it is started before the readings are generated or replayed, so it records them from the first one
+		logger.Info("Starting record readings...")
+		supervisor.Go("recorder", recorder.Process)
+	}
+
+	// detector scores every reading before it is saved, so it is shared by all the paths writing them
+	detector := workers.NewAnomalyDetector(ctx, sensorStorage, logger, *cfg, hub)
+
+	worker := workers.NewWorker(ctx, sensorStorage, logger, *cfg, hub, detector, species, faults, scenario, clock, supervisor)
+
+	if *replayPath != "" {
+		recording, err := os.Open(*replayPath)
+		if err != nil {
+			logger.Panic(err)
+		}
+
+		var replayClock generations.Clock = generations.RealClock{}
+		if cfg.Replay.Speed != 1 {
+			replayClock = generations.NewAcceleratedClock(time.Now(), cfg.Replay.Speed)
+		}
+
+		replay := workers.NewReplay(ctx, sensorStorage, logger, *cfg, hub, detector, replayClock)
+
+		// replay is using to feed the recorded readings back instead of the simulated ones
+		logger.Infof("Starting replay %s...", *replayPath)
+		supervisor.Go("replay", func() error {
+			defer recording.Close()
+
+			// a failed replay is not restarted, the readings replayed already would be saved twice
+			replayed, err := replay.Run(recording)
+			if err != nil {
+				logger.Errorf("replay stopped after %d readings: %v", replayed, err)
+				return nil
+			}
+
+			logger.Infof("replayed %d readings", replayed)
+
+			return nil
+		})
+	} else {
+		// worker is using to update sensor data
+		logger.Info("Starting generate data for sensors...")
+		go worker.Process()
 	}
 
-	worker := workers.NewWorker(ctx, sensorStorage, logger, *cfg)
+	aggregator := workers.NewAggregator(ctx, sensorStorage, logger, *cfg, clock)
+
+	// aggregator is using to roll raw sensor data up into statistics buckets
+	logger.Info("Starting aggregate statistics...")
+	supervisor.Go("aggregator", func() error {
+		aggregator.Process()
+		return nil
+	})
+
+	alerts := workers.NewAlertEvaluator(ctx, sensorStorage, logger, *cfg, hub)
 
-	// worker is using to update sensor data
-	logger.Info("Starting generate data for sensors...")
-	go worker.Process()
+	// alert evaluator is using to check the alert rules against every new reading
+	logger.Info("Starting evaluate alert rules...")
+	supervisor.Go("alert evaluator", func() error {
+		alerts.Process()
+		return nil
+	})
+
+	tracker := workers.NewLivenessTracker(ctx, sensorStorage, logger, *cfg, hub, clock)
+
+	// tracker is using to detect the sensors which stopped reporting their data
+	logger.Info("Starting track sensor liveness...")
+	supervisor.Go("liveness tracker", func() error {
+		tracker.Process()
+		return nil
+	})
+
+	// detector is using to flag the readings which deviate from the sensor history or from the neighbour sensors
+	logger.Info("Starting detect anomalies...")
+	supervisor.Go("anomaly detector", func() error {
+		detector.Process()
+		return nil
+	})
+
+	dispatcher := workers.NewWebhookDispatcher(ctx, sensorStorage, logger, *cfg, hub)
+
+	// dispatcher is using to deliver the readings and the events to the webhooks
+	logger.Info("Starting deliver webhooks...")
+	supervisor.Go("webhook dispatcher", func() error {
+		dispatcher.Process()
+		return nil
+	})
 
 	// Define a new Fiber app with config.
 	app := fiber.New(fiber.Config{
 		ReadTimeout: cfg.HTTP.ReadTimeOut,
 	})
 
-	sensorService := service.NewService(ctx, sensorStorage, logger, *cfg, redis)
+	sensorService := service.NewService(ctx, sensorStorage, logger, *cfg, redis, worker, hub, detector, tracker, species, faults, supervisor, clock)
+
+	if cfg.MQTT.Enabled {
+		bridge := workers.NewMQTTBridge(ctx, sensorService, logger, *cfg)
+
+		// bridge is using to receive measurements of the live sensors from the MQTT broker
+		logger.Info("Starting MQTT ingestion bridge...")
+		supervisor.Go("mqtt bridge", bridge.Process)
+	}
+
+	if cfg.Ingestion.APIKey == "" {
+		logger.Warnf("ingestion.api_key is not set, the ingestion endpoint is disabled")
+	}
+
+	if cfg.Admin.APIKey == "" {
+		logger.Warnf("admin.api_key is not set, the admin endpoints are disabled")
+	}
 
 	routes := handler.NewHandler(ctx, *cfg, sensorService)
 
@@ -88,20 +258,31 @@ func main() {
 	routes.RegisterSwagger(app)
 
 	// Start server with graceful shutdown.
-	StartServerWithGracefulShutdown(app, *cfg)
+	StartServerWithGracefulShutdown(app, *cfg, routes.CloseStreams)
+
+	// the workers stop once the server is down, the readings in flight are written before the exit
+	cancel()
+
+	if !supervisor.Wait(cfg.Supervisor.DrainTimeout) {
+		logger.Warnf("the workers did not drain in %s", cfg.Supervisor.DrainTimeout)
+	}
 }
 
 // StartServerWithGracefulShutdown function for starting server with a graceful shutdown.
-func StartServerWithGracefulShutdown(a *fiber.App, cfg config.Config) {
+// closeStreams ends the long-lived responses the shutdown would wait for.
+func StartServerWithGracefulShutdown(a *fiber.App, cfg config.Config, closeStreams func()) {
 	// Create channel for idle connections.
 	idleConnsClosed := make(chan struct{})
 
 	go func() {
 		sigint := make(chan os.Signal, 1)
-		signal.Notify(sigint, os.Interrupt) // Catch OS signals.
+		// Catch OS signals, docker stop sends SIGTERM.
+		signal.Notify(sigint, os.Interrupt, syscall.SIGTERM)
 		<-sigint
 
-		// Received an interrupt signal, shutdown.
+		// Received an interrupt or a termination signal, shutdown.
+		closeStreams()
+
 		if err := a.Shutdown(); err != nil {
 			// Error from closing listeners, or context timeout:
 			log.Printf("Oops... Server is not shutting down! Reason: %v", err)
@@ -1,9 +1,11 @@
 package config
 
 import (
+	"errors"
 	"log"
 	"time"
 
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
@@ -27,10 +29,192 @@ type Config struct {
 		Password   string `yaml:"password" env-default:"" env-required:"true" env:"REDIS_PASSWORD"`
 		Expiration int    `yaml:"expiration" env-default:"10" env-required:"true" env:"REDIS_EXPIRATION"`
 	} `yaml:"redis"`
+	Aggregation struct {
+		Interval time.Duration `yaml:"interval" env-default:"1m" env:"AGGREGATION_INTERVAL"`
+	} `yaml:"aggregation"`
+	Admin struct {
+		// APIKey authenticates the admin endpoints, they are not served if it is empty.
+		APIKey string `yaml:"api_key" env:"ADMIN_API_KEY"`
+	} `yaml:"admin"`
+	Ingestion struct {
+		// APIKey authenticates the live sensors, the ingestion endpoint is not served if it is empty.
+		APIKey       string `yaml:"api_key" env:"INGESTION_API_KEY"`
+		MaxBatchSize int    `yaml:"max_batch_size" env-default:"1000" env:"INGESTION_MAX_BATCH_SIZE"`
+		// MaxClockSkew is how far ahead of the server clock a measurement timestamp may be.
+		MaxClockSkew time.Duration `yaml:"max_clock_skew" env-default:"1m" env:"INGESTION_MAX_CLOCK_SKEW"`
+		// MaxAge is how old a measurement timestamp may be.
+		MaxAge time.Duration `yaml:"max_age" env-default:"168h" env:"INGESTION_MAX_AGE"`
+	} `yaml:"ingestion"`
+	MQTT struct {
+		Enabled  bool   `yaml:"enabled" env-default:"false" env:"MQTT_ENABLED"`
+		Broker   string `yaml:"broker" env-default:"tcp://localhost:1883" env:"MQTT_BROKER"`
+		ClientID string `yaml:"client_id" env-default:"sensor-go" env:"MQTT_CLIENT_ID"`
+		Username string `yaml:"username" env:"MQTT_USERNAME"`
+		Password string `yaml:"password" env:"MQTT_PASSWORD"`
+		// Topic is the topic pattern the sensors publish to, {group} and {index} make up the sensor codename.
+		Topic                string        `yaml:"topic" env-default:"sensors/{group}/{index}/data" env:"MQTT_TOPIC"`
+		QoS                  int           `yaml:"qos" env-default:"1" env:"MQTT_QOS"`
+		MaxAttempts          int           `yaml:"attempts" env-default:"5" env:"MQTT_ATTEMPTS"`
+		RetryDelay           time.Duration `yaml:"retry_delay" env-default:"1s" env:"MQTT_RETRY_DELAY"`
+		MaxRetryDelay        time.Duration `yaml:"max_retry_delay" env-default:"30s" env:"MQTT_MAX_RETRY_DELAY"`
+		MaxReconnectInterval time.Duration `yaml:"max_reconnect_interval" env-default:"1m" env:"MQTT_MAX_RECONNECT_INTERVAL"`
+	} `yaml:"mqtt"`
+	Stream struct {
+		// BufferSize is the number of readings buffered per subscriber, newer readings are dropped for a subscriber lagging behind.
+		BufferSize int           `yaml:"buffer_size" env-default:"256" env:"STREAM_BUFFER_SIZE"`
+		Heartbeat  time.Duration `yaml:"heartbeat" env-default:"15s" env:"STREAM_HEARTBEAT"`
+	} `yaml:"stream"`
+	Alerts struct {
+		// BufferSize is the number of readings waiting for the evaluation, newer readings are dropped if the evaluator lags behind.
+		BufferSize     int           `yaml:"buffer_size" env-default:"1024" env:"ALERTS_BUFFER_SIZE"`
+		ReloadInterval time.Duration `yaml:"reload_interval" env-default:"10s" env:"ALERTS_RELOAD_INTERVAL"`
+	} `yaml:"alerts"`
+	Webhooks struct {
+		// BufferSize is the number of events and deliveries waiting for the dispatcher, newer ones are dropped if it lags behind.
+		BufferSize     int           `yaml:"buffer_size" env-default:"1024" env:"WEBHOOKS_BUFFER_SIZE"`
+		Workers        int           `yaml:"workers" env-default:"4" env:"WEBHOOKS_WORKERS"`
+		Timeout        time.Duration `yaml:"timeout" env-default:"5s" env:"WEBHOOKS_TIMEOUT"`
+		MaxAttempts    int           `yaml:"attempts" env-default:"5" env:"WEBHOOKS_ATTEMPTS"`
+		RetryDelay     time.Duration `yaml:"retry_delay" env-default:"1s" env:"WEBHOOKS_RETRY_DELAY"`
+		MaxRetryDelay  time.Duration `yaml:"max_retry_delay" env-default:"1m" env:"WEBHOOKS_MAX_RETRY_DELAY"`
+		ReloadInterval time.Duration `yaml:"reload_interval" env-default:"10s" env:"WEBHOOKS_RELOAD_INTERVAL"`
+	} `yaml:"webhooks"`
+	Liveness struct {
+		// LateAfter and OfflineAfter are the numbers of the missed data output intervals after which a sensor is late or offline.
+		LateAfter     float64       `yaml:"late_after" env-default:"2" env:"LIVENESS_LATE_AFTER"`
+		OfflineAfter  float64       `yaml:"offline_after" env-default:"5" env:"LIVENESS_OFFLINE_AFTER"`
+		CheckInterval time.Duration `yaml:"check_interval" env-default:"5s" env:"LIVENESS_CHECK_INTERVAL"`
+		BufferSize    int           `yaml:"buffer_size" env-default:"1024" env:"LIVENESS_BUFFER_SIZE"`
+	} `yaml:"liveness"`
+	Anomaly struct {
+		// Window is the number of the latest readings of a sensor the rolling mean and deviation are computed from,
+		// a sensor is not scored against itself until it has MinSamples of them.
+		Window     int `yaml:"window" env-default:"60" env:"ANOMALY_WINDOW"`
+		MinSamples int `yaml:"min_samples" env-default:"10" env:"ANOMALY_MIN_SAMPLES"`
+		// Neighbours is the number of the nearest sensors a reading is compared to.
+		Neighbours int `yaml:"neighbours" env-default:"3" env:"ANOMALY_NEIGHBOURS"`
+		// Threshold is the score from which a reading is flagged, MinStdDev keeps the score finite for the flat series.
+		Threshold      float64       `yaml:"threshold" env-default:"3" env:"ANOMALY_THRESHOLD"`
+		MinStdDev      float64       `yaml:"min_std_dev" env-default:"0.1" env:"ANOMALY_MIN_STD_DEV"`
+		BufferSize     int           `yaml:"buffer_size" env-default:"1024" env:"ANOMALY_BUFFER_SIZE"`
+		ReloadInterval time.Duration `yaml:"reload_interval" env-default:"30s" env:"ANOMALY_RELOAD_INTERVAL"`
+	} `yaml:"anomaly"`
+	// Temperature is the simulated water temperature, Groups override the Default model for the named sensor groups.
+	Temperature struct {
+		Default TemperatureModel            `yaml:"default"`
+		Groups  map[string]TemperatureModel `yaml:"groups"`
+	} `yaml:"temperature"`
+	Simulation struct {
+		// Seed makes the simulation reproducible, the same seed generates the same sensors and readings.
+		// A random seed is used if it is zero, it is logged at the start to reproduce the run.
+		Seed int64 `yaml:"seed" env:"SIMULATION_SEED"`
+		// Speed is how many times faster than the wall clock the simulation runs, the readings are stamped with the
+		// simulation time, which runs ahead of the wall clock from the start if the speed is above 1.
+		Speed float64 `yaml:"speed" env-default:"1" env:"SIMULATION_SPEED"`
+	} `yaml:"simulation"`
+	Scheduler struct {
+		// Tick is how often the scheduler looks for the due readings, in the simulation time.
+		Tick time.Duration `yaml:"tick" env-default:"100ms" env:"SCHEDULER_TICK"`
+		// Workers is the number of the goroutines generating and writing the readings, a sensor is always handled by the same one.
+		Workers int `yaml:"workers" env-default:"4" env:"SCHEDULER_WORKERS"`
+		// BatchSize is the largest number of the due readings a worker writes by a single bulk insert.
+		BatchSize int `yaml:"batch_size" env-default:"500" env:"SCHEDULER_BATCH_SIZE"`
+		// QueueSize is the number of the due readings waiting for a worker, the scheduler waits while the queue is full.
+		QueueSize int `yaml:"queue_size" env-default:"1024" env:"SCHEDULER_QUEUE_SIZE"`
+	} `yaml:"scheduler"`
+	Supervisor struct {
+		// RestartBackoff is the wait before a failed task is restarted, it doubles with every failure in a row up to MaxBackoff.
+		RestartBackoff time.Duration `yaml:"restart_backoff" env-default:"1s" env:"SUPERVISOR_RESTART_BACKOFF"`
+		MaxBackoff     time.Duration `yaml:"max_backoff" env-default:"1m" env:"SUPERVISOR_MAX_BACKOFF"`
+		// MaxRestarts is the number of the failures in a row after which a task is given up, 0 restarts it forever.
+		MaxRestarts int `yaml:"max_restarts" env:"SUPERVISOR_MAX_RESTARTS"`
+		// DrainTimeout is how long the tasks may take to write the readings in flight after an interrupt.
+		DrainTimeout time.Duration `yaml:"drain_timeout" env-default:"10s" env:"SUPERVISOR_DRAIN_TIMEOUT"`
+		// WriteRetries is how many times a write failed by a transient storage error is retried,
+		// RetryBackoff is the wait before the first retry, it doubles with every retry.
+		WriteRetries int           `yaml:"write_retries" env-default:"3" env:"SUPERVISOR_WRITE_RETRIES"`
+		RetryBackoff time.Duration `yaml:"retry_backoff" env-default:"100ms" env:"SUPERVISOR_RETRY_BACKOFF"`
+		// WriteTimeout bounds every write, the writes do not stop with the root context, so the ones in flight drain.
+		WriteTimeout time.Duration `yaml:"write_timeout" env-default:"10s" env:"SUPERVISOR_WRITE_TIMEOUT"`
+	} `yaml:"supervisor"`
+	Backfill struct {
+		// BatchSize is the number of the measurements written by a single bulk insert.
+		BatchSize int `yaml:"batch_size" env-default:"5000" env:"BACKFILL_BATCH_SIZE"`
+	} `yaml:"backfill"`
+	Recorder struct {
+		// Path is the NDJSON file every reading of the pipeline is appended to, nothing is recorded if it is empty.
+		Path string `yaml:"path" env:"RECORDER_PATH"`
+		// BufferSize is the number of readings waiting for the recorder, newer readings are dropped if it lags behind.
+		BufferSize    int           `yaml:"buffer_size" env-default:"4096" env:"RECORDER_BUFFER_SIZE"`
+		FlushInterval time.Duration `yaml:"flush_interval" env-default:"1s" env:"RECORDER_FLUSH_INTERVAL"`
+	} `yaml:"recorder"`
+	Replay struct {
+		// Speed is how many times faster than recorded the readings are replayed.
+		Speed float64 `yaml:"speed" env-default:"1" env:"REPLAY_SPEED"`
+		// KeepTime keeps the recorded times of the readings, otherwise they are stamped with the time of the replay.
+		KeepTime bool `yaml:"keep_time" env:"REPLAY_KEEP_TIME"`
+		// DataOutputRate is the data output rate, in seconds, of the sensors the replay creates, the recording lacks it.
+		DataOutputRate int `yaml:"data_output_rate" env-default:"10" env:"REPLAY_DATA_OUTPUT_RATE"`
+	} `yaml:"replay"`
+	Species struct {
+		// Catalogue is the path of the YAML or JSON species catalogue, the embedded one is used if it is empty.
+		Catalogue string `yaml:"catalogue" env:"SPECIES_CATALOGUE"`
+	} `yaml:"species"`
+	Schools struct {
+		// Enabled moves the schooling species across the sensor field as schools, a sensor detects the schools passing
+		// within the DetectionRadius. Otherwise every sensor draws its schooling species on its own.
+		Enabled         bool    `yaml:"enabled" env-default:"true" env:"SCHOOLS_ENABLED"`
+		Count           int     `yaml:"count" env-default:"20" env:"SCHOOLS_COUNT"`
+		DetectionRadius float64 `yaml:"detection_radius" env-default:"3" env:"SCHOOLS_DETECTION_RADIUS"`
+		// Speed is the distance a school swims in a second, TurnRate is how fast its heading wanders, in radians per second.
+		Speed    float64 `yaml:"speed" env-default:"0.05" env:"SCHOOLS_SPEED"`
+		TurnRate float64 `yaml:"turn_rate" env-default:"0.05" env:"SCHOOLS_TURN_RATE"`
+		// FieldSize and MaxDepth bound the schools to x and y within ±FieldSize and z within -MaxDepth and the surface.
+		FieldSize float64 `yaml:"field_size" env-default:"12" env:"SCHOOLS_FIELD_SIZE"`
+		MaxDepth  float64 `yaml:"max_depth" env-default:"12" env:"SCHOOLS_MAX_DEPTH"`
+		// Step is the simulated time the schools move by at once, it must be positive.
+		Step time.Duration `yaml:"step" env-default:"1s" env:"SCHOOLS_STEP"`
+	} `yaml:"schools"`
+	Faults struct {
+		// Rules inject the faults into the simulated sensors, they may be toggled at runtime through the admin API.
+		Rules []domain.FaultRule `yaml:"rules"`
+	} `yaml:"faults"`
 	GroupNames         string `env-default:"Alpha, Beta, Gamma" env-required:"true" yaml:"group_names" env:"GROUP_NAMES"`
 	CountSensorInGroup int    `env-default:"5" env-required:"true" yaml:"sensors_count" env:"SENSORS_COUNT"`
 }
 
+// TemperatureModel describes the water temperature of a sensor group. The temperature is uniform down to the thermocline,
+// it drops by the gradient per meter below it until it reaches the deep water temperature. The diurnal cycle fades
+// with the depth, the seasonal one shifts the whole warm layer. Every sensor follows the profile with a random
+// deviation which reverts to zero, so the consecutive readings of a sensor stay close.
+type TemperatureModel struct {
+	Surface             float64 `yaml:"surface"`
+	ThermoclineDepth    float64 `yaml:"thermocline_depth"`
+	ThermoclineGradient float64 `yaml:"thermocline_gradient"`
+	Deep                float64 `yaml:"deep"`
+	// DiurnalAmplitude is the daily swing at the surface, peaking at DiurnalPeakHour UTC,
+	// DiurnalDepth is the depth over which the swing fades by the factor of e.
+	DiurnalAmplitude float64 `yaml:"diurnal_amplitude"`
+	DiurnalPeakHour  float64 `yaml:"diurnal_peak_hour"`
+	DiurnalDepth     float64 `yaml:"diurnal_depth"`
+	// SeasonalAmplitude is the yearly swing, peaking on the SeasonalPeakDay of the year.
+	SeasonalAmplitude float64 `yaml:"seasonal_amplitude"`
+	SeasonalPeakDay   float64 `yaml:"seasonal_peak_day"`
+	// Noise is the standard deviation of the sensor deviation from the profile,
+	// ReversionTime is how fast the deviation decays, the longer the smoother.
+	Noise         float64       `yaml:"noise"`
+	ReversionTime time.Duration `yaml:"reversion_time"`
+}
+
+// TemperatureModel returns the temperature model of the sensor group.
+func (c *Config) TemperatureModel(group string) TemperatureModel {
+	if model, ok := c.Temperature.Groups[group]; ok {
+		return model
+	}
+
+	return c.Temperature.Default
+}
+
 func GetConfig(path string) *Config {
 	log.Print("config init")
 
@@ -40,5 +224,42 @@ func GetConfig(path string) *Config {
 		log.Fatalf("error read config: %v", err)
 	}
 
+	if err := c.Validate(); err != nil {
+		log.Fatalf("error read config: %v", err)
+	}
+
 	return c
 }
+
+// Validate rejects the settings the simulation cannot run with.
+func (c *Config) Validate() error {
+	if c.Schools.Step <= 0 {
+		return errors.New("schools.step must be positive")
+	}
+
+	if c.Simulation.Speed <= 0 {
+		return errors.New("simulation.speed must be positive")
+	}
+
+	if c.Scheduler.Tick <= 0 || c.Scheduler.Workers <= 0 || c.Scheduler.BatchSize <= 0 {
+		return errors.New("scheduler.tick, scheduler.workers and scheduler.batch_size must be positive")
+	}
+
+	if c.Backfill.BatchSize <= 0 {
+		return errors.New("backfill.batch_size must be positive")
+	}
+
+	if c.Replay.Speed <= 0 {
+		return errors.New("replay.speed must be positive")
+	}
+
+	if c.Ingestion.MaxClockSkew < 0 {
+		return errors.New("ingestion.max_clock_skew must not be negative")
+	}
+
+	if c.Ingestion.MaxAge <= 0 {
+		return errors.New("ingestion.max_age must be positive")
+	}
+
+	return nil
+}
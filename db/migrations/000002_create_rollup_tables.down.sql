@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_detected_fish_created_at;
+DROP INDEX IF EXISTS idx_temperature_created_at;
+DROP TABLE IF EXISTS rollup_watermark;
+DROP TABLE IF EXISTS species_rollup;
+DROP TABLE IF EXISTS transparency_rollup;
+DROP TABLE IF EXISTS temperature_rollup;
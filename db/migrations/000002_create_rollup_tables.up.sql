@@ -0,0 +1,44 @@
+CREATE TABLE temperature_rollup (
+    bucket text NOT NULL,
+    bucket_start timestamp NOT NULL,
+    group_name text NOT NULL REFERENCES sensor_group (name) ON DELETE CASCADE,
+    sensorID uuid REFERENCES sensor (id) ON DELETE CASCADE,
+    value_count bigint NOT NULL,
+    value_sum double precision NOT NULL,
+    value_min double precision NOT NULL,
+    value_max double precision NOT NULL,
+    CONSTRAINT temperature_rollup_key UNIQUE NULLS NOT DISTINCT (bucket, bucket_start, group_name, sensorID)
+);
+
+CREATE TABLE transparency_rollup (
+    bucket text NOT NULL,
+    bucket_start timestamp NOT NULL,
+    group_name text NOT NULL REFERENCES sensor_group (name) ON DELETE CASCADE,
+    sensorID uuid REFERENCES sensor (id) ON DELETE CASCADE,
+    value_count bigint NOT NULL,
+    value_sum double precision NOT NULL,
+    value_min double precision NOT NULL,
+    value_max double precision NOT NULL,
+    CONSTRAINT transparency_rollup_key UNIQUE NULLS NOT DISTINCT (bucket, bucket_start, group_name, sensorID)
+);
+
+CREATE TABLE species_rollup (
+    bucket text NOT NULL,
+    bucket_start timestamp NOT NULL,
+    group_name text NOT NULL REFERENCES sensor_group (name) ON DELETE CASCADE,
+    sensorID uuid REFERENCES sensor (id) ON DELETE CASCADE,
+    name text NOT NULL,
+    count bigint NOT NULL,
+    CONSTRAINT species_rollup_key UNIQUE NULLS NOT DISTINCT (bucket, bucket_start, group_name, sensorID, name)
+);
+
+CREATE TABLE rollup_watermark (
+    bucket text PRIMARY KEY,
+    aggregated_till timestamp NOT NULL
+);
+
+CREATE INDEX idx_temperature_created_at ON temperature(created_at);
+CREATE INDEX idx_detected_fish_created_at ON detected_fish(created_at);
+CREATE INDEX idx_temperature_rollup_bucket ON temperature_rollup(bucket, group_name, bucket_start);
+CREATE INDEX idx_transparency_rollup_bucket ON transparency_rollup(bucket, group_name, bucket_start);
+CREATE INDEX idx_species_rollup_bucket ON species_rollup(bucket, group_name, bucket_start);
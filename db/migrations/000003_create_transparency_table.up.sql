@@ -0,0 +1,9 @@
+CREATE TABLE transparency (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    percent int NOT NULL CHECK ( percent between 0 and 100),
+    sensorID uuid REFERENCES sensor (id) ON DELETE CASCADE,
+    created_at timestamp NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_transparency_created_at ON transparency(created_at);
+CREATE INDEX idx_transparency_sensor_id ON transparency(sensorID);
@@ -0,0 +1,31 @@
+CREATE TABLE temperature (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    degrees double precision NOT NULL,
+    sensorID uuid REFERENCES sensor (id) ON DELETE CASCADE,
+    created_at timestamp NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE transparency (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    percent int NOT NULL CHECK ( percent between 0 and 100),
+    sensorID uuid REFERENCES sensor (id) ON DELETE CASCADE,
+    created_at timestamp NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_temperature_created_at ON temperature(created_at);
+CREATE INDEX idx_transparency_created_at ON transparency(created_at);
+CREATE INDEX idx_transparency_sensor_id ON transparency(sensorID);
+
+INSERT INTO temperature (degrees, sensorID, created_at)
+SELECT temperature, sensorID, created_at FROM measurement WHERE temperature IS NOT NULL;
+
+INSERT INTO transparency (percent, sensorID, created_at)
+SELECT transparency, sensorID, created_at FROM measurement WHERE transparency IS NOT NULL;
+
+ALTER TABLE sensor ADD COLUMN fishes uuid [];
+UPDATE sensor SET fishes = (SELECT ARRAY_AGG(df.id) FROM detected_fish df WHERE df.measurementID = sensor.measurementID);
+ALTER TABLE sensor DROP COLUMN measurementID;
+
+ALTER TABLE detected_fish DROP COLUMN measurementID;
+
+DROP TABLE IF EXISTS measurement;
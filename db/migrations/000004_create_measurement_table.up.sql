@@ -0,0 +1,26 @@
+CREATE TABLE measurement (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    sensorID uuid NOT NULL REFERENCES sensor (id) ON DELETE CASCADE,
+    temperature double precision,
+    transparency int CHECK ( transparency between 0 and 100),
+    created_at timestamp NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_measurement_sensor_created_at ON measurement(sensorID, created_at);
+CREATE INDEX idx_measurement_created_at ON measurement(created_at);
+
+-- the history written before measurements existed has no common identifier, keep it as partial measurements
+INSERT INTO measurement (sensorID, temperature, created_at)
+SELECT sensorID, degrees, created_at FROM temperature WHERE sensorID IS NOT NULL;
+
+INSERT INTO measurement (sensorID, transparency, created_at)
+SELECT sensorID, percent, created_at FROM transparency WHERE sensorID IS NOT NULL;
+
+ALTER TABLE detected_fish ADD COLUMN measurementID uuid REFERENCES measurement (id) ON DELETE CASCADE;
+CREATE INDEX idx_detected_fish_measurement_id ON detected_fish(measurementID);
+
+ALTER TABLE sensor ADD COLUMN measurementID uuid REFERENCES measurement (id) ON DELETE SET NULL;
+ALTER TABLE sensor DROP COLUMN fishes;
+
+DROP TABLE temperature;
+DROP TABLE transparency;
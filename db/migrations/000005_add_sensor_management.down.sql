@@ -0,0 +1,19 @@
+ALTER TABLE species_rollup DROP CONSTRAINT species_rollup_group_name_fkey;
+ALTER TABLE species_rollup ADD CONSTRAINT species_rollup_group_name_fkey
+    FOREIGN KEY (group_name) REFERENCES sensor_group (name) ON DELETE CASCADE;
+
+ALTER TABLE transparency_rollup DROP CONSTRAINT transparency_rollup_group_name_fkey;
+ALTER TABLE transparency_rollup ADD CONSTRAINT transparency_rollup_group_name_fkey
+    FOREIGN KEY (group_name) REFERENCES sensor_group (name) ON DELETE CASCADE;
+
+ALTER TABLE temperature_rollup DROP CONSTRAINT temperature_rollup_group_name_fkey;
+ALTER TABLE temperature_rollup ADD CONSTRAINT temperature_rollup_group_name_fkey
+    FOREIGN KEY (group_name) REFERENCES sensor_group (name) ON DELETE CASCADE;
+
+ALTER TABLE sensor DROP CONSTRAINT sensor_group_name_fkey;
+ALTER TABLE sensor ADD CONSTRAINT sensor_group_name_fkey
+    FOREIGN KEY (group_name) REFERENCES sensor_group (name) ON DELETE CASCADE;
+
+DROP INDEX IF EXISTS idx_sensor_codename;
+
+ALTER TABLE sensor_group DROP COLUMN last_in_group_id;
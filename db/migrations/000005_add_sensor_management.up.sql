@@ -0,0 +1,23 @@
+ALTER TABLE sensor_group ADD COLUMN last_in_group_id int NOT NULL DEFAULT 0;
+
+UPDATE sensor_group
+SET last_in_group_id = (SELECT COALESCE(MAX(in_group_id), 0) FROM sensor WHERE sensor.group_name = sensor_group.name);
+
+CREATE UNIQUE INDEX idx_sensor_codename ON sensor(group_name, in_group_id);
+
+-- groups can be renamed through the API, the name is referenced instead of the id
+ALTER TABLE sensor DROP CONSTRAINT sensor_group_name_fkey;
+ALTER TABLE sensor ADD CONSTRAINT sensor_group_name_fkey
+    FOREIGN KEY (group_name) REFERENCES sensor_group (name) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE temperature_rollup DROP CONSTRAINT temperature_rollup_group_name_fkey;
+ALTER TABLE temperature_rollup ADD CONSTRAINT temperature_rollup_group_name_fkey
+    FOREIGN KEY (group_name) REFERENCES sensor_group (name) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE transparency_rollup DROP CONSTRAINT transparency_rollup_group_name_fkey;
+ALTER TABLE transparency_rollup ADD CONSTRAINT transparency_rollup_group_name_fkey
+    FOREIGN KEY (group_name) REFERENCES sensor_group (name) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE species_rollup DROP CONSTRAINT species_rollup_group_name_fkey;
+ALTER TABLE species_rollup ADD CONSTRAINT species_rollup_group_name_fkey
+    FOREIGN KEY (group_name) REFERENCES sensor_group (name) ON DELETE CASCADE ON UPDATE CASCADE;
@@ -0,0 +1 @@
+ALTER TABLE sensor DROP COLUMN source;
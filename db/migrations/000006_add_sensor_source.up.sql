@@ -0,0 +1 @@
+ALTER TABLE sensor ADD COLUMN source text NOT NULL DEFAULT 'simulated' CHECK ( source IN ('simulated', 'live'));
@@ -0,0 +1,3 @@
+CREATE EXTENSION IF NOT EXISTS cube;
+
+CREATE INDEX idx_sensor_position ON sensor USING gist (cube(ARRAY[x, y, z]));
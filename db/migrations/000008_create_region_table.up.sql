@@ -0,0 +1,21 @@
+CREATE TABLE region (
+    name text PRIMARY KEY,
+    x_min double precision,
+    x_max double precision,
+    y_min double precision,
+    y_max double precision,
+    z_min double precision,
+    z_max double precision,
+    center_x double precision,
+    center_y double precision,
+    center_z double precision,
+    radius double precision,
+    created_at timestamp NOT NULL DEFAULT NOW(),
+    -- a region is either a box or a sphere
+    CONSTRAINT region_shape CHECK (
+        (radius IS NULL AND num_nulls(x_min, x_max, y_min, y_max, z_min, z_max) = 0
+            AND num_nonnulls(center_x, center_y, center_z) = 0)
+        OR (radius IS NOT NULL AND num_nulls(center_x, center_y, center_z) = 0
+            AND num_nonnulls(x_min, x_max, y_min, y_max, z_min, z_max) = 0)
+    )
+);
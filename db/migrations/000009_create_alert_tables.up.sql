@@ -0,0 +1,30 @@
+CREATE TABLE alert_rule (
+    id serial PRIMARY KEY,
+    name text NOT NULL,
+    metric text NOT NULL,
+    species text,
+    -- the scope of the rule, every sensor if none of them is set
+    group_name text REFERENCES sensor_group (name) ON DELETE CASCADE ON UPDATE CASCADE,
+    sensor_id uuid REFERENCES sensor (id) ON DELETE CASCADE,
+    region_name text REFERENCES region (name) ON DELETE CASCADE,
+    -- empty aggregation evaluates every sensor separately, otherwise the latest readings of the scope are aggregated
+    aggregation text NOT NULL DEFAULT '',
+    operator text NOT NULL,
+    threshold double precision NOT NULL,
+    consecutive int NOT NULL DEFAULT 1 CHECK ( consecutive > 0 ),
+    created_at timestamp NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE alert (
+    id bigserial PRIMARY KEY,
+    rule_id int NOT NULL REFERENCES alert_rule (id) ON DELETE CASCADE,
+    -- the sensor the alert fired for, NULL for the aggregated rules
+    sensor_id uuid REFERENCES sensor (id) ON DELETE CASCADE,
+    value double precision NOT NULL,
+    fired_at timestamp NOT NULL,
+    resolved_value double precision,
+    resolved_at timestamp
+);
+
+CREATE INDEX idx_alert_fired_at ON alert(fired_at);
+CREATE INDEX idx_alert_firing ON alert(rule_id) WHERE resolved_at IS NULL;
@@ -0,0 +1,36 @@
+CREATE TABLE webhook (
+    id serial PRIMARY KEY,
+    url text NOT NULL,
+    secret text NOT NULL,
+    -- the subscribed event types, every event type if empty
+    events text [] NOT NULL DEFAULT '{}',
+    created_at timestamp NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE webhook_delivery (
+    id bigserial PRIMARY KEY,
+    webhook_id int NOT NULL REFERENCES webhook (id) ON DELETE CASCADE,
+    event_id uuid NOT NULL,
+    event_type text NOT NULL,
+    attempt int NOT NULL,
+    status_code int,
+    error text,
+    success boolean NOT NULL,
+    duration_ms bigint NOT NULL,
+    created_at timestamp NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhook_delivery_webhook ON webhook_delivery(webhook_id, created_at);
+
+CREATE TABLE webhook_dead_letter (
+    id bigserial PRIMARY KEY,
+    webhook_id int NOT NULL REFERENCES webhook (id) ON DELETE CASCADE,
+    event_id uuid NOT NULL,
+    event_type text NOT NULL,
+    payload jsonb NOT NULL,
+    attempts int NOT NULL,
+    error text NOT NULL,
+    created_at timestamp NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhook_dead_letter_webhook ON webhook_dead_letter(webhook_id, created_at);
@@ -0,0 +1,15 @@
+CREATE TABLE anomaly (
+    id bigserial PRIMARY KEY,
+    measurement_id uuid NOT NULL REFERENCES measurement (id) ON DELETE CASCADE,
+    sensor_id uuid NOT NULL REFERENCES sensor (id) ON DELETE CASCADE,
+    metric text NOT NULL,
+    value double precision NOT NULL,
+    score double precision NOT NULL,
+    z_score double precision,
+    spatial_score double precision,
+    -- the time of the reading
+    created_at timestamp NOT NULL,
+    detected_at timestamp NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_anomaly_created_at ON anomaly(created_at);
@@ -0,0 +1 @@
+ALTER TABLE measurement DROP COLUMN IF EXISTS fault;
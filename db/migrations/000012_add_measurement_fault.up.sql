@@ -0,0 +1,3 @@
+-- the mode of the fault injected into a simulated measurement, NULL for the clean ones
+ALTER TABLE measurement ADD COLUMN IF NOT EXISTS fault text;
+CREATE INDEX idx_measurement_fault ON measurement(fault) WHERE fault IS NOT NULL;
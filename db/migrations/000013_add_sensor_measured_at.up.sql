@@ -0,0 +1,3 @@
+-- the time of the latest measurement of the sensor, updated_at also changes when the sensor is edited
+ALTER TABLE sensor ADD COLUMN IF NOT EXISTS measured_at timestamp;
+UPDATE sensor SET measured_at = updated_at WHERE measurementid IS NOT NULL;
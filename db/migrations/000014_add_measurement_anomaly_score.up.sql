@@ -0,0 +1,2 @@
+-- the anomaly score of a measurement at the time it was saved, NULL for the measurements saved unscored
+ALTER TABLE measurement ADD COLUMN IF NOT EXISTS anomaly_score double precision;
@@ -18,9 +18,14 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/v1/group/{groupName}/species": {
+        "/api/v1/admin/faults": {
             "get": {
-                "description": "Retrieves the current detected fish species for a sensor group based on the provided group name.",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves the rules injecting the faults into the simulated readings, the configured ones and the ones added through the API.",
                 "consumes": [
                     "application/json"
                 ],
@@ -28,40 +33,45 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "group"
-                ],
-                "summary": "Get current detected fish species for a sensor group",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Name of the sensor group",
-                        "name": "groupName",
-                        "in": "path",
-                        "required": true
-                    }
+                    "admin"
                 ],
+                "summary": "Get fault rules",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.ResponseDetectedFish"
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "boolean"
+                                },
+                                "msg": {
+                                    "type": "string"
+                                },
+                                "rules": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/domain.FaultRule"
+                                    }
+                                }
                             }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/group/{groupName}/species/top/{top}": {
-            "get": {
-                "description": "Retrieves the current top detected fish species for a sensor group based on the provided group name and other optional parameters.",
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Adds a rule injecting faults into the simulated readings, e.g. {\"mode\": \"spike\", \"group_name\": \"gamma\", \"probability\": 0.05, \"magnitude\": 10}. The mode is drift, stuck, spike, invalid, out_of_range, dropout or outage, probability is the chance a reading of a healthy sensor starts the fault. Drift, stuck and outage faults last the duration in seconds, drift changes the temperature by the magnitude in degrees per hour, downwards if it is negative, a spike by the positive magnitude up or down. The rule applies to a group or a sensor codename, every simulated sensor if neither is set, it is enabled unless enabled is false. The rules are kept in memory only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -69,47 +79,50 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "group"
+                    "admin"
                 ],
-                "summary": "Get current top detected fish species for a sensor group",
+                "summary": "Create a fault rule",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Name of the sensor group",
-                        "name": "groupName",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Number of top species to retrieve",
-                        "name": "top",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Start date for the period (UNIX timestamp)",
-                        "name": "from",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "End date for the period (UNIX timestamp)",
-                        "name": "till",
-                        "in": "query"
+                        "description": "rule to create",
+                        "name": "rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreateFaultRule"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.ResponseDetectedFish"
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "boolean"
+                                },
+                                "msg": {
+                                    "type": "string"
+                                },
+                                "rule": {
+                                    "$ref": "#/definitions/domain.FaultRule"
+                                }
                             }
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "422": {
                         "description": "Unprocessable Entity",
                         "schema": {
@@ -125,9 +138,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/group/{groupName}/temperature/average": {
+        "/api/v1/admin/faults/active": {
             "get": {
-                "description": "Retrieves the temperature in Celsius for a sensor group based on the provided group name.",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves the drift, stuck and outage faults the simulated sensors suffer from, the ones ending first first.",
                 "consumes": [
                     "application/json"
                 ],
@@ -135,27 +153,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "group"
-                ],
-                "summary": "Get temperature in Celsius for a sensor group",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Name of the sensor group",
-                        "name": "groupName",
-                        "in": "path",
-                        "required": true
-                    }
+                    "admin"
                 ],
+                "summary": "Get active faults",
                 "responses": {
                     "200": {
-                        "description": "temperature",
+                        "description": "OK",
                         "schema": {
-                            "type": "number"
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "boolean"
+                                },
+                                "faults": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/domain.ActiveFault"
+                                    }
+                                },
+                                "msg": {
+                                    "type": "string"
+                                }
+                            }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "string"
                         }
@@ -163,9 +186,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/group/{groupName}/transparency/average": {
-            "get": {
-                "description": "Retrieves the transparency percentage for a sensor group based on the provided group name.",
+        "/api/v1/admin/faults/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deletes a fault rule, the lasting faults it injected end at once.",
                 "consumes": [
                     "application/json"
                 ],
@@ -173,37 +201,46 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "group"
+                    "admin"
                 ],
-                "summary": "Get transparency percentage for a sensor group",
+                "summary": "Delete a fault rule",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Name of the sensor group",
-                        "name": "groupName",
+                        "type": "integer",
+                        "description": "id of the rule",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "transparency",
+                        "description": "OK",
                         "schema": {
-                            "type": "number"
+                            "type": "string"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/region/temperature/max": {
-            "get": {
-                "description": "Retrieves the current maximum temperature with optional parameters.",
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Enables or disables a fault rule, e.g. {\"enabled\": true}. The lasting faults of a disabled rule end at once.",
                 "consumes": [
                     "application/json"
                 ],
@@ -211,62 +248,59 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "region"
+                    "admin"
                 ],
-                "summary": "Get current maximum temperature according to region.",
+                "summary": "Toggle a fault rule",
                 "parameters": [
                     {
-                        "type": "number",
-                        "description": "minimum X coordinate",
-                        "name": "xMin",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "number",
-                        "description": "maximum X coordinate",
-                        "name": "xMax",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "number",
-                        "description": "maximum Y coordinate",
-                        "name": "yMax",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "number",
-                        "description": "minimum Y coordinate",
-                        "name": "yMix",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "number",
-                        "description": "minimum Z coordinate",
-                        "name": "zMin",
-                        "in": "query",
+                        "type": "integer",
+                        "description": "id of the rule",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "type": "number",
-                        "description": "maximum Z coordinate",
-                        "name": "zMax",
-                        "in": "query",
-                        "required": true
+                        "description": "whether the rule is enabled",
+                        "name": "rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UpdateFaultRule"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "number"
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "boolean"
+                                },
+                                "msg": {
+                                    "type": "string"
+                                },
+                                "rule": {
+                                    "$ref": "#/definitions/domain.FaultRule"
+                                }
+                            }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
                         "schema": {
                             "type": "string"
                         }
@@ -274,9 +308,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/region/temperature/min": {
+        "/api/v1/admin/scheduler": {
             "get": {
-                "description": "Retrieves the current minimum temperature with optional parameters.",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves the metrics of the scheduler generating the simulated readings: the scheduled sensors, the due readings waiting for a writer, the written, lost and failed readings and the lag between the time a reading is due and the time it is written, in milliseconds of the simulation clock.",
                 "consumes": [
                     "application/json"
                 ],
@@ -284,62 +323,83 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "region"
+                    "admin"
                 ],
-                "summary": "Get current minimum temperature according to region.",
-                "parameters": [
-                    {
-                        "type": "number",
-                        "description": "minimum X coordinate",
-                        "name": "xMin",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "number",
-                        "description": "maximum X coordinate",
-                        "name": "xMax",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "number",
-                        "description": "maximum Y coordinate",
-                        "name": "yMax",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "number",
-                        "description": "minimum Y coordinate",
-                        "name": "yMix",
-                        "in": "query",
-                        "required": true
+                "summary": "Get scheduler stats",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "boolean"
+                                },
+                                "msg": {
+                                    "type": "string"
+                                },
+                                "scheduler": {
+                                    "$ref": "#/definitions/domain.SchedulerStats"
+                                }
+                            }
+                        }
                     },
-                    {
-                        "type": "number",
-                        "description": "minimum Z coordinate",
-                        "name": "zMin",
-                        "in": "query",
-                        "required": true
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/tasks": {
+            "get": {
+                "security": [
                     {
-                        "type": "number",
-                        "description": "maximum Z coordinate",
-                        "name": "zMax",
-                        "in": "query",
-                        "required": true
+                        "ApiKeyAuth": []
                     }
                 ],
+                "description": "Retrieves the long-lived tasks of the service in the order they were started: the scheduler and the writers of the simulated readings and the other workers. A task is running, restarting after a failure, stopped or failed for good, with the number of its restarts and its latest failure.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get supervised tasks",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "number"
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "boolean"
+                                },
+                                "msg": {
+                                    "type": "string"
+                                },
+                                "tasks": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/domain.TaskStatus"
+                                    }
+                                }
+                            }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "string"
                         }
@@ -347,9 +407,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/sensor/{codename}/temperature/average": {
+        "/api/v1/alerts": {
             "get": {
-                "description": "Retrieves the average temperature based on the  optional parameters.",
+                "description": "Retrieves the fired alerts, the latest first. An alert is firing until the condition of its rule stops holding, then it is resolved.",
                 "consumes": [
                     "application/json"
                 ],
@@ -357,35 +417,55 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "sensor"
+                    "alerts"
                 ],
-                "summary": "Get average temperature from sensor",
+                "summary": "Get alerts",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "name of the group and id inside the group",
-                        "name": "codename",
-                        "in": "path",
-                        "required": true
+                        "type": "integer",
+                        "description": "id of the rule",
+                        "name": "rule",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Start date for the period (UNIX timestamp)",
+                        "description": "firing or resolved",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "UNIX timestamp, alerts fired since",
                         "name": "from",
                         "in": "query"
                     },
                     {
-                        "type": "string",
-                        "description": "End date for the period (UNIX timestamp)",
+                        "type": "integer",
+                        "description": "UNIX timestamp, alerts fired before",
                         "name": "till",
                         "in": "query"
-                    }
-                ],
+                    },
+                    {
+                        "type": "integer",
+                        "description": "maximum number of alerts, 100 by default",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "number"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Alert"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "string"
                         }
                     },
                     "500": {
@@ -396,19 +476,4039 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "domain.ResponseDetectedFish": {
-            "type": "object",
-            "properties": {
-                "count": {
-                    "type": "integer"
-                },
-                "name": {
-                    "type": "string"
+        },
+        "/api/v1/alerts/rules": {
+            "get": {
+                "description": "Retrieves all alert rules.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "Get alert rules",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.AlertRule"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a rule evaluated on every new reading, e.g. {\"name\": \"hot gamma\", \"metric\": \"temperature\", \"group_name\": \"gamma\", \"operator\": \"\u003e\", \"threshold\": 30, \"consecutive\": 2}. The rule applies to a group, a sensor codename or a named region, every sensor if none of them is set. Without an aggregation every sensor is evaluated on its own, avg, min or max aggregates the latest readings of all the sensors in the scope. A species rule compares the count of the named species.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "Create an alert rule",
+                "parameters": [
+                    {
+                        "description": "rule to create",
+                        "name": "rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreateAlertRule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AlertRule"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/alerts/rules/{id}": {
+            "delete": {
+                "description": "Deletes an alert rule together with its alert history.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "Delete an alert rule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id of the rule",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/anomalies": {
+            "get": {
+                "description": "Retrieves the readings whose temperature or transparency deviates from the rolling mean of the sensor (z_score) or from the latest readings of its nearest sensors (spatial_score) by at least anomaly.threshold standard deviations, the latest first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "anomalies"
+                ],
+                "summary": "Get anomalies",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name of the sensor group",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "UNIX timestamp, readings taken since",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "UNIX timestamp, readings taken before",
+                        "name": "till",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "maximum number of anomalies, 100 by default",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Anomaly"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/group/{groupName}/series": {
+            "get": {
+                "description": "Retrieves the metric values reported by all sensors of a group aggregated into buckets, the start of the period is rounded down to the bucket start.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group"
+                ],
+                "summary": "Get a time series for a sensor group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name of the sensor group",
+                        "name": "groupName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "temperature or transparency",
+                        "name": "metric",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date for the period (UNIX timestamp)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date for the period (UNIX timestamp)",
+                        "name": "till",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "1m, 1h or 1d, 1h by default",
+                        "name": "bucket",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "avg, min, max or p95, avg by default",
+                        "name": "agg",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.SeriesPoint"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
                 }
             }
+        },
+        "/api/v1/group/{groupName}/species": {
+            "get": {
+                "description": "Retrieves the current detected fish species for a sensor group based on the provided group name.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group"
+                ],
+                "summary": "Get current detected fish species for a sensor group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name of the sensor group",
+                        "name": "groupName",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.ResponseDetectedFish"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/group/{groupName}/species/top/{top}": {
+            "get": {
+                "description": "Retrieves the current top detected fish species for a sensor group based on the provided group name and other optional parameters.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group"
+                ],
+                "summary": "Get current top detected fish species for a sensor group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name of the sensor group",
+                        "name": "groupName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of top species to retrieve",
+                        "name": "top",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date for the period (UNIX timestamp)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date for the period (UNIX timestamp), now by default",
+                        "name": "till",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.ResponseDetectedFish"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/group/{groupName}/temperature/average": {
+            "get": {
+                "description": "Retrieves the temperature in Celsius for a sensor group based on the provided group name.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group"
+                ],
+                "summary": "Get temperature in Celsius for a sensor group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name of the sensor group",
+                        "name": "groupName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "leave the offline sensors out of the average",
+                        "name": "excludeOffline",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "temperature",
+                        "schema": {
+                            "type": "number"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/group/{groupName}/transparency/average": {
+            "get": {
+                "description": "Retrieves the current transparency percentage for a sensor group, or the historical average for the period if from and till are provided.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group"
+                ],
+                "summary": "Get transparency percentage for a sensor group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name of the sensor group",
+                        "name": "groupName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date for the period (UNIX timestamp)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date for the period (UNIX timestamp), now by default",
+                        "name": "till",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "leave the offline sensors out of the current average",
+                        "name": "excludeOffline",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "transparency",
+                        "schema": {
+                            "type": "number"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/groups": {
+            "get": {
+                "description": "Retrieves all sensor groups with their sensors.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Get sensor groups",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.SensorGroup"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Registers a new sensor group, the group name must consist of lowercase letters only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Create a sensor group",
+                "parameters": [
+                    {
+                        "description": "group to create",
+                        "name": "group",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreateGroup"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SensorGroup"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/groups/{groupName}": {
+            "get": {
+                "description": "Retrieves a sensor group with its sensors based on the provided group name.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Get a sensor group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name of the sensor group",
+                        "name": "groupName",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SensorGroup"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deletes a sensor group together with its sensors and their data, the data generation for them stops.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Delete a sensor group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name of the sensor group",
+                        "name": "groupName",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Renames a sensor group, the codenames of its sensors follow the new name.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Rename a sensor group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name of the sensor group",
+                        "name": "groupName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "new group name",
+                        "name": "group",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UpdateGroup"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SensorGroup"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/region/sensors": {
+            "get": {
+                "description": "Retrieves the sensors inside the region, these are the sensors the region statistics are computed from.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "region"
+                ],
+                "summary": "Get sensors according to region.",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "minimum X coordinate of the box",
+                        "name": "xMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "maximum X coordinate of the box",
+                        "name": "xMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "minimum Y coordinate of the box",
+                        "name": "yMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "maximum Y coordinate of the box",
+                        "name": "yMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "minimum Z coordinate of the box",
+                        "name": "zMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "maximum Z coordinate of the box",
+                        "name": "zMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "X coordinate of the sphere center",
+                        "name": "x",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Y coordinate of the sphere center",
+                        "name": "y",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Z coordinate of the sphere center",
+                        "name": "z",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "radius of the sphere, the box is used if it is not provided",
+                        "name": "radius",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "name of a persisted region, used instead of the coordinates",
+                        "name": "region",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Sensor"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/region/species": {
+            "get": {
+                "description": "Retrieves the species detected by the last measurements of the sensors inside the region.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "region"
+                ],
+                "summary": "Get current detected fish species according to region.",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "minimum X coordinate of the box",
+                        "name": "xMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "maximum X coordinate of the box",
+                        "name": "xMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "minimum Y coordinate of the box",
+                        "name": "yMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "maximum Y coordinate of the box",
+                        "name": "yMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "minimum Z coordinate of the box",
+                        "name": "zMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "maximum Z coordinate of the box",
+                        "name": "zMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "X coordinate of the sphere center",
+                        "name": "x",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Y coordinate of the sphere center",
+                        "name": "y",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Z coordinate of the sphere center",
+                        "name": "z",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "radius of the sphere, the box is used if it is not provided",
+                        "name": "radius",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "name of a persisted region, used instead of the coordinates",
+                        "name": "region",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.ResponseDetectedFish"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/region/species/top/{top}": {
+            "get": {
+                "description": "Retrieves the top species detected by the last measurements of the sensors inside the region, or during the period if from and till are provided.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "region"
+                ],
+                "summary": "Get top detected fish species according to region.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number of top species to retrieve",
+                        "name": "top",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "minimum X coordinate of the box",
+                        "name": "xMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "maximum X coordinate of the box",
+                        "name": "xMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "minimum Y coordinate of the box",
+                        "name": "yMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "maximum Y coordinate of the box",
+                        "name": "yMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "minimum Z coordinate of the box",
+                        "name": "zMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "maximum Z coordinate of the box",
+                        "name": "zMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "X coordinate of the sphere center",
+                        "name": "x",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Y coordinate of the sphere center",
+                        "name": "y",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Z coordinate of the sphere center",
+                        "name": "z",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "radius of the sphere, the box is used if it is not provided",
+                        "name": "radius",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "name of a persisted region, used instead of the coordinates",
+                        "name": "region",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date for the period (UNIX timestamp)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date for the period (UNIX timestamp), now by default",
+                        "name": "till",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.ResponseDetectedFish"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/region/temperature/average": {
+            "get": {
+                "description": "Retrieves the current average temperature of the sensors inside the region, or the average they measured during the period if from and till are provided.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "region"
+                ],
+                "summary": "Get average temperature according to region.",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "minimum X coordinate of the box",
+                        "name": "xMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "maximum X coordinate of the box",
+                        "name": "xMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "minimum Y coordinate of the box",
+                        "name": "yMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "maximum Y coordinate of the box",
+                        "name": "yMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "minimum Z coordinate of the box",
+                        "name": "zMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "maximum Z coordinate of the box",
+                        "name": "zMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "X coordinate of the sphere center",
+                        "name": "x",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Y coordinate of the sphere center",
+                        "name": "y",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Z coordinate of the sphere center",
+                        "name": "z",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "radius of the sphere, the box is used if it is not provided",
+                        "name": "radius",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "name of a persisted region, used instead of the coordinates",
+                        "name": "region",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date for the period (UNIX timestamp)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date for the period (UNIX timestamp), now by default",
+                        "name": "till",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "number"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/region/temperature/max": {
+            "get": {
+                "description": "Retrieves the current maximum temperature of the sensors inside the region, or the maximum they measured during the period if from and till are provided.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "region"
+                ],
+                "summary": "Get maximum temperature according to region.",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "minimum X coordinate of the box",
+                        "name": "xMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "maximum X coordinate of the box",
+                        "name": "xMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "minimum Y coordinate of the box",
+                        "name": "yMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "maximum Y coordinate of the box",
+                        "name": "yMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "minimum Z coordinate of the box",
+                        "name": "zMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "maximum Z coordinate of the box",
+                        "name": "zMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "X coordinate of the sphere center",
+                        "name": "x",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Y coordinate of the sphere center",
+                        "name": "y",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Z coordinate of the sphere center",
+                        "name": "z",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "radius of the sphere, the box is used if it is not provided",
+                        "name": "radius",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "name of a persisted region, used instead of the coordinates",
+                        "name": "region",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date for the period (UNIX timestamp)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date for the period (UNIX timestamp), now by default",
+                        "name": "till",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "number"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/region/temperature/min": {
+            "get": {
+                "description": "Retrieves the current minimum temperature of the sensors inside the region, or the minimum they measured during the period if from and till are provided.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "region"
+                ],
+                "summary": "Get minimum temperature according to region.",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "minimum X coordinate of the box",
+                        "name": "xMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "maximum X coordinate of the box",
+                        "name": "xMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "minimum Y coordinate of the box",
+                        "name": "yMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "maximum Y coordinate of the box",
+                        "name": "yMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "minimum Z coordinate of the box",
+                        "name": "zMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "maximum Z coordinate of the box",
+                        "name": "zMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "X coordinate of the sphere center",
+                        "name": "x",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Y coordinate of the sphere center",
+                        "name": "y",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Z coordinate of the sphere center",
+                        "name": "z",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "radius of the sphere, the box is used if it is not provided",
+                        "name": "radius",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "name of a persisted region, used instead of the coordinates",
+                        "name": "region",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date for the period (UNIX timestamp)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date for the period (UNIX timestamp), now by default",
+                        "name": "till",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "number"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/region/temperature/percentile": {
+            "get": {
+                "description": "Retrieves the percentile of the current temperature of the sensors inside the region, or of the temperature they measured during the period if from and till are provided.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "region"
+                ],
+                "summary": "Get temperature percentile according to region.",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "minimum X coordinate of the box",
+                        "name": "xMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "maximum X coordinate of the box",
+                        "name": "xMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "minimum Y coordinate of the box",
+                        "name": "yMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "maximum Y coordinate of the box",
+                        "name": "yMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "minimum Z coordinate of the box",
+                        "name": "zMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "maximum Z coordinate of the box",
+                        "name": "zMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "X coordinate of the sphere center",
+                        "name": "x",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Y coordinate of the sphere center",
+                        "name": "y",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Z coordinate of the sphere center",
+                        "name": "z",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "radius of the sphere, the box is used if it is not provided",
+                        "name": "radius",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "name of a persisted region, used instead of the coordinates",
+                        "name": "region",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "percentile between 0 and 100, 95 by default",
+                        "name": "p",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date for the period (UNIX timestamp)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date for the period (UNIX timestamp), now by default",
+                        "name": "till",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "number"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/region/transparency/average": {
+            "get": {
+                "description": "Retrieves the current average transparency of the sensors inside the region, or the average they measured during the period if from and till are provided.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "region"
+                ],
+                "summary": "Get average transparency according to region.",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "minimum X coordinate of the box",
+                        "name": "xMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "maximum X coordinate of the box",
+                        "name": "xMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "minimum Y coordinate of the box",
+                        "name": "yMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "maximum Y coordinate of the box",
+                        "name": "yMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "minimum Z coordinate of the box",
+                        "name": "zMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "maximum Z coordinate of the box",
+                        "name": "zMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "X coordinate of the sphere center",
+                        "name": "x",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Y coordinate of the sphere center",
+                        "name": "y",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Z coordinate of the sphere center",
+                        "name": "z",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "radius of the sphere, the box is used if it is not provided",
+                        "name": "radius",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "name of a persisted region, used instead of the coordinates",
+                        "name": "region",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date for the period (UNIX timestamp)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date for the period (UNIX timestamp), now by default",
+                        "name": "till",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "number"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/regions": {
+            "get": {
+                "description": "Retrieves all named regions.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "regions"
+                ],
+                "summary": "Get named regions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.NamedRegion"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Persists a box or a sphere under a name, the name can be passed to the region routes instead of the coordinates.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "regions"
+                ],
+                "summary": "Create a named region",
+                "parameters": [
+                    {
+                        "description": "region to create, either box or sphere",
+                        "name": "region",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreateRegion"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.NamedRegion"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/regions/{name}": {
+            "get": {
+                "description": "Retrieves a named region by its name.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "regions"
+                ],
+                "summary": "Get a named region",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name of the region",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.NamedRegion"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes a named region, the sensors inside it are not affected.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "regions"
+                ],
+                "summary": "Delete a named region",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name of the region",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/regions/{name}/series": {
+            "get": {
+                "description": "Retrieves the metric values reported by the sensors currently inside the named region aggregated into buckets, the start of the period is rounded down to the bucket start.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "regions"
+                ],
+                "summary": "Get a time series for a named region",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name of the region",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "temperature or transparency",
+                        "name": "metric",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date for the period (UNIX timestamp)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date for the period (UNIX timestamp)",
+                        "name": "till",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "1m, 1h or 1d, 1h by default",
+                        "name": "bucket",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "avg, min, max or p95, avg by default",
+                        "name": "agg",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.SeriesPoint"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sensor/{codename}/measurements": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Saves a single measurement or a batch of measurements reported by a sensor. The body is either one measurement object or an array of them, the whole batch is rejected if any measurement is invalid.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sensor"
+                ],
+                "summary": "Ingest sensor measurements",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "name of the group and id inside the group",
+                        "name": "codename",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "measurement or array of measurements",
+                        "name": "measurements",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.IngestMeasurement"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Measurement"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sensor/{codename}/series": {
+            "get": {
+                "description": "Retrieves the metric values reported by a sensor aggregated into buckets, the start of the period is rounded down to the bucket start.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sensor"
+                ],
+                "summary": "Get a time series from sensor",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "name of the group and id inside the group",
+                        "name": "codename",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "temperature or transparency",
+                        "name": "metric",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date for the period (UNIX timestamp)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date for the period (UNIX timestamp)",
+                        "name": "till",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "1m, 1h or 1d, 1h by default",
+                        "name": "bucket",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "avg, min, max or p95, avg by default",
+                        "name": "agg",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.SeriesPoint"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sensor/{codename}/temperature/average": {
+            "get": {
+                "description": "Retrieves the average temperature based on the  optional parameters.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sensor"
+                ],
+                "summary": "Get average temperature from sensor",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "name of the group and id inside the group",
+                        "name": "codename",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date for the period (UNIX timestamp)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date for the period (UNIX timestamp), now by default",
+                        "name": "till",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "number"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sensor/{codename}/transparency/average": {
+            "get": {
+                "description": "Retrieves the average transparency based on the optional parameters.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sensor"
+                ],
+                "summary": "Get average transparency from sensor",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "name of the group and id inside the group",
+                        "name": "codename",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date for the period (UNIX timestamp)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date for the period (UNIX timestamp), now by default",
+                        "name": "till",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "number"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sensors": {
+            "get": {
+                "description": "Retrieves all sensors or the sensors of the group provided in the group parameter.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sensors"
+                ],
+                "summary": "Get sensors",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name of the sensor group",
+                        "name": "group",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Sensor"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Registers a new sensor with explicit coordinates and data output rate, the index inside the group is assigned automatically and the data generation starts right away.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sensors"
+                ],
+                "summary": "Register a sensor",
+                "parameters": [
+                    {
+                        "description": "sensor to register",
+                        "name": "sensor",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreateSensor"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Sensor"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sensors/nearest": {
+            "get": {
+                "description": "Retrieves the k sensors closest to the point with their distances, nearest first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sensors"
+                ],
+                "summary": "Get the nearest sensors",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "X coordinate of the point",
+                        "name": "x",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Y coordinate of the point",
+                        "name": "y",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Z coordinate of the point",
+                        "name": "z",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "number of sensors, 1 by default",
+                        "name": "k",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.SensorDistance"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sensors/status": {
+            "get": {
+                "description": "Retrieves whether the sensors report their data at the expected cadence: a sensor which missed liveness.late_after data output intervals is late, after liveness.offline_after intervals it is offline, its next reading makes it online again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sensors"
+                ],
+                "summary": "Get sensor liveness",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name of the sensor group",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "online, late or offline",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.SensorStatus"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sensors/{codename}": {
+            "get": {
+                "description": "Retrieves a sensor by its codename.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sensors"
+                ],
+                "summary": "Get a sensor",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "name of the group and id inside the group",
+                        "name": "codename",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Sensor"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deletes a sensor together with its data, the data generation for it stops.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sensors"
+                ],
+                "summary": "Retire a sensor",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "name of the group and id inside the group",
+                        "name": "codename",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Changes the coordinates, the data output rate or the group of a sensor, the data generation is retuned right away. A sensor moved to another group gets the next index of that group.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sensors"
+                ],
+                "summary": "Update a sensor",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "name of the group and id inside the group",
+                        "name": "codename",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "fields to change",
+                        "name": "sensor",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UpdateSensor"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Sensor"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/species": {
+            "get": {
+                "description": "Retrieves the fish species the sensors detect with their scientific names, habitats, preferred depth (meters) and temperature (Celsius) ranges, schooling behaviour and rarity.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "species"
+                ],
+                "summary": "Get the species catalogue",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "only the species of the habitat, e.g. reef",
+                        "name": "habitat",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Species"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stream": {
+            "get": {
+                "description": "Pushes every new reading, generated or ingested, over a WebSocket if the request is a WebSocket upgrade, or as Server-Sent Events otherwise. The readings can be filtered by group, sensor codename and region and narrowed down to some metrics. Readings are dropped for a client which does not keep up, the number of dropped readings is reported periodically.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stream"
+                ],
+                "summary": "Stream live sensor readings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name of the sensor group",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "name of the group and id inside the group",
+                        "name": "codename",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "name of a persisted region",
+                        "name": "region",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated temperature, transparency and species, all of them by default",
+                        "name": "metric",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Reading"
+                        }
+                    },
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Reading"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves all webhooks, the secrets are not returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get webhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Webhook"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Subscribes the url to the events listed in events, every event type if it is empty: reading.created, alert.firing, alert.resolved, sensor.failed, storage.error. Events are POSTed as JSON with the X-Webhook-Signature header, the HMAC-SHA256 of the body keyed with the secret, failed deliveries are retried with an exponential backoff. The secret is not returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Create a webhook",
+                "parameters": [
+                    {
+                        "description": "webhook to create",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreateWebhook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Webhook"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/webhooks/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deletes a webhook together with its delivery log and dead letters.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete a webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id of the webhook",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/webhooks/{id}/dead-letters": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves the events which failed every delivery attempt or did not fit into the delivery queue, with their payload, the latest first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get webhook dead letters",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id of the webhook",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "maximum number of dead letters, 100 by default",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.DeadLetter"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves the delivery attempts of a webhook with their response status or error, the latest first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id of the webhook",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "maximum number of deliveries, 100 by default",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "domain.ActiveFault": {
+            "type": "object",
+            "properties": {
+                "codename": {
+                    "type": "string"
+                },
+                "mode": {
+                    "$ref": "#/definitions/domain.FaultMode"
+                },
+                "rule_id": {
+                    "type": "integer"
+                },
+                "sensor_id": {
+                    "type": "string"
+                },
+                "since": {
+                    "type": "string"
+                },
+                "until": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Aggregation": {
+            "type": "string",
+            "enum": [
+                "avg",
+                "min",
+                "max",
+                "p95",
+                "percentile"
+            ],
+            "x-enum-varnames": [
+                "AggregationAvg",
+                "AggregationMin",
+                "AggregationMax",
+                "AggregationP95",
+                "AggregationPercentile"
+            ]
+        },
+        "domain.Alert": {
+            "type": "object",
+            "properties": {
+                "codename": {
+                    "type": "string"
+                },
+                "fired_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "resolved_at": {
+                    "type": "string"
+                },
+                "resolved_value": {
+                    "type": "number"
+                },
+                "rule_id": {
+                    "type": "integer"
+                },
+                "rule_name": {
+                    "type": "string"
+                },
+                "sensor_id": {
+                    "type": "string"
+                },
+                "state": {
+                    "$ref": "#/definitions/domain.AlertState"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.AlertRule": {
+            "type": "object",
+            "properties": {
+                "aggregation": {
+                    "$ref": "#/definitions/domain.Aggregation"
+                },
+                "codename": {
+                    "type": "string"
+                },
+                "consecutive": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "group_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "metric": {
+                    "$ref": "#/definitions/domain.Metric"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "operator": {
+                    "$ref": "#/definitions/domain.Operator"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "sensor_id": {
+                    "type": "string"
+                },
+                "species": {
+                    "type": "string"
+                },
+                "threshold": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.AlertState": {
+            "type": "string",
+            "enum": [
+                "firing",
+                "resolved"
+            ],
+            "x-enum-varnames": [
+                "AlertFiring",
+                "AlertResolved"
+            ]
+        },
+        "domain.Anomaly": {
+            "type": "object",
+            "properties": {
+                "codename": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "detected_at": {
+                    "type": "string"
+                },
+                "group_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "measurement_id": {
+                    "type": "string"
+                },
+                "metric": {
+                    "$ref": "#/definitions/domain.Metric"
+                },
+                "score": {
+                    "type": "number"
+                },
+                "sensor_id": {
+                    "type": "string"
+                },
+                "spatial_score": {
+                    "type": "number"
+                },
+                "value": {
+                    "type": "number"
+                },
+                "z_score": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.Codename": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "sensor_group_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.Coordinates": {
+            "type": "object",
+            "properties": {
+                "x": {
+                    "type": "number"
+                },
+                "y": {
+                    "type": "number"
+                },
+                "z": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.CreateAlertRule": {
+            "type": "object",
+            "properties": {
+                "aggregation": {
+                    "$ref": "#/definitions/domain.Aggregation"
+                },
+                "codename": {
+                    "type": "string"
+                },
+                "consecutive": {
+                    "type": "integer"
+                },
+                "group_name": {
+                    "type": "string"
+                },
+                "metric": {
+                    "$ref": "#/definitions/domain.Metric"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "operator": {
+                    "$ref": "#/definitions/domain.Operator"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "species": {
+                    "type": "string"
+                },
+                "threshold": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.CreateFaultRule": {
+            "type": "object",
+            "properties": {
+                "codename": {
+                    "type": "string"
+                },
+                "duration": {
+                    "type": "integer"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "group_name": {
+                    "type": "string"
+                },
+                "magnitude": {
+                    "type": "number"
+                },
+                "mode": {
+                    "$ref": "#/definitions/domain.FaultMode"
+                },
+                "probability": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.CreateGroup": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.CreateRegion": {
+            "type": "object",
+            "properties": {
+                "box": {
+                    "$ref": "#/definitions/domain.Region"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "sphere": {
+                    "$ref": "#/definitions/domain.Sphere"
+                }
+            }
+        },
+        "domain.CreateSensor": {
+            "type": "object",
+            "properties": {
+                "coordinates": {
+                    "$ref": "#/definitions/domain.Coordinates"
+                },
+                "data_output_rate": {
+                    "type": "integer"
+                },
+                "group_name": {
+                    "type": "string"
+                },
+                "source": {
+                    "$ref": "#/definitions/domain.SensorSource"
+                }
+            }
+        },
+        "domain.CreateWebhook": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.EventType"
+                    }
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.DeadLetter": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "event_type": {
+                    "$ref": "#/definitions/domain.EventType"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "webhook_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.DetectedFish": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "measurement_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "sensor_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.EventType": {
+            "type": "string",
+            "enum": [
+                "reading.created",
+                "alert.firing",
+                "alert.resolved",
+                "sensor.failed",
+                "storage.error",
+                "sensor.offline",
+                "sensor.online",
+                "anomaly.detected"
+            ],
+            "x-enum-varnames": [
+                "EventReadingCreated",
+                "EventAlertFiring",
+                "EventAlertResolved",
+                "EventSensorFailed",
+                "EventStorageError",
+                "EventSensorOffline",
+                "EventSensorOnline",
+                "EventAnomalyDetected"
+            ]
+        },
+        "domain.FaultMode": {
+            "type": "string",
+            "enum": [
+                "drift",
+                "stuck",
+                "spike",
+                "invalid",
+                "out_of_range",
+                "dropout",
+                "outage"
+            ],
+            "x-enum-varnames": [
+                "FaultDrift",
+                "FaultStuck",
+                "FaultSpike",
+                "FaultInvalid",
+                "FaultOutOfRange",
+                "FaultDropout",
+                "FaultOutage"
+            ]
+        },
+        "domain.FaultRule": {
+            "type": "object",
+            "properties": {
+                "codename": {
+                    "type": "string"
+                },
+                "duration": {
+                    "type": "integer"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "group_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "magnitude": {
+                    "type": "number"
+                },
+                "mode": {
+                    "$ref": "#/definitions/domain.FaultMode"
+                },
+                "probability": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.IngestDetectedFish": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.IngestMeasurement": {
+            "type": "object",
+            "properties": {
+                "detected_fish": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.IngestDetectedFish"
+                    }
+                },
+                "temperature": {
+                    "type": "number"
+                },
+                "timestamp": {
+                    "description": "Timestamp is the UNIX time of the measurement, the time of the request is used if it is omitted.\nIt is bounded by the ingestion clock skew and maximum age.",
+                    "type": "integer"
+                },
+                "transparency": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.LivenessStatus": {
+            "type": "string",
+            "enum": [
+                "online",
+                "late",
+                "offline"
+            ],
+            "x-enum-varnames": [
+                "StatusOnline",
+                "StatusLate",
+                "StatusOffline"
+            ]
+        },
+        "domain.Measurement": {
+            "type": "object",
+            "properties": {
+                "anomaly_score": {
+                    "description": "AnomalyScore is the score of the measurement by the anomaly detector, empty if it was saved unscored.",
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "detected_fish": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DetectedFish"
+                    }
+                },
+                "fault": {
+                    "description": "Fault is the mode of the fault injected into the simulated measurement, empty for a clean one.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "sensor_id": {
+                    "type": "string"
+                },
+                "temperature": {
+                    "type": "number"
+                },
+                "transparency": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.Metric": {
+            "type": "string",
+            "enum": [
+                "species",
+                "temperature",
+                "transparency"
+            ],
+            "x-enum-varnames": [
+                "MetricSpecies",
+                "MetricTemperature",
+                "MetricTransparency"
+            ]
+        },
+        "domain.NamedRegion": {
+            "type": "object",
+            "properties": {
+                "box": {
+                    "$ref": "#/definitions/domain.Region"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "sphere": {
+                    "$ref": "#/definitions/domain.Sphere"
+                }
+            }
+        },
+        "domain.Operator": {
+            "type": "string",
+            "enum": [
+                "\u003e",
+                "\u003e=",
+                "\u003c",
+                "\u003c="
+            ],
+            "x-enum-varnames": [
+                "OperatorGreater",
+                "OperatorGreaterEqual",
+                "OperatorLess",
+                "OperatorLessEqual"
+            ]
+        },
+        "domain.Range": {
+            "type": "object",
+            "properties": {
+                "max": {
+                    "type": "number"
+                },
+                "min": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.Rarity": {
+            "type": "string",
+            "enum": [
+                "common",
+                "uncommon",
+                "rare"
+            ],
+            "x-enum-varnames": [
+                "RarityCommon",
+                "RarityUncommon",
+                "RarityRare"
+            ]
+        },
+        "domain.Reading": {
+            "type": "object",
+            "properties": {
+                "anomaly_score": {
+                    "type": "number"
+                },
+                "codename": {
+                    "type": "string"
+                },
+                "coordinates": {
+                    "$ref": "#/definitions/domain.Coordinates"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "detected_fish": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ResponseDetectedFish"
+                    }
+                },
+                "fault": {
+                    "type": "string"
+                },
+                "group_name": {
+                    "type": "string"
+                },
+                "measurement_id": {
+                    "type": "string"
+                },
+                "sensor_id": {
+                    "type": "string"
+                },
+                "temperature": {
+                    "type": "number"
+                },
+                "transparency": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.Region": {
+            "type": "object",
+            "properties": {
+                "x_max": {
+                    "type": "number"
+                },
+                "x_min": {
+                    "type": "number"
+                },
+                "y_max": {
+                    "type": "number"
+                },
+                "y_min": {
+                    "type": "number"
+                },
+                "z_max": {
+                    "type": "number"
+                },
+                "z_min": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.ResponseDetectedFish": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.SchedulerStats": {
+            "type": "object",
+            "properties": {
+                "batches": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "lag_avg_ms": {
+                    "type": "number"
+                },
+                "lag_last_ms": {
+                    "description": "LagLast is the largest lag of the latest written batch, LagAvg and LagMax are of every written reading.",
+                    "type": "number"
+                },
+                "lag_max_ms": {
+                    "type": "number"
+                },
+                "lost": {
+                    "type": "integer"
+                },
+                "queued": {
+                    "description": "Queued is the number of the due readings waiting for a worker.",
+                    "type": "integer"
+                },
+                "retries": {
+                    "description": "Retries counts the writes retried after a transient storage error.",
+                    "type": "integer"
+                },
+                "sensors": {
+                    "type": "integer"
+                },
+                "workers": {
+                    "type": "integer"
+                },
+                "written": {
+                    "description": "Written, Lost and Failed count the readings written, lost to the faults, the scenario and the sensors deleted\nbefore their readings were written, and failed to be written.",
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.Sensor": {
+            "type": "object",
+            "properties": {
+                "codename": {
+                    "$ref": "#/definitions/domain.Codename"
+                },
+                "coordinates": {
+                    "$ref": "#/definitions/domain.Coordinates"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "data_output_rate": {
+                    "type": "integer"
+                },
+                "detected_fish": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DetectedFish"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "source": {
+                    "$ref": "#/definitions/domain.SensorSource"
+                },
+                "temperature": {
+                    "type": "number"
+                },
+                "transparency": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.SensorDistance": {
+            "type": "object",
+            "properties": {
+                "distance": {
+                    "type": "number"
+                },
+                "sensor": {
+                    "$ref": "#/definitions/domain.Sensor"
+                }
+            }
+        },
+        "domain.SensorGroup": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "sensors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Sensor"
+                    }
+                }
+            }
+        },
+        "domain.SensorSource": {
+            "type": "string",
+            "enum": [
+                "simulated",
+                "live"
+            ],
+            "x-enum-varnames": [
+                "SourceSimulated",
+                "SourceLive"
+            ]
+        },
+        "domain.SensorStatus": {
+            "type": "object",
+            "properties": {
+                "codename": {
+                    "type": "string"
+                },
+                "data_output_rate": {
+                    "type": "integer"
+                },
+                "group_name": {
+                    "type": "string"
+                },
+                "last_seen": {
+                    "type": "string"
+                },
+                "sensor_id": {
+                    "type": "string"
+                },
+                "since": {
+                    "type": "string"
+                },
+                "source": {
+                    "$ref": "#/definitions/domain.SensorSource"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.LivenessStatus"
+                }
+            }
+        },
+        "domain.SeriesPoint": {
+            "type": "object",
+            "properties": {
+                "bucket_start": {
+                    "type": "string"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.Species": {
+            "type": "object",
+            "properties": {
+                "depth": {
+                    "$ref": "#/definitions/domain.Range"
+                },
+                "habitat": {
+                    "type": "string"
+                },
+                "max_count": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rarity": {
+                    "$ref": "#/definitions/domain.Rarity"
+                },
+                "schooling": {
+                    "type": "boolean"
+                },
+                "scientific_name": {
+                    "type": "string"
+                },
+                "temperature": {
+                    "$ref": "#/definitions/domain.Range"
+                }
+            }
+        },
+        "domain.Sphere": {
+            "type": "object",
+            "properties": {
+                "center": {
+                    "$ref": "#/definitions/domain.Coordinates"
+                },
+                "radius": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.TaskState": {
+            "type": "string",
+            "enum": [
+                "running",
+                "restarting",
+                "stopped",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "TaskRunning",
+                "TaskRestarting",
+                "TaskStopped",
+                "TaskFailed"
+            ]
+        },
+        "domain.TaskStatus": {
+            "type": "object",
+            "properties": {
+                "last_error": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "restarts": {
+                    "type": "integer"
+                },
+                "since": {
+                    "type": "string"
+                },
+                "state": {
+                    "$ref": "#/definitions/domain.TaskState"
+                }
+            }
+        },
+        "domain.UpdateFaultRule": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.UpdateGroup": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.UpdateSensor": {
+            "type": "object",
+            "properties": {
+                "coordinates": {
+                    "$ref": "#/definitions/domain.Coordinates"
+                },
+                "data_output_rate": {
+                    "type": "integer"
+                },
+                "group_name": {
+                    "type": "string"
+                },
+                "source": {
+                    "$ref": "#/definitions/domain.SensorSource"
+                }
+            }
+        },
+        "domain.Webhook": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.EventType"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempt": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "duration_ms": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "event_type": {
+                    "$ref": "#/definitions/domain.EventType"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "status_code": {
+                    "type": "integer"
+                },
+                "success": {
+                    "type": "boolean"
+                },
+                "webhook_id": {
+                    "type": "integer"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
         }
     }
 }`
@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MetricSpecies is the count of a species detected by a sensor, it is only used by the alert rules.
+const MetricSpecies Metric = "species"
+
+// Operator compares the value of an alert rule to its threshold.
+type Operator string
+
+const (
+	OperatorGreater      Operator = ">"
+	OperatorGreaterEqual Operator = ">="
+	OperatorLess         Operator = "<"
+	OperatorLessEqual    Operator = "<="
+)
+
+// Valid reports whether o is a known operator.
+func (o Operator) Valid() bool {
+	switch o {
+	case OperatorGreater, OperatorGreaterEqual, OperatorLess, OperatorLessEqual:
+		return true
+	}
+
+	return false
+}
+
+// Compare reports whether the value satisfies the operator against the threshold.
+func (o Operator) Compare(value, threshold float64) bool {
+	switch o {
+	case OperatorGreater:
+		return value > threshold
+	case OperatorGreaterEqual:
+		return value >= threshold
+	case OperatorLess:
+		return value < threshold
+	case OperatorLessEqual:
+		return value <= threshold
+	}
+
+	return false
+}
+
+// AlertRule fires an alert when the metric compared to the threshold holds for Consecutive readings in a row.
+// The rule applies to the sensors of a group, a single sensor or a named region, every sensor if none of them is set.
+// Without an aggregation every sensor of the scope is evaluated on its own, otherwise the latest readings
+// of all the sensors in the scope are aggregated on every reading.
+type AlertRule struct {
+	ID          int         `json:"id"`
+	Name        string      `json:"name"`
+	Metric      Metric      `json:"metric"`
+	Species     string      `json:"species,omitempty"`
+	GroupName   string      `json:"group_name,omitempty"`
+	SensorID    *uuid.UUID  `json:"sensor_id,omitempty"`
+	Codename    string      `json:"codename,omitempty"`
+	Region      string      `json:"region,omitempty"`
+	Aggregation Aggregation `json:"aggregation,omitempty"`
+	Operator    Operator    `json:"operator"`
+	Threshold   float64     `json:"threshold"`
+	Consecutive int         `json:"consecutive"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// InScope reports whether the reading is taken by a sensor the rule applies to, area is the shape of the rule region.
+func (r AlertRule) InScope(reading Reading, area *Area) bool {
+	if r.GroupName != "" && r.GroupName != reading.GroupName {
+		return false
+	}
+
+	if r.SensorID != nil && *r.SensorID != reading.SensorID {
+		return false
+	}
+
+	if r.Region != "" && (area == nil || !area.Contains(reading.Coordinates)) {
+		return false
+	}
+
+	return true
+}
+
+// Value returns the metric of the rule taken from the reading.
+func (r AlertRule) Value(reading Reading) float64 {
+	switch r.Metric {
+	case MetricTemperature:
+		return reading.Temperature
+	case MetricTransparency:
+		return float64(reading.Transparency)
+	case MetricSpecies:
+		count := 0
+		for _, fish := range reading.DetectedFish {
+			if strings.EqualFold(fish.Name, r.Species) {
+				count += fish.Count
+			}
+		}
+
+		return float64(count)
+	}
+
+	return 0
+}
+
+// CreateAlertRule describes an alert rule to create, at most one of GroupName, Codename and Region may be set.
+type CreateAlertRule struct {
+	Name        string      `json:"name"`
+	Metric      Metric      `json:"metric"`
+	Species     string      `json:"species,omitempty"`
+	GroupName   string      `json:"group_name,omitempty"`
+	Codename    string      `json:"codename,omitempty"`
+	Region      string      `json:"region,omitempty"`
+	Aggregation Aggregation `json:"aggregation,omitempty"`
+	Operator    Operator    `json:"operator"`
+	Threshold   float64     `json:"threshold"`
+	Consecutive int         `json:"consecutive,omitempty"`
+}
+
+// AlertState is the state of an alert, it is firing until the rule condition stops holding.
+type AlertState string
+
+const (
+	AlertFiring   AlertState = "firing"
+	AlertResolved AlertState = "resolved"
+)
+
+// Alert is a period during which the condition of a rule held.
+type Alert struct {
+	ID            int64      `json:"id"`
+	RuleID        int        `json:"rule_id"`
+	RuleName      string     `json:"rule_name"`
+	SensorID      *uuid.UUID `json:"sensor_id,omitempty"`
+	Codename      string     `json:"codename,omitempty"`
+	State         AlertState `json:"state"`
+	Value         float64    `json:"value"`
+	FiredAt       time.Time  `json:"fired_at"`
+	ResolvedValue *float64   `json:"resolved_value,omitempty"`
+	ResolvedAt    *time.Time `json:"resolved_at,omitempty"`
+}
+
+// AlertQuery filters the alert history, zero fields match every alert.
+type AlertQuery struct {
+	RuleID int
+	State  AlertState
+	From   time.Time
+	Till   time.Time
+	Limit  int
+}
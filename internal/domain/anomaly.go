@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Anomaly is a metric of a reading which deviates from the recent readings of the sensor or from its neighbours.
+// ZScore compares the value to the rolling mean of the sensor, SpatialScore to the latest values of the nearest
+// sensors, either is empty if there was not enough data for it. Score is the larger of their absolute values.
+type Anomaly struct {
+	ID            int64     `json:"id"`
+	MeasurementID uuid.UUID `json:"measurement_id"`
+	SensorID      uuid.UUID `json:"sensor_id"`
+	Codename      string    `json:"codename"`
+	GroupName     string    `json:"group_name"`
+	Metric        Metric    `json:"metric"`
+	Value         float64   `json:"value"`
+	Score         float64   `json:"score"`
+	ZScore        *float64  `json:"z_score,omitempty"`
+	SpatialScore  *float64  `json:"spatial_score,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	DetectedAt    time.Time `json:"detected_at"`
+}
+
+// AnomalyQuery filters the flagged readings, zero fields match every anomaly.
+type AnomalyQuery struct {
+	GroupName string
+	From      time.Time
+	Till      time.Time
+	Limit     int
+}
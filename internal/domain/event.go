@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventType is the kind of an event the service notifies about.
+type EventType string
+
+const (
+	// EventReadingCreated carries every new Reading.
+	EventReadingCreated EventType = "reading.created"
+	// EventAlertFiring and EventAlertResolved carry the Alert which changed its state.
+	EventAlertFiring   EventType = "alert.firing"
+	EventAlertResolved EventType = "alert.resolved"
+	// EventSensorFailed carries the SensorFailure of a sensor whose data generation stopped.
+	EventSensorFailed EventType = "sensor.failed"
+	// EventStorageError carries the SensorFailure of a measurement which could not be saved.
+	EventStorageError EventType = "storage.error"
+	// EventSensorOffline and EventSensorOnline carry the SensorStatus of a sensor which stopped
+	// or started reporting its data again.
+	EventSensorOffline EventType = "sensor.offline"
+	EventSensorOnline  EventType = "sensor.online"
+	// EventAnomalyDetected carries the Anomaly flagged in a reading.
+	EventAnomalyDetected EventType = "anomaly.detected"
+)
+
+// EventTypes lists every known event type.
+var EventTypes = []EventType{
+	EventReadingCreated, EventAlertFiring, EventAlertResolved, EventSensorFailed, EventStorageError,
+	EventSensorOffline, EventSensorOnline, EventAnomalyDetected,
+}
+
+// Valid reports whether t is a known event type.
+func (t EventType) Valid() bool {
+	for _, known := range EventTypes {
+		if t == known {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Event is something notable that happened in the service, Data depends on the type.
+type Event struct {
+	ID        uuid.UUID `json:"id"`
+	Type      EventType `json:"type"`
+	Data      any       `json:"data"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewEvent(eventType EventType, data any) Event {
+	return Event{ID: uuid.New(), Type: eventType, Data: data, CreatedAt: time.Now()}
+}
+
+// SensorFailure describes a failure of the data generation of a sensor.
+type SensorFailure struct {
+	SensorID uuid.UUID `json:"sensor_id"`
+	Codename string    `json:"codename"`
+	Error    string    `json:"error"`
+}
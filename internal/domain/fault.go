@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FaultMode is the kind of a fault injected into the simulated sensor data.
+type FaultMode string
+
+const (
+	// FaultDrift shifts the temperature by Magnitude degrees per hour for the Duration, downwards if Magnitude is negative,
+	// the sensor is recalibrated then.
+	FaultDrift FaultMode = "drift"
+	// FaultStuck repeats the values of the first faulty reading for the Duration.
+	FaultStuck FaultMode = "stuck"
+	// FaultSpike shifts the temperature of a single reading by Magnitude degrees up or down.
+	FaultSpike FaultMode = "spike"
+	// FaultInvalid replaces the temperature of a single reading with NaN, it is saved and published as null.
+	FaultInvalid FaultMode = "invalid"
+	// FaultOutOfRange replaces the temperature of a single reading with -127, the reading of a disconnected probe,
+	// far below any water temperature.
+	FaultOutOfRange FaultMode = "out_of_range"
+	// FaultDropout loses a single reading.
+	FaultDropout FaultMode = "dropout"
+	// FaultOutage loses every reading for the Duration.
+	FaultOutage FaultMode = "outage"
+)
+
+// FaultModes lists every known fault mode.
+var FaultModes = []FaultMode{FaultDrift, FaultStuck, FaultSpike, FaultInvalid, FaultOutOfRange, FaultDropout, FaultOutage}
+
+// Valid reports whether m is a known fault mode.
+func (m FaultMode) Valid() bool {
+	for _, mode := range FaultModes {
+		if m == mode {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Lasting reports whether the fault lasts for a duration rather than a single reading.
+func (m FaultMode) Lasting() bool {
+	return m == FaultDrift || m == FaultStuck || m == FaultOutage
+}
+
+// Lost reports whether the readings of the fault are lost rather than saved with faulty values.
+func (m FaultMode) Lost() bool {
+	return m == FaultDropout || m == FaultOutage
+}
+
+// FaultRule injects the faults of the mode into the sensors of the group or the sensor of the codename, every simulated
+// sensor if neither is set. Probability is the chance a reading of a healthy sensor starts the fault, Duration is
+// how many seconds a drift, stuck or outage fault lasts, Magnitude is the drift in degrees per hour or the height
+// of a spike. The rules are configured in the faults section and added through the admin API.
+type FaultRule struct {
+	ID          int       `json:"id" yaml:"-"`
+	Mode        FaultMode `json:"mode" yaml:"mode"`
+	GroupName   string    `json:"group_name,omitempty" yaml:"group_name"`
+	Codename    string    `json:"codename,omitempty" yaml:"codename"`
+	Probability float64   `json:"probability" yaml:"probability"`
+	Duration    int       `json:"duration" yaml:"duration"`
+	Magnitude   float64   `json:"magnitude" yaml:"magnitude"`
+	Enabled     bool      `json:"enabled" yaml:"enabled"`
+}
+
+// Applies reports whether the rule injects the faults into the sensor.
+func (r FaultRule) Applies(sensor Sensor) bool {
+	switch {
+	case r.Codename != "":
+		return r.Codename == sensor.Codename.String()
+	case r.GroupName != "":
+		return r.GroupName == sensor.Codename.Name
+	}
+
+	return true
+}
+
+// CreateFaultRule describes a fault rule added through the admin API, it is enabled unless Enabled is false.
+type CreateFaultRule struct {
+	Mode        FaultMode `json:"mode"`
+	GroupName   string    `json:"group_name"`
+	Codename    string    `json:"codename"`
+	Probability float64   `json:"probability"`
+	Duration    int       `json:"duration"`
+	Magnitude   float64   `json:"magnitude"`
+	Enabled     *bool     `json:"enabled"`
+}
+
+// UpdateFaultRule toggles a fault rule.
+type UpdateFaultRule struct {
+	Enabled bool `json:"enabled"`
+}
+
+// ActiveFault is a lasting fault a sensor suffers from.
+type ActiveFault struct {
+	SensorID uuid.UUID `json:"sensor_id"`
+	Codename string    `json:"codename"`
+	RuleID   int       `json:"rule_id"`
+	Mode     FaultMode `json:"mode"`
+	Since    time.Time `json:"since"`
+	Until    time.Time `json:"until"`
+}
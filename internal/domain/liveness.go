@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LivenessStatus tells whether a sensor reports its data at the expected cadence.
+type LivenessStatus string
+
+const (
+	StatusOnline  LivenessStatus = "online"
+	StatusLate    LivenessStatus = "late"
+	StatusOffline LivenessStatus = "offline"
+)
+
+// Valid reports whether s is a known liveness status.
+func (s LivenessStatus) Valid() bool {
+	return s == StatusOnline || s == StatusLate || s == StatusOffline
+}
+
+// SensorStatus is the liveness of a sensor, Since is the time it got the status.
+// LastSeen is the time the latest reading of the sensor arrived, it is empty until the first reading since the start.
+type SensorStatus struct {
+	SensorID       uuid.UUID      `json:"sensor_id"`
+	Codename       string         `json:"codename"`
+	GroupName      string         `json:"group_name"`
+	Source         SensorSource   `json:"source"`
+	DataOutputRate int            `json:"data_output_rate"`
+	Status         LivenessStatus `json:"status"`
+	Since          time.Time      `json:"since"`
+	LastSeen       *time.Time     `json:"last_seen,omitempty"`
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Measurement is everything a sensor reported at a single tick.
+type Measurement struct {
+	ID           uuid.UUID      `json:"id"`
+	SensorID     uuid.UUID      `json:"sensor_id"`
+	Temperature  float64        `json:"temperature"`
+	Transparency int            `json:"transparency"`
+	DetectedFish []DetectedFish `json:"detected_fish"`
+	CreatedAt    time.Time      `json:"created_at"`
+	// Fault is the mode of the fault injected into the simulated measurement, empty for a clean one.
+	Fault string `json:"fault,omitempty"`
+	// AnomalyScore is the score of the measurement by the anomaly detector, empty if it was saved unscored.
+	AnomalyScore *float64 `json:"anomaly_score"`
+}
+
+// IngestMeasurement is a measurement reported by a live sensor through the ingestion API.
+type IngestMeasurement struct {
+	Temperature  *float64             `json:"temperature"`
+	Transparency *int                 `json:"transparency"`
+	DetectedFish []IngestDetectedFish `json:"detected_fish"`
+	// Timestamp is the UNIX time of the measurement, the time of the request is used if it is omitted.
+	// It is bounded by the ingestion clock skew and maximum age.
+	Timestamp *int64 `json:"timestamp,omitempty"`
+}
+
+type IngestDetectedFish struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// DecodeIngestMeasurements decodes either a single measurement object or an array of them.
+func DecodeIngestMeasurements(data []byte) ([]IngestMeasurement, error) {
+	data = bytes.TrimSpace(data)
+
+	if len(data) > 0 && data[0] == '[' {
+		var measurements []IngestMeasurement
+		err := json.Unmarshal(data, &measurements)
+		return measurements, err
+	}
+
+	var measurement IngestMeasurement
+
+	err := json.Unmarshal(data, &measurement)
+	if err != nil {
+		return nil, err
+	}
+
+	return []IngestMeasurement{measurement}, nil
+}
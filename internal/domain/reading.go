@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reading is a saved measurement together with the sensor it was taken by, it is what the live streams carry.
+type Reading struct {
+	MeasurementID uuid.UUID              `json:"measurement_id"`
+	SensorID      uuid.UUID              `json:"sensor_id"`
+	Codename      string                 `json:"codename"`
+	GroupName     string                 `json:"group_name"`
+	Coordinates   Coordinates            `json:"coordinates"`
+	Temperature   float64                `json:"temperature"`
+	Transparency  int                    `json:"transparency"`
+	DetectedFish  []ResponseDetectedFish `json:"detected_fish"`
+	CreatedAt     time.Time              `json:"created_at"`
+	Fault         string                 `json:"fault,omitempty"`
+	AnomalyScore  *float64               `json:"anomaly_score"`
+}
+
+func NewReading(sensor Sensor, measurement Measurement) Reading {
+	reading := Reading{
+		MeasurementID: measurement.ID,
+		SensorID:      sensor.ID,
+		Codename:      sensor.Codename.String(),
+		GroupName:     sensor.Codename.Name,
+		Coordinates:   sensor.Coordinates,
+		Temperature:   measurement.Temperature,
+		Transparency:  measurement.Transparency,
+		DetectedFish:  make([]ResponseDetectedFish, 0, len(measurement.DetectedFish)),
+		CreatedAt:     measurement.CreatedAt,
+		Fault:         measurement.Fault,
+		AnomalyScore:  measurement.AnomalyScore,
+	}
+
+	for _, fish := range measurement.DetectedFish {
+		reading.DetectedFish = append(reading.DetectedFish, ResponseDetectedFish{Name: fish.Name, Count: fish.Count})
+	}
+
+	return reading
+}
+
+// ValidTemperature returns the temperature of the reading, nil for the NaN of an invalid reading.
+func (r Reading) ValidTemperature() *float64 {
+	if math.IsNaN(r.Temperature) {
+		return nil
+	}
+
+	return &r.Temperature
+}
+
+// reading has the fields of Reading without its JSON methods.
+type reading Reading
+
+// readingJSON is the JSON form of a reading, JSON can not carry NaN, so an invalid temperature is null.
+type readingJSON struct {
+	reading
+	Temperature *float64 `json:"temperature"`
+}
+
+func (r Reading) MarshalJSON() ([]byte, error) {
+	return json.Marshal(readingJSON{reading: reading(r), Temperature: r.ValidTemperature()})
+}
+
+func (r *Reading) UnmarshalJSON(data []byte) error {
+	var decoded readingJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	*r = Reading(decoded.reading)
+
+	r.Temperature = math.NaN()
+	if decoded.Temperature != nil {
+		r.Temperature = *decoded.Temperature
+	}
+
+	return nil
+}
+
+// Contains reports whether the point is inside the box or the sphere of the area.
+func (a Area) Contains(point Coordinates) bool {
+	switch {
+	case a.Box != nil:
+		return point.X >= a.Box.XMin && point.X <= a.Box.XMax &&
+			point.Y >= a.Box.YMin && point.Y <= a.Box.YMax &&
+			point.Z >= a.Box.ZMin && point.Z <= a.Box.ZMax
+	case a.Sphere != nil:
+		return point.Distance(a.Sphere.Center) <= a.Sphere.Radius
+	}
+
+	return false
+}
+
+// Distance returns the euclidean distance between the points.
+func (c Coordinates) Distance(other Coordinates) float64 {
+	return math.Sqrt((c.X-other.X)*(c.X-other.X) + (c.Y-other.Y)*(c.Y-other.Y) + (c.Z-other.Z)*(c.Z-other.Z))
+}
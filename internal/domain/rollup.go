@@ -0,0 +1,66 @@
+package domain
+
+import "time"
+
+// Bucket is the width of a statistics rollup bucket.
+type Bucket string
+
+const (
+	BucketMinute Bucket = "1m"
+	BucketHour   Bucket = "1h"
+	BucketDay    Bucket = "1d"
+)
+
+// Buckets lists every rollup bucket from the finest to the coarsest one.
+var Buckets = []Bucket{BucketMinute, BucketHour, BucketDay}
+
+// Unit returns the PostgreSQL date_trunc unit of the bucket.
+func (b Bucket) Unit() string {
+	switch b {
+	case BucketMinute:
+		return "minute"
+	case BucketHour:
+		return "hour"
+	case BucketDay:
+		return "day"
+	}
+
+	return ""
+}
+
+// Duration returns the width of the bucket.
+func (b Bucket) Duration() time.Duration {
+	switch b {
+	case BucketMinute:
+		return time.Minute
+	case BucketHour:
+		return time.Hour
+	case BucketDay:
+		return 24 * time.Hour
+	}
+
+	return 0
+}
+
+// Source returns the finer bucket the rollup is built from, or empty string when it is built from raw data.
+func (b Bucket) Source() Bucket {
+	switch b {
+	case BucketHour:
+		return BucketMinute
+	case BucketDay:
+		return BucketHour
+	}
+
+	return ""
+}
+
+// Truncate rounds t down to the start of the bucket in UTC, the time zone of the stored timestamps,
+// like date_trunc does on them.
+func (b Bucket) Truncate(t time.Time) time.Time {
+	return t.UTC().Truncate(b.Duration())
+}
+
+// Valid reports whether b is a known bucket.
+func (b Bucket) Valid() bool {
+	return b.Duration() != 0
+}
@@ -0,0 +1,22 @@
+package domain
+
+// SchedulerStats are the metrics of the scheduler generating the simulated readings. The lag of a reading is the time
+// between the moment it is due and the moment it is written, by the simulation clock, in milliseconds.
+type SchedulerStats struct {
+	Sensors int `json:"sensors"`
+	Workers int `json:"workers"`
+	// Queued is the number of the due readings waiting for a worker.
+	Queued int `json:"queued"`
+	// Written, Lost and Failed count the readings written, lost to the faults, the scenario and the sensors deleted
+	// before their readings were written, and failed to be written.
+	Written uint64 `json:"written"`
+	Lost    uint64 `json:"lost"`
+	Failed  uint64 `json:"failed"`
+	// Retries counts the writes retried after a transient storage error.
+	Retries uint64 `json:"retries"`
+	Batches uint64 `json:"batches"`
+	// LagLast is the largest lag of the latest written batch, LagAvg and LagMax are of every written reading.
+	LagLast float64 `json:"lag_last_ms"`
+	LagAvg  float64 `json:"lag_avg_ms"`
+	LagMax  float64 `json:"lag_max_ms"`
+}
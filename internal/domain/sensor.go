@@ -1,13 +1,30 @@
 package domain
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// SensorSource tells where the sensor data comes from.
+type SensorSource string
+
+const (
+	// SourceSimulated sensors get their data from the built-in generator.
+	SourceSimulated SensorSource = "simulated"
+	// SourceLive sensors report their data through the ingestion API, the generator skips them.
+	SourceLive SensorSource = "live"
+)
+
+// Valid reports whether s is a known sensor source.
+func (s SensorSource) Valid() bool {
+	return s == SourceSimulated || s == SourceLive
+}
+
 type Sensor struct {
 	ID             uuid.UUID      `json:"id"`
+	Source         SensorSource   `json:"source"`
 	DataOutputRate int            `json:"data_output_rate"`
 	Temperature    float64        `json:"temperature"`
 	Transparency   int            `json:"transparency"`
@@ -31,10 +48,11 @@ type Coordinates struct {
 }
 
 type DetectedFish struct {
-	ID       uuid.UUID `json:"id,omitempty"`
-	SensorID uuid.UUID `json:"sensor_id,omitempty"`
-	Name     string    `json:"name"`
-	Count    int       `json:"count"`
+	ID            uuid.UUID `json:"id,omitempty"`
+	SensorID      uuid.UUID `json:"sensor_id,omitempty"`
+	MeasurementID uuid.UUID `json:"measurement_id,omitempty"`
+	Name          string    `json:"name"`
+	Count         int       `json:"count"`
 }
 
 type ResponseDetectedFish struct {
@@ -47,11 +65,79 @@ type Codename struct {
 	SensorGroupID int    `json:"sensor_group_id"`
 }
 
+// String returns the codename as it is used in the routes, e.g. alpha5.
+func (c Codename) String() string {
+	return fmt.Sprintf("%s%d", c.Name, c.SensorGroupID)
+}
+
 type Region struct {
-	XMin float64 `yaml:"x_min"`
-	XMax float64 `yaml:"x_max"`
-	YMin float64 `yaml:"y_min"`
-	YMax float64 `yaml:"y_max"`
-	ZMin float64 `yaml:"z_min"`
-	ZMax float64 `yaml:"z_max"`
+	XMin float64 `yaml:"x_min" json:"x_min"`
+	XMax float64 `yaml:"x_max" json:"x_max"`
+	YMin float64 `yaml:"y_min" json:"y_min"`
+	YMax float64 `yaml:"y_max" json:"y_max"`
+	ZMin float64 `yaml:"z_min" json:"z_min"`
+	ZMax float64 `yaml:"z_max" json:"z_max"`
+}
+
+// Sphere is the region within the radius around the center point.
+type Sphere struct {
+	Center Coordinates `json:"center"`
+	Radius float64     `json:"radius"`
+}
+
+// Area is a region of interest, either a box or a sphere.
+type Area struct {
+	Box    *Region `json:"box,omitempty"`
+	Sphere *Sphere `json:"sphere,omitempty"`
+}
+
+// NamedRegion is a region of interest persisted under a name, it can be used instead of the region coordinates.
+type NamedRegion struct {
+	Name      string    `json:"name"`
+	Box       *Region   `json:"box,omitempty"`
+	Sphere    *Sphere   `json:"sphere,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Area returns the shape of the region.
+func (r NamedRegion) Area() Area {
+	return Area{Box: r.Box, Sphere: r.Sphere}
+}
+
+// CreateRegion describes a named region to persist, exactly one of Box and Sphere must be set.
+type CreateRegion struct {
+	Name   string  `json:"name"`
+	Box    *Region `json:"box,omitempty"`
+	Sphere *Sphere `json:"sphere,omitempty"`
+}
+
+// SensorDistance is a sensor and its distance to the point it was searched around.
+type SensorDistance struct {
+	Sensor   Sensor  `json:"sensor"`
+	Distance float64 `json:"distance"`
+}
+
+// CreateSensor describes a sensor registered through the API, its index inside the group is assigned automatically.
+type CreateSensor struct {
+	GroupName      string       `json:"group_name"`
+	Source         SensorSource `json:"source,omitempty"`
+	DataOutputRate int          `json:"data_output_rate"`
+	Coordinates    Coordinates  `json:"coordinates"`
+}
+
+// UpdateSensor describes the sensor fields to change, nil fields are left as they are.
+// Moving a sensor to another group assigns it the next index inside that group.
+type UpdateSensor struct {
+	GroupName      *string       `json:"group_name,omitempty"`
+	Source         *SensorSource `json:"source,omitempty"`
+	DataOutputRate *int          `json:"data_output_rate,omitempty"`
+	Coordinates    *Coordinates  `json:"coordinates,omitempty"`
+}
+
+type CreateGroup struct {
+	Name string `json:"name"`
+}
+
+type UpdateGroup struct {
+	Name string `json:"name"`
 }
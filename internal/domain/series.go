@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Metric is a numeric value reported by the sensors which can be queried as a time series.
+type Metric string
+
+const (
+	MetricTemperature  Metric = "temperature"
+	MetricTransparency Metric = "transparency"
+)
+
+// Valid reports whether m is a known metric.
+func (m Metric) Valid() bool {
+	return m == MetricTemperature || m == MetricTransparency
+}
+
+// Aggregation is the function applied to the values of a series bucket.
+type Aggregation string
+
+const (
+	AggregationAvg Aggregation = "avg"
+	AggregationMin Aggregation = "min"
+	AggregationMax Aggregation = "max"
+	AggregationP95 Aggregation = "p95"
+	// AggregationPercentile is an arbitrary percentile, it is only used by the region queries.
+	AggregationPercentile Aggregation = "percentile"
+)
+
+// Valid reports whether a is a known aggregation.
+func (a Aggregation) Valid() bool {
+	switch a {
+	case AggregationAvg, AggregationMin, AggregationMax, AggregationP95:
+		return true
+	}
+
+	return false
+}
+
+// Apply aggregates a non-empty list of values, the percentile (0-100) is only used by AggregationPercentile.
+// Percentiles are interpolated the same way PostgreSQL percentile_cont does. The values are sorted in place.
+func (a Aggregation) Apply(values []float64, percentile float64) float64 {
+	sort.Float64s(values)
+
+	switch a {
+	case AggregationMin:
+		return values[0]
+	case AggregationMax:
+		return values[len(values)-1]
+	case AggregationP95, AggregationPercentile:
+		if a == AggregationP95 {
+			percentile = 95
+		}
+
+		position := percentile / 100 * float64(len(values)-1)
+		lower := math.Floor(position)
+		upper := math.Ceil(position)
+
+		return values[int(lower)] + (values[int(upper)]-values[int(lower)])*(position-lower)
+	}
+
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+
+	return sum / float64(len(values))
+}
+
+// SeriesQuery describes a bucketed time series of a sensor, of the sensors listed in SensorIDs
+// or, if both are empty, of a whole group.
+type SeriesQuery struct {
+	GroupName   string
+	SensorID    uuid.UUID
+	SensorIDs   []uuid.UUID
+	Metric      Metric
+	Bucket      Bucket
+	Aggregation Aggregation
+	From        time.Time
+	Till        time.Time
+}
+
+// SeriesPoint is the aggregated value of a series bucket and the number of values it is computed from.
+type SeriesPoint struct {
+	BucketStart time.Time `json:"bucket_start"`
+	Value       float64   `json:"value"`
+	Count       int64     `json:"count"`
+}
@@ -0,0 +1,39 @@
+package domain
+
+// Rarity tells how often a species is met in its habitat.
+type Rarity string
+
+const (
+	RarityCommon   Rarity = "common"
+	RarityUncommon Rarity = "uncommon"
+	RarityRare     Rarity = "rare"
+)
+
+// Valid reports whether r is a known rarity.
+func (r Rarity) Valid() bool {
+	return r == RarityCommon || r == RarityUncommon || r == RarityRare
+}
+
+// Range is an inclusive range of values.
+type Range struct {
+	Min float64 `json:"min" yaml:"min"`
+	Max float64 `json:"max" yaml:"max"`
+}
+
+// Contains reports whether the value is inside the range.
+func (r Range) Contains(value float64) bool {
+	return value >= r.Min && value <= r.Max
+}
+
+// Species is an entry of the species catalogue. Depth is in meters below the surface, temperature in degrees Celsius.
+// A schooling species is detected in groups of up to MaxCount fish, a solitary one alone or in a few.
+type Species struct {
+	Name           string `json:"name" yaml:"name"`
+	ScientificName string `json:"scientific_name" yaml:"scientific_name"`
+	Habitat        string `json:"habitat" yaml:"habitat"`
+	Depth          Range  `json:"depth" yaml:"depth"`
+	Temperature    Range  `json:"temperature" yaml:"temperature"`
+	Schooling      bool   `json:"schooling" yaml:"schooling"`
+	MaxCount       int    `json:"max_count" yaml:"max_count"`
+	Rarity         Rarity `json:"rarity" yaml:"rarity"`
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// TaskState is the state of a supervised task.
+type TaskState string
+
+const (
+	// TaskRunning is a task which runs, TaskRestarting one which failed and waits for its restart.
+	TaskRunning    TaskState = "running"
+	TaskRestarting TaskState = "restarting"
+	// TaskStopped is a task which returned, TaskFailed one which failed too many times in a row to be restarted.
+	TaskStopped TaskState = "stopped"
+	TaskFailed  TaskState = "failed"
+)
+
+// TaskStatus is the state of a supervised task since the time, with the number of its restarts and its latest failure.
+type TaskStatus struct {
+	Name      string    `json:"name"`
+	State     TaskState `json:"state"`
+	Since     time.Time `json:"since"`
+	Restarts  int       `json:"restarts"`
+	LastError string    `json:"last_error,omitempty"`
+}
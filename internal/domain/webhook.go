@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The headers sent with every webhook delivery.
+const (
+	HeaderWebhookEvent     = "X-Webhook-Event"
+	HeaderWebhookDelivery  = "X-Webhook-Delivery"
+	HeaderWebhookSignature = "X-Webhook-Signature"
+)
+
+// SignPayload returns the signature of the payload sent in HeaderWebhookSignature,
+// the hex encoded HMAC-SHA256 of the payload keyed with the webhook secret and prefixed with "sha256=".
+func SignPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Webhook is a subscription to the service events, the events are POSTed to the URL as JSON
+// signed with the secret. An empty Events list subscribes to every event type.
+type Webhook struct {
+	ID        int         `json:"id"`
+	URL       string      `json:"url"`
+	Secret    string      `json:"-"`
+	Events    []EventType `json:"events"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// Subscribed reports whether the webhook wants the events of the type.
+func (w Webhook) Subscribed(eventType EventType) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+
+	for _, t := range w.Events {
+		if t == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+type CreateWebhook struct {
+	URL    string      `json:"url"`
+	Secret string      `json:"secret"`
+	Events []EventType `json:"events,omitempty"`
+}
+
+// WebhookDelivery is a single attempt to deliver an event to a webhook.
+type WebhookDelivery struct {
+	ID         int64     `json:"id"`
+	WebhookID  int       `json:"webhook_id"`
+	EventID    uuid.UUID `json:"event_id"`
+	EventType  EventType `json:"event_type"`
+	Attempt    int       `json:"attempt"`
+	StatusCode *int      `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Success    bool      `json:"success"`
+	DurationMs int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// DeadLetter is an event which could not be delivered to a webhook after all the attempts.
+type DeadLetter struct {
+	ID        int64           `json:"id"`
+	WebhookID int             `json:"webhook_id"`
+	EventID   uuid.UUID       `json:"event_id"`
+	EventType EventType       `json:"event_type"`
+	Payload   json.RawMessage `json:"payload" swaggertype:"object"`
+	Attempts  int             `json:"attempts"`
+	Error     string          `json:"error"`
+	CreatedAt time.Time       `json:"created_at"`
+}
@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/internal/service"
+	"github.com/PavelDonchenko/sensor-go/internal/storage"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateAlertRule creates a new alert rule.
+//
+// @Summary Create an alert rule
+// @Description Creates a rule evaluated on every new reading, e.g. {"name": "hot gamma", "metric": "temperature", "group_name": "gamma", "operator": ">", "threshold": 30, "consecutive": 2}. The rule applies to a group, a sensor codename or a named region, every sensor if none of them is set. Without an aggregation every sensor is evaluated on its own, avg, min or max aggregates the latest readings of all the sensors in the scope. A species rule compares the count of the named species.
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Param rule body domain.CreateAlertRule true "rule to create"
+// @Success 201 {object} domain.AlertRule
+// @Failure 404 {string} string
+// @Failure 422 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/alerts/rules [post]
+func (h *Handler) CreateAlertRule(c *fiber.Ctx) error {
+	var req domain.CreateAlertRule
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	rule, err := h.service.CreateAlertRule(h.ctx, req)
+	if err != nil {
+		return c.Status(alertErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"error": false,
+		"msg":   nil,
+		"rule":  rule,
+	})
+}
+
+// GetAlertRules retrieves all alert rules.
+//
+// @Summary Get alert rules
+// @Description Retrieves all alert rules.
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Success 200 {array} domain.AlertRule
+// @Failure 500 {string} string
+// @Router /api/v1/alerts/rules [get]
+func (h *Handler) GetAlertRules(c *fiber.Ctx) error {
+	rules, err := h.service.GetAlertRules(h.ctx)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"msg":   nil,
+		"rules": rules,
+	})
+}
+
+// DeleteAlertRule deletes an alert rule together with its alerts.
+//
+// @Summary Delete an alert rule
+// @Description Deletes an alert rule together with its alert history.
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Param id path int true "id of the rule"
+// @Success 200 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/alerts/rules/{id} [delete]
+func (h *Handler) DeleteAlertRule(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": true,
+			"msg":   storage.ErrAlertRuleNotFound.Error(),
+		})
+	}
+
+	err = h.service.DeleteAlertRule(h.ctx, id)
+	if err != nil {
+		return c.Status(alertErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"msg":   nil,
+	})
+}
+
+// GetAlerts retrieves the alert history.
+//
+// @Summary Get alerts
+// @Description Retrieves the fired alerts, the latest first. An alert is firing until the condition of its rule stops holding, then it is resolved.
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Param rule query int false "id of the rule"
+// @Param state query string false "firing or resolved"
+// @Param from query int false "UNIX timestamp, alerts fired since"
+// @Param till query int false "UNIX timestamp, alerts fired before"
+// @Param limit query int false "maximum number of alerts, 100 by default"
+// @Success 200 {array} domain.Alert
+// @Failure 422 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/alerts [get]
+func (h *Handler) GetAlerts(c *fiber.Ctx) error {
+	query, err := parseAlertQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	alerts, err := h.service.GetAlerts(h.ctx, query)
+	if err != nil {
+		return c.Status(alertErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":  false,
+		"msg":    nil,
+		"alerts": alerts,
+	})
+}
+
+func parseAlertQuery(c *fiber.Ctx) (domain.AlertQuery, error) {
+	query := domain.AlertQuery{
+		State: domain.AlertState(strings.ToLower(c.Query("state"))),
+	}
+
+	var err error
+
+	if rule := c.Query("rule"); rule != "" {
+		query.RuleID, err = strconv.Atoi(rule)
+		if err != nil {
+			return query, errors.New("rule must be a rule id")
+		}
+	}
+
+	query.Limit, err = strconv.Atoi(c.Query("limit", "100"))
+	if err != nil {
+		return query, service.ErrorWrongAlertLimit
+	}
+
+	query.From, err = parseUnixQuery(c, "from")
+	if err != nil {
+		return query, err
+	}
+
+	query.Till, err = parseUnixQuery(c, "till")
+	if err != nil {
+		return query, err
+	}
+
+	return query, nil
+}
+
+func alertErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, storage.ErrAlertRuleNotFound), errors.Is(err, storage.ErrSensorNotFound),
+		errors.Is(err, storage.ErrRegionNotFound), errors.Is(err, service.ErrorWrongGroupName):
+		return fiber.StatusNotFound
+	case errors.Is(err, service.ErrorEmptyRuleName), errors.Is(err, service.ErrorWrongRuleMetric),
+		errors.Is(err, service.ErrorMissingRuleSpecies), errors.Is(err, service.ErrorWrongRuleOperator),
+		errors.Is(err, service.ErrorWrongRuleAggregation), errors.Is(err, service.ErrorWrongRuleScope),
+		errors.Is(err, service.ErrorWrongConsecutive), errors.Is(err, service.ErrorWrongAlertState),
+		errors.Is(err, service.ErrorWrongAlertLimit), errors.Is(err, service.ErrorWrongAlertPeriod):
+		return fiber.StatusUnprocessableEntity
+	}
+
+	return fiber.StatusInternalServerError
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetAnomalies retrieves the readings flagged as anomalous.
+//
+// @Summary Get anomalies
+// @Description Retrieves the readings whose temperature or transparency deviates from the rolling mean of the sensor (z_score) or from the latest readings of its nearest sensors (spatial_score) by at least anomaly.threshold standard deviations, the latest first.
+// @Tags anomalies
+// @Accept json
+// @Produce json
+// @Param group query string false "Name of the sensor group"
+// @Param from query int false "UNIX timestamp, readings taken since"
+// @Param till query int false "UNIX timestamp, readings taken before"
+// @Param limit query int false "maximum number of anomalies, 100 by default"
+// @Success 200 {array} domain.Anomaly
+// @Failure 404 {string} string
+// @Failure 422 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/anomalies [get]
+func (h *Handler) GetAnomalies(c *fiber.Ctx) error {
+	query, err := parseAnomalyQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	anomalies, err := h.service.GetAnomalies(h.ctx, query)
+	if err != nil {
+		return c.Status(anomalyErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":     false,
+		"msg":       nil,
+		"anomalies": anomalies,
+	})
+}
+
+func parseAnomalyQuery(c *fiber.Ctx) (domain.AnomalyQuery, error) {
+	query := domain.AnomalyQuery{
+		GroupName: strings.ToLower(c.Query("group")),
+	}
+
+	var err error
+
+	query.Limit, err = strconv.Atoi(c.Query("limit", "100"))
+	if err != nil {
+		return query, service.ErrorWrongAnomalyLimit
+	}
+
+	query.From, err = parseUnixQuery(c, "from")
+	if err != nil {
+		return query, err
+	}
+
+	query.Till, err = parseUnixQuery(c, "till")
+	if err != nil {
+		return query, err
+	}
+
+	return query, nil
+}
+
+func anomalyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrorWrongGroupName):
+		return fiber.StatusNotFound
+	case errors.Is(err, service.ErrorWrongAnomalyLimit), errors.Is(err, service.ErrorWrongAnomalyPeriod):
+		return fiber.StatusUnprocessableEntity
+	}
+
+	return fiber.StatusInternalServerError
+}
@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"strconv"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/internal/service"
+	"github.com/PavelDonchenko/sensor-go/internal/storage"
+	"github.com/PavelDonchenko/sensor-go/pkg/generations"
+	"github.com/gofiber/fiber/v2"
+)
+
+// authenticateAdmin rejects the requests without the configured admin API key.
+func (h *Handler) authenticateAdmin(c *fiber.Ctx) error {
+	key := h.cfg.Admin.APIKey
+	provided := c.Get(apiKeyHeader)
+
+	if subtle.ConstantTimeCompare([]byte(key), []byte(provided)) != 1 {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": true,
+			"msg":   "invalid or missing API key",
+		})
+	}
+
+	return c.Next()
+}
+
+// GetFaultRules retrieves the fault injection rules.
+//
+// @Summary Get fault rules
+// @Description Retrieves the rules injecting the faults into the simulated readings, the configured ones and the ones added through the API.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} object{error=bool,msg=string,rules=[]domain.FaultRule}
+// @Failure 401 {string} string
+// @Router /api/v1/admin/faults [get]
+func (h *Handler) GetFaultRules(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"error": false,
+		"msg":   nil,
+		"rules": h.service.GetFaultRules(),
+	})
+}
+
+// CreateFaultRule adds a fault injection rule.
+//
+// @Summary Create a fault rule
+// @Description Adds a rule injecting faults into the simulated readings, e.g. {"mode": "spike", "group_name": "gamma", "probability": 0.05, "magnitude": 10}. The mode is drift, stuck, spike, invalid, out_of_range, dropout or outage, probability is the chance a reading of a healthy sensor starts the fault. Drift, stuck and outage faults last the duration in seconds, drift changes the temperature by the magnitude in degrees per hour, downwards if it is negative, a spike by the positive magnitude up or down. The rule applies to a group or a sensor codename, every simulated sensor if neither is set, it is enabled unless enabled is false. The rules are kept in memory only.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param rule body domain.CreateFaultRule true "rule to create"
+// @Success 201 {object} object{error=bool,msg=string,rule=domain.FaultRule}
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Failure 422 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/admin/faults [post]
+func (h *Handler) CreateFaultRule(c *fiber.Ctx) error {
+	var req domain.CreateFaultRule
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	rule, err := h.service.CreateFaultRule(h.ctx, req)
+	if err != nil {
+		return c.Status(faultErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"error": false,
+		"msg":   nil,
+		"rule":  rule,
+	})
+}
+
+// UpdateFaultRule toggles a fault injection rule.
+//
+// @Summary Toggle a fault rule
+// @Description Enables or disables a fault rule, e.g. {"enabled": true}. The lasting faults of a disabled rule end at once.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "id of the rule"
+// @Param rule body domain.UpdateFaultRule true "whether the rule is enabled"
+// @Success 200 {object} object{error=bool,msg=string,rule=domain.FaultRule}
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Failure 422 {string} string
+// @Router /api/v1/admin/faults/{id} [patch]
+func (h *Handler) UpdateFaultRule(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": true,
+			"msg":   generations.ErrFaultRuleNotFound.Error(),
+		})
+	}
+
+	var req domain.UpdateFaultRule
+
+	if err = c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	rule, err := h.service.UpdateFaultRule(id, req)
+	if err != nil {
+		return c.Status(faultErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"msg":   nil,
+		"rule":  rule,
+	})
+}
+
+// DeleteFaultRule deletes a fault injection rule.
+//
+// @Summary Delete a fault rule
+// @Description Deletes a fault rule, the lasting faults it injected end at once.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "id of the rule"
+// @Success 200 {string} string
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Router /api/v1/admin/faults/{id} [delete]
+func (h *Handler) DeleteFaultRule(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": true,
+			"msg":   generations.ErrFaultRuleNotFound.Error(),
+		})
+	}
+
+	err = h.service.DeleteFaultRule(id)
+	if err != nil {
+		return c.Status(faultErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"msg":   nil,
+	})
+}
+
+// GetActiveFaults retrieves the faults the sensors suffer from.
+//
+// @Summary Get active faults
+// @Description Retrieves the drift, stuck and outage faults the simulated sensors suffer from, the ones ending first first.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} object{error=bool,msg=string,faults=[]domain.ActiveFault}
+// @Failure 401 {string} string
+// @Router /api/v1/admin/faults/active [get]
+func (h *Handler) GetActiveFaults(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"error":  false,
+		"msg":    nil,
+		"faults": h.service.GetActiveFaults(),
+	})
+}
+
+func faultErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, generations.ErrFaultRuleNotFound), errors.Is(err, storage.ErrSensorNotFound),
+		errors.Is(err, service.ErrorWrongGroupName):
+		return fiber.StatusNotFound
+	case errors.Is(err, generations.ErrorWrongFaultMode), errors.Is(err, generations.ErrorWrongFaultScope),
+		errors.Is(err, generations.ErrorWrongProbability), errors.Is(err, generations.ErrorWrongFaultDuration),
+		errors.Is(err, generations.ErrorWrongFaultMagnitude):
+		return fiber.StatusUnprocessableEntity
+	}
+
+	return fiber.StatusInternalServerError
+}
@@ -13,10 +13,20 @@ type Handler struct {
 	ctx     context.Context
 	cfg     config.Config
 	service service.SensorService
+	// streams ends the open reading streams before the server shuts down
+	streams      context.Context
+	closeStreams context.CancelFunc
 }
 
 func NewHandler(ctx context.Context, cfg config.Config, service service.SensorService) *Handler {
-	return &Handler{ctx: ctx, cfg: cfg, service: service}
+	streams, closeStreams := context.WithCancel(ctx)
+
+	return &Handler{ctx: ctx, cfg: cfg, service: service, streams: streams, closeStreams: closeStreams}
+}
+
+// CloseStreams ends the open reading streams, the server waits for them to end when it shuts down.
+func (h *Handler) CloseStreams() {
+	h.closeStreams()
 }
 
 func (h *Handler) Register(a *fiber.App) {
@@ -26,9 +36,77 @@ func (h *Handler) Register(a *fiber.App) {
 	route.Get("/group/:groupName/temperature/average", h.GetTemperature)
 	route.Get("/group/:groupName/species", h.GetCurrentSpecies)
 	route.Get("/group/:groupName/species/top/:top", h.GetCurrentTopSpecies)
+	route.Get("/group/:groupName/series", h.GetGroupSeries)
 	route.Get("/region/temperature/min", h.GeRegionMinTemperature)
 	route.Get("/region/temperature/max", h.GeRegionMaxTemperature)
+	route.Get("/region/temperature/average", h.GetRegionAverageTemperature)
+	route.Get("/region/temperature/percentile", h.GetRegionPercentileTemperature)
+	route.Get("/region/transparency/average", h.GetRegionTransparency)
+	route.Get("/region/species", h.GetRegionSpecies)
+	route.Get("/region/species/top/:top", h.GetRegionTopSpecies)
+	route.Get("/region/sensors", h.GetRegionSensors)
 	route.Get("/sensor/:codename/temperature/average", h.GetAverageSensorTemperature)
+	route.Get("/sensor/:codename/transparency/average", h.GetAverageSensorTransparency)
+	route.Get("/sensor/:codename/series", h.GetSensorSeries)
+
+	// the ingestion is disabled without its API key
+	if h.cfg.Ingestion.APIKey != "" {
+		route.Post("/sensor/:codename/measurements", h.authenticateIngestion, h.IngestMeasurements)
+	}
+
+	route.Get("/species", h.GetSpeciesCatalogue)
+
+	route.Get("/stream", h.Stream)
+
+	route.Get("/anomalies", h.GetAnomalies)
+
+	route.Get("/alerts", h.GetAlerts)
+	route.Post("/alerts/rules", h.CreateAlertRule)
+	route.Get("/alerts/rules", h.GetAlertRules)
+	route.Delete("/alerts/rules/:id", h.DeleteAlertRule)
+
+	// the webhooks hold the secrets and the payloads of the events, they are managed with the admin API key only
+	if h.cfg.Admin.APIKey != "" {
+		route.Post("/webhooks", h.authenticateAdmin, h.CreateWebhook)
+		route.Get("/webhooks", h.authenticateAdmin, h.GetWebhooks)
+		route.Delete("/webhooks/:id", h.authenticateAdmin, h.DeleteWebhook)
+		route.Get("/webhooks/:id/deliveries", h.authenticateAdmin, h.GetWebhookDeliveries)
+		route.Get("/webhooks/:id/dead-letters", h.authenticateAdmin, h.GetDeadLetters)
+	}
+
+	// the admin API is disabled without its API key
+	if h.cfg.Admin.APIKey != "" {
+		admin := route.Group("/admin", h.authenticateAdmin)
+		admin.Get("/faults", h.GetFaultRules)
+		admin.Post("/faults", h.CreateFaultRule)
+		admin.Get("/faults/active", h.GetActiveFaults)
+		admin.Patch("/faults/:id", h.UpdateFaultRule)
+		admin.Delete("/faults/:id", h.DeleteFaultRule)
+		admin.Get("/scheduler", h.GetSchedulerStats)
+		admin.Get("/tasks", h.GetTasks)
+	}
+
+	route.Get("/groups", h.GetGroups)
+	route.Get("/groups/:groupName", h.GetGroup)
+	route.Post("/regions", h.CreateRegion)
+	route.Get("/regions", h.GetRegions)
+	route.Get("/regions/:name", h.GetRegion)
+	route.Delete("/regions/:name", h.DeleteRegion)
+	route.Get("/regions/:name/series", h.GetRegionSeries)
+	route.Get("/sensors", h.GetSensors)
+	route.Get("/sensors/nearest", h.GetNearestSensors)
+	route.Get("/sensors/status", h.GetSensorStatuses)
+	route.Get("/sensors/:codename", h.GetSensor)
+
+	// the groups and the sensors are changed with the admin API key only
+	if h.cfg.Admin.APIKey != "" {
+		route.Post("/groups", h.authenticateAdmin, h.CreateGroup)
+		route.Patch("/groups/:groupName", h.authenticateAdmin, h.UpdateGroup)
+		route.Delete("/groups/:groupName", h.authenticateAdmin, h.DeleteGroup)
+		route.Post("/sensors", h.authenticateAdmin, h.CreateSensor)
+		route.Patch("/sensors/:codename", h.authenticateAdmin, h.UpdateSensor)
+		route.Delete("/sensors/:codename", h.authenticateAdmin, h.DeleteSensor)
+	}
 }
 func (h *Handler) RegisterSwagger(a *fiber.App) {
 	// Create routes group.
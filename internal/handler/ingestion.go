@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/internal/service"
+	"github.com/PavelDonchenko/sensor-go/internal/storage"
+	"github.com/gofiber/fiber/v2"
+)
+
+// apiKeyHeader is the header live sensors and admins authenticate with.
+const apiKeyHeader = "X-API-Key"
+
+// authenticateIngestion rejects the requests without the configured ingestion API key,
+// every request is rejected if no key is configured.
+func (h *Handler) authenticateIngestion(c *fiber.Ctx) error {
+	key := h.cfg.Ingestion.APIKey
+	provided := c.Get(apiKeyHeader)
+
+	if key == "" || subtle.ConstantTimeCompare([]byte(key), []byte(provided)) != 1 {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": true,
+			"msg":   "invalid or missing API key",
+		})
+	}
+
+	return c.Next()
+}
+
+// IngestMeasurements saves the measurements reported by a live sensor.
+//
+// @Summary Ingest sensor measurements
+// @Description Saves a single measurement or a batch of measurements reported by a sensor. The body is either one measurement object or an array of them, the whole batch is rejected if any measurement is invalid.
+// @Tags sensor
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param codename path string true "name of the group and id inside the group"
+// @Param measurements body []domain.IngestMeasurement true "measurement or array of measurements"
+// @Success 201 {array} domain.Measurement
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Failure 422 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/sensor/{codename}/measurements [post]
+func (h *Handler) IngestMeasurements(c *fiber.Ctx) error {
+	codename := c.Params("codename")
+
+	measurements, err := domain.DecodeIngestMeasurements(c.Body())
+	if err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	saved, err := h.service.IngestMeasurements(h.ctx, codename, measurements)
+	if err != nil {
+		return c.Status(ingestionErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"error":        false,
+		"msg":          nil,
+		"measurements": saved,
+	})
+}
+
+func ingestionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, storage.ErrSensorNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, service.ErrorEmptyBatch), errors.Is(err, service.ErrorBatchTooLarge),
+		errors.Is(err, service.ErrorMissingTemperature), errors.Is(err, service.ErrorWrongTemperature),
+		errors.Is(err, service.ErrorWrongTransparency), errors.Is(err, service.ErrorWrongFishName),
+		errors.Is(err, service.ErrorWrongFishCount), errors.Is(err, service.ErrorFutureTimestamp),
+		errors.Is(err, service.ErrorOldTimestamp):
+		return fiber.StatusUnprocessableEntity
+	}
+
+	return fiber.StatusInternalServerError
+}
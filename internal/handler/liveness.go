@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetSensorStatuses retrieves the liveness of the sensors.
+//
+// @Summary Get sensor liveness
+// @Description Retrieves whether the sensors report their data at the expected cadence: a sensor which missed liveness.late_after data output intervals is late, after liveness.offline_after intervals it is offline, its next reading makes it online again.
+// @Tags sensors
+// @Accept json
+// @Produce json
+// @Param group query string false "Name of the sensor group"
+// @Param status query string false "online, late or offline"
+// @Success 200 {array} domain.SensorStatus
+// @Failure 404 {string} string
+// @Failure 422 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/sensors/status [get]
+func (h *Handler) GetSensorStatuses(c *fiber.Ctx) error {
+	groupName := strings.ToLower(c.Query("group"))
+	status := domain.LivenessStatus(strings.ToLower(c.Query("status")))
+
+	statuses, err := h.service.GetSensorStatuses(h.ctx, groupName, status)
+	if err != nil {
+		return c.Status(livenessErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":    false,
+		"msg":      nil,
+		"statuses": statuses,
+	})
+}
+
+// queryBool reports whether the query parameter is set to a true value, e.g. 1 or true.
+func queryBool(c *fiber.Ctx, key string) bool {
+	value, _ := strconv.ParseBool(c.Query(key))
+
+	return value
+}
+
+func livenessErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrorWrongGroupName), errors.Is(err, service.ErrorNoOnlineSensors):
+		return fiber.StatusNotFound
+	case errors.Is(err, service.ErrorWrongLivenessStatus):
+		return fiber.StatusUnprocessableEntity
+	}
+
+	return fiber.StatusInternalServerError
+}
+
+// groupAverageErrorStatus keeps the group averages failing with 500 on any error but the lack of online sensors.
+func groupAverageErrorStatus(err error) int {
+	if errors.Is(err, service.ErrorNoOnlineSensors) {
+		return fiber.StatusNotFound
+	}
+
+	return fiber.StatusInternalServerError
+}
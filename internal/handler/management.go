@@ -0,0 +1,369 @@
+package handler
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/internal/service"
+	"github.com/PavelDonchenko/sensor-go/internal/storage"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateGroup registers a new sensor group.
+//
+// @Summary Create a sensor group
+// @Description Registers a new sensor group, the group name must consist of lowercase letters only.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param group body domain.CreateGroup true "group to create"
+// @Success 201 {object} domain.SensorGroup
+// @Failure 401 {string} string
+// @Failure 409 {string} string
+// @Failure 422 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/groups [post]
+func (h *Handler) CreateGroup(c *fiber.Ctx) error {
+	var req domain.CreateGroup
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	group, err := h.service.CreateGroup(h.ctx, req)
+	if err != nil {
+		return c.Status(managementErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"error": false,
+		"msg":   nil,
+		"group": group,
+	})
+}
+
+// GetGroups retrieves all sensor groups with their sensors.
+//
+// @Summary Get sensor groups
+// @Description Retrieves all sensor groups with their sensors.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Success 200 {array} domain.SensorGroup
+// @Failure 500 {string} string
+// @Router /api/v1/groups [get]
+func (h *Handler) GetGroups(c *fiber.Ctx) error {
+	groups, err := h.service.GetGroups(h.ctx)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":  false,
+		"msg":    nil,
+		"groups": groups,
+	})
+}
+
+// GetGroup retrieves a sensor group with its sensors.
+//
+// @Summary Get a sensor group
+// @Description Retrieves a sensor group with its sensors based on the provided group name.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param groupName path string true "Name of the sensor group"
+// @Success 200 {object} domain.SensorGroup
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/groups/{groupName} [get]
+func (h *Handler) GetGroup(c *fiber.Ctx) error {
+	groupName := c.Params("groupName")
+
+	group, err := h.service.GetGroup(h.ctx, strings.ToLower(groupName))
+	if err != nil {
+		return c.Status(managementErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"msg":   nil,
+		"group": group,
+	})
+}
+
+// UpdateGroup renames a sensor group, the codenames of its sensors follow the new name.
+//
+// @Summary Rename a sensor group
+// @Description Renames a sensor group, the codenames of its sensors follow the new name.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param groupName path string true "Name of the sensor group"
+// @Param group body domain.UpdateGroup true "new group name"
+// @Success 200 {object} domain.SensorGroup
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Failure 409 {string} string
+// @Failure 422 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/groups/{groupName} [patch]
+func (h *Handler) UpdateGroup(c *fiber.Ctx) error {
+	groupName := c.Params("groupName")
+
+	var req domain.UpdateGroup
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	group, err := h.service.UpdateGroup(h.ctx, strings.ToLower(groupName), req)
+	if err != nil {
+		return c.Status(managementErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"msg":   nil,
+		"group": group,
+	})
+}
+
+// DeleteGroup deletes a sensor group together with its sensors and their data.
+//
+// @Summary Delete a sensor group
+// @Description Deletes a sensor group together with its sensors and their data, the data generation for them stops.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param groupName path string true "Name of the sensor group"
+// @Success 200 {string} string
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/groups/{groupName} [delete]
+func (h *Handler) DeleteGroup(c *fiber.Ctx) error {
+	groupName := c.Params("groupName")
+
+	err := h.service.DeleteGroup(h.ctx, strings.ToLower(groupName))
+	if err != nil {
+		return c.Status(managementErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"msg":   nil,
+	})
+}
+
+// CreateSensor registers a new sensor in a group.
+//
+// @Summary Register a sensor
+// @Description Registers a new sensor with explicit coordinates and data output rate, the index inside the group is assigned automatically and the data generation starts right away.
+// @Tags sensors
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param sensor body domain.CreateSensor true "sensor to register"
+// @Success 201 {object} domain.Sensor
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Failure 422 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/sensors [post]
+func (h *Handler) CreateSensor(c *fiber.Ctx) error {
+	var req domain.CreateSensor
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	sensor, err := h.service.CreateSensor(h.ctx, req)
+	if err != nil {
+		return c.Status(managementErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"error":  false,
+		"msg":    nil,
+		"sensor": sensor,
+	})
+}
+
+// GetSensors retrieves the sensors, optionally only the ones of a group.
+//
+// @Summary Get sensors
+// @Description Retrieves all sensors or the sensors of the group provided in the group parameter.
+// @Tags sensors
+// @Accept json
+// @Produce json
+// @Param group query string false "Name of the sensor group"
+// @Success 200 {array} domain.Sensor
+// @Failure 500 {string} string
+// @Router /api/v1/sensors [get]
+func (h *Handler) GetSensors(c *fiber.Ctx) error {
+	groupName := c.Query("group")
+
+	sensors, err := h.service.GetSensors(h.ctx, strings.ToLower(groupName))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"msg":     nil,
+		"sensors": sensors,
+	})
+}
+
+// GetSensor retrieves a sensor by its codename.
+//
+// @Summary Get a sensor
+// @Description Retrieves a sensor by its codename.
+// @Tags sensors
+// @Accept json
+// @Produce json
+// @Param codename path string true "name of the group and id inside the group"
+// @Success 200 {object} domain.Sensor
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/sensors/{codename} [get]
+func (h *Handler) GetSensor(c *fiber.Ctx) error {
+	codename := c.Params("codename")
+
+	sensor, err := h.service.GetSensor(h.ctx, codename)
+	if err != nil {
+		return c.Status(managementErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":  false,
+		"msg":    nil,
+		"sensor": sensor,
+	})
+}
+
+// UpdateSensor changes the coordinates, the data output rate or the group of a sensor.
+//
+// @Summary Update a sensor
+// @Description Changes the coordinates, the data output rate or the group of a sensor, the data generation is retuned right away. A sensor moved to another group gets the next index of that group.
+// @Tags sensors
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param codename path string true "name of the group and id inside the group"
+// @Param sensor body domain.UpdateSensor true "fields to change"
+// @Success 200 {object} domain.Sensor
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Failure 422 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/sensors/{codename} [patch]
+func (h *Handler) UpdateSensor(c *fiber.Ctx) error {
+	codename := c.Params("codename")
+
+	var req domain.UpdateSensor
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	sensor, err := h.service.UpdateSensor(h.ctx, codename, req)
+	if err != nil {
+		return c.Status(managementErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":  false,
+		"msg":    nil,
+		"sensor": sensor,
+	})
+}
+
+// DeleteSensor retires a sensor.
+//
+// @Summary Retire a sensor
+// @Description Deletes a sensor together with its data, the data generation for it stops.
+// @Tags sensors
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param codename path string true "name of the group and id inside the group"
+// @Success 200 {string} string
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/sensors/{codename} [delete]
+func (h *Handler) DeleteSensor(c *fiber.Ctx) error {
+	codename := c.Params("codename")
+
+	err := h.service.DeleteSensor(h.ctx, codename)
+	if err != nil {
+		return c.Status(managementErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"msg":   nil,
+	})
+}
+
+func managementErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, storage.ErrGroupNotFound), errors.Is(err, storage.ErrSensorNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, storage.ErrGroupExists):
+		return fiber.StatusConflict
+	case errors.Is(err, service.ErrorWrongDataOutputRate), errors.Is(err, service.ErrorInvalidGroupName),
+		errors.Is(err, service.ErrorWrongSensorSource):
+		return fiber.StatusUnprocessableEntity
+	}
+
+	return fiber.StatusInternalServerError
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"strings"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateRegion persists a named region of interest.
+//
+// @Summary Create a named region
+// @Description Persists a box or a sphere under a name, the name can be passed to the region routes instead of the coordinates.
+// @Tags regions
+// @Accept json
+// @Produce json
+// @Param region body domain.CreateRegion true "region to create, either box or sphere"
+// @Success 201 {object} domain.NamedRegion
+// @Failure 409 {string} string
+// @Failure 422 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/regions [post]
+func (h *Handler) CreateRegion(c *fiber.Ctx) error {
+	var req domain.CreateRegion
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	region, err := h.service.CreateRegion(h.ctx, req)
+	if err != nil {
+		return c.Status(regionErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"error":  false,
+		"msg":    nil,
+		"region": region,
+	})
+}
+
+// GetRegions retrieves all named regions.
+//
+// @Summary Get named regions
+// @Description Retrieves all named regions.
+// @Tags regions
+// @Accept json
+// @Produce json
+// @Success 200 {array} domain.NamedRegion
+// @Failure 500 {string} string
+// @Router /api/v1/regions [get]
+func (h *Handler) GetRegions(c *fiber.Ctx) error {
+	regions, err := h.service.GetRegions(h.ctx)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"msg":     nil,
+		"regions": regions,
+	})
+}
+
+// GetRegion retrieves a named region.
+//
+// @Summary Get a named region
+// @Description Retrieves a named region by its name.
+// @Tags regions
+// @Accept json
+// @Produce json
+// @Param name path string true "Name of the region"
+// @Success 200 {object} domain.NamedRegion
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/regions/{name} [get]
+func (h *Handler) GetRegion(c *fiber.Ctx) error {
+	name := c.Params("name")
+
+	region, err := h.service.GetRegion(h.ctx, strings.ToLower(name))
+	if err != nil {
+		return c.Status(regionErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":  false,
+		"msg":    nil,
+		"region": region,
+	})
+}
+
+// DeleteRegion deletes a named region.
+//
+// @Summary Delete a named region
+// @Description Deletes a named region, the sensors inside it are not affected.
+// @Tags regions
+// @Accept json
+// @Produce json
+// @Param name path string true "Name of the region"
+// @Success 200 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/regions/{name} [delete]
+func (h *Handler) DeleteRegion(c *fiber.Ctx) error {
+	name := c.Params("name")
+
+	err := h.service.DeleteRegion(h.ctx, strings.ToLower(name))
+	if err != nil {
+		return c.Status(regionErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"msg":   nil,
+	})
+}
+
+// GetRegionSeries retrieves the bucketed time series of a metric reported by the sensors inside a named region.
+//
+// @Summary Get a time series for a named region
+// @Description Retrieves the metric values reported by the sensors currently inside the named region aggregated into buckets, the start of the period is rounded down to the bucket start.
+// @Tags regions
+// @Accept json
+// @Produce json
+// @Param name path string true "Name of the region"
+// @Param metric query string true "temperature or transparency"
+// @Param from query string true "Start date for the period (UNIX timestamp)"
+// @Param till query string true "End date for the period (UNIX timestamp)"
+// @Param bucket query string false "1m, 1h or 1d, 1h by default"
+// @Param agg query string false "avg, min, max or p95, avg by default"
+// @Success 200 {array} domain.SeriesPoint
+// @Failure 404 {string} string
+// @Failure 422 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/regions/{name}/series [get]
+func (h *Handler) GetRegionSeries(c *fiber.Ctx) error {
+	name := c.Params("name")
+
+	query, err := parseSeriesQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	series, err := h.service.GetRegionSeries(h.ctx, strings.ToLower(name), query)
+	if err != nil {
+		status := seriesErrorStatus(err)
+		if status == fiber.StatusInternalServerError {
+			status = regionErrorStatus(err)
+		}
+
+		return c.Status(status).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":  false,
+		"msg":    nil,
+		"series": series,
+	})
+}
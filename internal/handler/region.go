@@ -0,0 +1,571 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/internal/service"
+	"github.com/PavelDonchenko/sensor-go/internal/storage"
+	"github.com/PavelDonchenko/sensor-go/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GeRegionMinTemperature retrieves the minimum temperature according to region.
+//
+// @Summary Get minimum temperature according to region.
+// @Description Retrieves the current minimum temperature of the sensors inside the region, or the minimum they measured during the period if from and till are provided.
+// @Tags region
+// @Accept json
+// @Produce json
+// @Param xMin query number false "minimum X coordinate of the box"
+// @Param xMax query number false "maximum X coordinate of the box"
+// @Param yMin query number false "minimum Y coordinate of the box"
+// @Param yMax query number false "maximum Y coordinate of the box"
+// @Param zMin query number false "minimum Z coordinate of the box"
+// @Param zMax query number false "maximum Z coordinate of the box"
+// @Param x query number false "X coordinate of the sphere center"
+// @Param y query number false "Y coordinate of the sphere center"
+// @Param z query number false "Z coordinate of the sphere center"
+// @Param radius query number false "radius of the sphere, the box is used if it is not provided"
+// @Param region query string false "name of a persisted region, used instead of the coordinates"
+// @Param from query string false "Start date for the period (UNIX timestamp)"
+// @Param till query string false "End date for the period (UNIX timestamp), now by default"
+// @Success 200 {number} number
+// @Failure 404 {string} string
+// @Failure 422 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/region/temperature/min [get]
+func (h *Handler) GeRegionMinTemperature(c *fiber.Ctx) error {
+	return h.getRegionTemperature(c, domain.AggregationMin, 0)
+}
+
+// GeRegionMaxTemperature retrieves the maximum temperature according to region.
+//
+// @Summary Get maximum temperature according to region.
+// @Description Retrieves the current maximum temperature of the sensors inside the region, or the maximum they measured during the period if from and till are provided.
+// @Tags region
+// @Accept json
+// @Produce json
+// @Param xMin query number false "minimum X coordinate of the box"
+// @Param xMax query number false "maximum X coordinate of the box"
+// @Param yMin query number false "minimum Y coordinate of the box"
+// @Param yMax query number false "maximum Y coordinate of the box"
+// @Param zMin query number false "minimum Z coordinate of the box"
+// @Param zMax query number false "maximum Z coordinate of the box"
+// @Param x query number false "X coordinate of the sphere center"
+// @Param y query number false "Y coordinate of the sphere center"
+// @Param z query number false "Z coordinate of the sphere center"
+// @Param radius query number false "radius of the sphere, the box is used if it is not provided"
+// @Param region query string false "name of a persisted region, used instead of the coordinates"
+// @Param from query string false "Start date for the period (UNIX timestamp)"
+// @Param till query string false "End date for the period (UNIX timestamp), now by default"
+// @Success 200 {number} number
+// @Failure 404 {string} string
+// @Failure 422 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/region/temperature/max [get]
+func (h *Handler) GeRegionMaxTemperature(c *fiber.Ctx) error {
+	return h.getRegionTemperature(c, domain.AggregationMax, 0)
+}
+
+// GetRegionAverageTemperature retrieves the average temperature according to region.
+//
+// @Summary Get average temperature according to region.
+// @Description Retrieves the current average temperature of the sensors inside the region, or the average they measured during the period if from and till are provided.
+// @Tags region
+// @Accept json
+// @Produce json
+// @Param xMin query number false "minimum X coordinate of the box"
+// @Param xMax query number false "maximum X coordinate of the box"
+// @Param yMin query number false "minimum Y coordinate of the box"
+// @Param yMax query number false "maximum Y coordinate of the box"
+// @Param zMin query number false "minimum Z coordinate of the box"
+// @Param zMax query number false "maximum Z coordinate of the box"
+// @Param x query number false "X coordinate of the sphere center"
+// @Param y query number false "Y coordinate of the sphere center"
+// @Param z query number false "Z coordinate of the sphere center"
+// @Param radius query number false "radius of the sphere, the box is used if it is not provided"
+// @Param region query string false "name of a persisted region, used instead of the coordinates"
+// @Param from query string false "Start date for the period (UNIX timestamp)"
+// @Param till query string false "End date for the period (UNIX timestamp), now by default"
+// @Success 200 {number} number
+// @Failure 404 {string} string
+// @Failure 422 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/region/temperature/average [get]
+func (h *Handler) GetRegionAverageTemperature(c *fiber.Ctx) error {
+	return h.getRegionTemperature(c, domain.AggregationAvg, 0)
+}
+
+// GetRegionPercentileTemperature retrieves a temperature percentile according to region.
+//
+// @Summary Get temperature percentile according to region.
+// @Description Retrieves the percentile of the current temperature of the sensors inside the region, or of the temperature they measured during the period if from and till are provided.
+// @Tags region
+// @Accept json
+// @Produce json
+// @Param xMin query number false "minimum X coordinate of the box"
+// @Param xMax query number false "maximum X coordinate of the box"
+// @Param yMin query number false "minimum Y coordinate of the box"
+// @Param yMax query number false "maximum Y coordinate of the box"
+// @Param zMin query number false "minimum Z coordinate of the box"
+// @Param zMax query number false "maximum Z coordinate of the box"
+// @Param x query number false "X coordinate of the sphere center"
+// @Param y query number false "Y coordinate of the sphere center"
+// @Param z query number false "Z coordinate of the sphere center"
+// @Param radius query number false "radius of the sphere, the box is used if it is not provided"
+// @Param region query string false "name of a persisted region, used instead of the coordinates"
+// @Param p query number false "percentile between 0 and 100, 95 by default"
+// @Param from query string false "Start date for the period (UNIX timestamp)"
+// @Param till query string false "End date for the period (UNIX timestamp), now by default"
+// @Success 200 {number} number
+// @Failure 404 {string} string
+// @Failure 422 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/region/temperature/percentile [get]
+func (h *Handler) GetRegionPercentileTemperature(c *fiber.Ctx) error {
+	percentile, err := strconv.ParseFloat(c.Query("p", "95"), 64)
+	if err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": true,
+			"msg":   service.ErrorWrongPercentile.Error(),
+		})
+	}
+
+	return h.getRegionTemperature(c, domain.AggregationPercentile, percentile)
+}
+
+// GetRegionTransparency retrieves the average transparency according to region.
+//
+// @Summary Get average transparency according to region.
+// @Description Retrieves the current average transparency of the sensors inside the region, or the average they measured during the period if from and till are provided.
+// @Tags region
+// @Accept json
+// @Produce json
+// @Param xMin query number false "minimum X coordinate of the box"
+// @Param xMax query number false "maximum X coordinate of the box"
+// @Param yMin query number false "minimum Y coordinate of the box"
+// @Param yMax query number false "maximum Y coordinate of the box"
+// @Param zMin query number false "minimum Z coordinate of the box"
+// @Param zMax query number false "maximum Z coordinate of the box"
+// @Param x query number false "X coordinate of the sphere center"
+// @Param y query number false "Y coordinate of the sphere center"
+// @Param z query number false "Z coordinate of the sphere center"
+// @Param radius query number false "radius of the sphere, the box is used if it is not provided"
+// @Param region query string false "name of a persisted region, used instead of the coordinates"
+// @Param from query string false "Start date for the period (UNIX timestamp)"
+// @Param till query string false "End date for the period (UNIX timestamp), now by default"
+// @Success 200 {number} number
+// @Failure 404 {string} string
+// @Failure 422 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/region/transparency/average [get]
+func (h *Handler) GetRegionTransparency(c *fiber.Ctx) error {
+	area, err := h.parseArea(c)
+	if err != nil {
+		return c.Status(regionErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	start, end := h.parsePeriod(c)
+
+	transparency, sensors, err := h.service.GetRegionTransparency(h.ctx, area, start, end)
+	if err != nil {
+		return c.Status(regionErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	if transparency == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   true,
+			"msg":     "no transparency data for the period",
+			"sensors": codenames(sensors),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":               false,
+		"msg":                 periodMessage(start, end),
+		"region transparency": *transparency,
+		"sensors":             codenames(sensors),
+	})
+}
+
+// GetRegionSpecies retrieves the species currently detected inside the region.
+//
+// @Summary Get current detected fish species according to region.
+// @Description Retrieves the species detected by the last measurements of the sensors inside the region.
+// @Tags region
+// @Accept json
+// @Produce json
+// @Param xMin query number false "minimum X coordinate of the box"
+// @Param xMax query number false "maximum X coordinate of the box"
+// @Param yMin query number false "minimum Y coordinate of the box"
+// @Param yMax query number false "maximum Y coordinate of the box"
+// @Param zMin query number false "minimum Z coordinate of the box"
+// @Param zMax query number false "maximum Z coordinate of the box"
+// @Param x query number false "X coordinate of the sphere center"
+// @Param y query number false "Y coordinate of the sphere center"
+// @Param z query number false "Z coordinate of the sphere center"
+// @Param radius query number false "radius of the sphere, the box is used if it is not provided"
+// @Param region query string false "name of a persisted region, used instead of the coordinates"
+// @Success 200 {array} domain.ResponseDetectedFish
+// @Failure 404 {string} string
+// @Failure 422 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/region/species [get]
+func (h *Handler) GetRegionSpecies(c *fiber.Ctx) error {
+	area, err := h.parseArea(c)
+	if err != nil {
+		return c.Status(regionErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	species, sensors, err := h.service.GetRegionSpecies(h.ctx, area)
+	if err != nil {
+		return c.Status(regionErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"msg":     nil,
+		"species": responseSpecies(species),
+		"sensors": codenames(sensors),
+	})
+}
+
+// GetRegionTopSpecies retrieves the top detected fish species inside the region.
+//
+// @Summary Get top detected fish species according to region.
+// @Description Retrieves the top species detected by the last measurements of the sensors inside the region, or during the period if from and till are provided.
+// @Tags region
+// @Accept json
+// @Produce json
+// @Param top path integer true "Number of top species to retrieve"
+// @Param xMin query number false "minimum X coordinate of the box"
+// @Param xMax query number false "maximum X coordinate of the box"
+// @Param yMin query number false "minimum Y coordinate of the box"
+// @Param yMax query number false "maximum Y coordinate of the box"
+// @Param zMin query number false "minimum Z coordinate of the box"
+// @Param zMax query number false "maximum Z coordinate of the box"
+// @Param x query number false "X coordinate of the sphere center"
+// @Param y query number false "Y coordinate of the sphere center"
+// @Param z query number false "Z coordinate of the sphere center"
+// @Param radius query number false "radius of the sphere, the box is used if it is not provided"
+// @Param region query string false "name of a persisted region, used instead of the coordinates"
+// @Param from query string false "Start date for the period (UNIX timestamp)"
+// @Param till query string false "End date for the period (UNIX timestamp), now by default"
+// @Success 200 {array} domain.ResponseDetectedFish
+// @Failure 404 {string} string
+// @Failure 422 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/region/species/top/{top} [get]
+func (h *Handler) GetRegionTopSpecies(c *fiber.Ctx) error {
+	top, err := strconv.Atoi(c.Params("top"))
+	if err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	area, err := h.parseArea(c)
+	if err != nil {
+		return c.Status(regionErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	start, end := h.parsePeriod(c)
+
+	species, sensors, err := h.service.GetRegionTopSpecies(h.ctx, area, start, end, top)
+	if err != nil {
+		return c.Status(regionErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"msg":     periodMessage(start, end),
+		"species": responseSpecies(species),
+		"sensors": codenames(sensors),
+	})
+}
+
+// GetRegionSensors retrieves the sensors inside the region.
+//
+// @Summary Get sensors according to region.
+// @Description Retrieves the sensors inside the region, these are the sensors the region statistics are computed from.
+// @Tags region
+// @Accept json
+// @Produce json
+// @Param xMin query number false "minimum X coordinate of the box"
+// @Param xMax query number false "maximum X coordinate of the box"
+// @Param yMin query number false "minimum Y coordinate of the box"
+// @Param yMax query number false "maximum Y coordinate of the box"
+// @Param zMin query number false "minimum Z coordinate of the box"
+// @Param zMax query number false "maximum Z coordinate of the box"
+// @Param x query number false "X coordinate of the sphere center"
+// @Param y query number false "Y coordinate of the sphere center"
+// @Param z query number false "Z coordinate of the sphere center"
+// @Param radius query number false "radius of the sphere, the box is used if it is not provided"
+// @Param region query string false "name of a persisted region, used instead of the coordinates"
+// @Success 200 {array} domain.Sensor
+// @Failure 404 {string} string
+// @Failure 422 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/region/sensors [get]
+func (h *Handler) GetRegionSensors(c *fiber.Ctx) error {
+	area, err := h.parseArea(c)
+	if err != nil {
+		return c.Status(regionErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	sensors, err := h.service.GetRegionSensors(h.ctx, area)
+	if err != nil {
+		return c.Status(regionErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"msg":     nil,
+		"sensors": sensors,
+	})
+}
+
+// GetNearestSensors retrieves the sensors closest to a point.
+//
+// @Summary Get the nearest sensors
+// @Description Retrieves the k sensors closest to the point with their distances, nearest first.
+// @Tags sensors
+// @Accept json
+// @Produce json
+// @Param x query number true "X coordinate of the point"
+// @Param y query number true "Y coordinate of the point"
+// @Param z query number true "Z coordinate of the point"
+// @Param k query integer false "number of sensors, 1 by default"
+// @Success 200 {array} domain.SensorDistance
+// @Failure 422 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/sensors/nearest [get]
+func (h *Handler) GetNearestSensors(c *fiber.Ctx) error {
+	var point domain.Coordinates
+
+	err := parseFloatQueries(c, map[string]*float64{
+		"x": &point.X,
+		"y": &point.Y,
+		"z": &point.Z,
+	})
+	if err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	k, err := strconv.Atoi(c.Query("k", "1"))
+	if err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": true,
+			"msg":   service.ErrorWrongNearestK.Error(),
+		})
+	}
+
+	sensors, err := h.service.GetNearestSensors(h.ctx, point, k)
+	if err != nil {
+		return c.Status(regionErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"msg":     nil,
+		"sensors": sensors,
+	})
+}
+
+func (h *Handler) getRegionTemperature(c *fiber.Ctx, agg domain.Aggregation, percentile float64) error {
+	area, err := h.parseArea(c)
+	if err != nil {
+		return c.Status(regionErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	start, end := h.parsePeriod(c)
+
+	temperature, sensors, err := h.service.GetRegionTemperature(h.ctx, area, agg, percentile, start, end)
+	if err != nil {
+		return c.Status(regionErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	if temperature == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   true,
+			"msg":     "no temperature data for the period",
+			"sensors": codenames(sensors),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":              false,
+		"msg":                periodMessage(start, end),
+		"region temperature": *temperature,
+		"sensors":            codenames(sensors),
+	})
+}
+
+// parseArea reads the region from the query parameters: the named region if its name is provided,
+// a sphere if the radius is provided, a box otherwise. Every bound of the chosen shape is required.
+func (h *Handler) parseArea(c *fiber.Ctx) (domain.Area, error) {
+	if name := c.Query("region"); name != "" {
+		region, err := h.service.GetRegion(h.ctx, strings.ToLower(name))
+		if err != nil {
+			return domain.Area{}, err
+		}
+
+		return region.Area(), nil
+	}
+
+	if c.Query("radius") != "" {
+		var sphere domain.Sphere
+
+		err := parseFloatQueries(c, map[string]*float64{
+			"x":      &sphere.Center.X,
+			"y":      &sphere.Center.Y,
+			"z":      &sphere.Center.Z,
+			"radius": &sphere.Radius,
+		})
+
+		return domain.Area{Sphere: &sphere}, err
+	}
+
+	var region domain.Region
+
+	err := parseFloatQueries(c, map[string]*float64{
+		"xMin": &region.XMin,
+		"xMax": &region.XMax,
+		"yMin": &region.YMin,
+		"yMax": &region.YMax,
+		"zMin": &region.ZMin,
+		"zMax": &region.ZMax,
+	})
+
+	return domain.Area{Box: &region}, err
+}
+
+// queryError is an invalid query parameter.
+type queryError string
+
+func (e queryError) Error() string {
+	return string(e)
+}
+
+func parseFloatQueries(c *fiber.Ctx, values map[string]*float64) error {
+	for key, dest := range values {
+		value, err := strconv.ParseFloat(c.Query(key), 64)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+			return queryError(key + " must be a finite number")
+		}
+
+		*dest = value
+	}
+
+	return nil
+}
+
+// parsePeriod reads the optional from and till UNIX timestamps as the storage date/time strings,
+// a period without till lasts until the current time of the simulation.
+func (h *Handler) parsePeriod(c *fiber.Ctx) (string, string) {
+	start := c.Query("from")
+	if start != "" {
+		start = utils.ParseUnixToString(start)
+	}
+
+	end := c.Query("till")
+	switch {
+	case end != "":
+		end = utils.ParseUnixToString(end)
+	case start != "":
+		end = h.service.Now().UTC().Format(utils.TimeLayout)
+	}
+
+	return start, end
+}
+
+func periodMessage(start, end string) string {
+	if start == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("for period from %s till %s", start, end)
+}
+
+func codenames(sensors []domain.Sensor) []string {
+	res := make([]string, 0, len(sensors))
+
+	for _, sensor := range sensors {
+		res = append(res, sensor.Codename.String())
+	}
+
+	return res
+}
+
+func responseSpecies(species []domain.DetectedFish) []domain.ResponseDetectedFish {
+	var res []domain.ResponseDetectedFish
+
+	for _, fish := range species {
+		res = append(res, domain.ResponseDetectedFish{
+			Name:  fish.Name,
+			Count: fish.Count,
+		})
+	}
+
+	return res
+}
+
+func regionErrorStatus(err error) int {
+	var qErr queryError
+
+	switch {
+	case errors.Is(err, service.ErrorEmptyRegion), errors.Is(err, storage.ErrRegionNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, storage.ErrRegionExists):
+		return fiber.StatusConflict
+	case errors.As(err, &qErr), errors.Is(err, service.ErrorInvalidRegionName):
+		return fiber.StatusUnprocessableEntity
+	case errors.Is(err, service.ErrorWrongRegion), errors.Is(err, service.ErrorWrongPercentile),
+		errors.Is(err, service.ErrorWrongTop), errors.Is(err, service.ErrorWrongNearestK):
+		return fiber.StatusUnprocessableEntity
+	}
+
+	return fiber.StatusInternalServerError
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetSchedulerStats retrieves the metrics of the simulated data generation.
+//
+// @Summary Get scheduler stats
+// @Description Retrieves the metrics of the scheduler generating the simulated readings: the scheduled sensors, the due readings waiting for a writer, the written, lost and failed readings and the lag between the time a reading is due and the time it is written, in milliseconds of the simulation clock.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} object{error=bool,msg=string,scheduler=domain.SchedulerStats}
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Router /api/v1/admin/scheduler [get]
+func (h *Handler) GetSchedulerStats(c *fiber.Ctx) error {
+	stats, err := h.service.GetSchedulerStats()
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":     false,
+		"msg":       nil,
+		"scheduler": stats,
+	})
+}
@@ -14,28 +14,53 @@ import (
 // GetTransparency retrieves the transparency percentage for a sensor group.
 //
 // @Summary Get transparency percentage for a sensor group
-// @Description Retrieves the transparency percentage for a sensor group based on the provided group name.
+// @Description Retrieves the current transparency percentage for a sensor group, or the historical average for the period if from and till are provided.
 // @Tags group
 // @Accept json
 // @Produce json
 // @Param groupName path string true "Name of the sensor group"
+// @Param from query string false "Start date for the period (UNIX timestamp)"
+// @Param till query string false "End date for the period (UNIX timestamp), now by default"
+// @Param excludeOffline query bool false "leave the offline sensors out of the current average"
 // @Success 200 {number} number "transparency"
+// @Failure 404 {string} string
 // @Failure 500 {string} string
 // @Router /api/v1/group/{groupName}/transparency/average [get]
 func (h *Handler) GetTransparency(c *fiber.Ctx) error {
 	groupName := c.Params("groupName")
 
-	transparency, err := h.service.GetTransparency(h.ctx, strings.ToLower(groupName))
+	start, end := h.parsePeriod(c)
+
+	var transparency *float64
+	var err error
+	var msg string
+
+	switch {
+	case start != "":
+		transparency, err = h.service.GetTransparencyForPeriod(h.ctx, strings.ToLower(groupName), start, end)
+		msg = fmt.Sprintf("transparency for period from %s till %s", start, end)
+	case queryBool(c, "excludeOffline"):
+		transparency, err = h.service.GetOnlineTransparency(h.ctx, strings.ToLower(groupName))
+	default:
+		transparency, err = h.service.GetTransparency(h.ctx, strings.ToLower(groupName))
+	}
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(groupAverageErrorStatus(err)).JSON(fiber.Map{
 			"error": true,
 			"msg":   err.Error(),
 		})
 	}
 
+	if transparency == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": true,
+			"msg":   "no transparency data for the period",
+		})
+	}
+
 	return c.JSON(fiber.Map{
 		"error":          false,
-		"msg":            nil,
+		"msg":            msg,
 		"transparency %": *transparency,
 	})
 }
@@ -48,20 +73,36 @@ func (h *Handler) GetTransparency(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param groupName path string true "Name of the sensor group"
+// @Param excludeOffline query bool false "leave the offline sensors out of the average"
 // @Success 200 {number} number "temperature"
+// @Failure 404 {string} string
 // @Failure 500 {string} string
 // @Router /api/v1/group/{groupName}/temperature/average [get]
 func (h *Handler) GetTemperature(c *fiber.Ctx) error {
 	groupName := c.Params("groupName")
 
-	temperature, err := h.service.GetTemperature(h.ctx, strings.ToLower(groupName))
+	var temperature *float64
+	var err error
+
+	if queryBool(c, "excludeOffline") {
+		temperature, err = h.service.GetOnlineTemperature(h.ctx, strings.ToLower(groupName))
+	} else {
+		temperature, err = h.service.GetTemperature(h.ctx, strings.ToLower(groupName))
+	}
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(groupAverageErrorStatus(err)).JSON(fiber.Map{
 			"error": true,
 			"msg":   err.Error(),
 		})
 	}
 
+	if temperature == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": true,
+			"msg":   "no temperature data for the group",
+		})
+	}
+
 	return c.JSON(fiber.Map{
 		"error":         false,
 		"msg":           nil,
@@ -119,7 +160,7 @@ func (h *Handler) GetCurrentSpecies(c *fiber.Ctx) error {
 // @Param groupName path string true "Name of the sensor group"
 // @Param top query integer true "Number of top species to retrieve"
 // @Param from query string false "Start date for the period (UNIX timestamp)"
-// @Param till query string false "End date for the period (UNIX timestamp)"
+// @Param till query string false "End date for the period (UNIX timestamp), now by default"
 // @Success 200 {array} domain.ResponseDetectedFish
 // @Failure 422 {string} string
 // @Failure 500 {string} string
@@ -137,15 +178,7 @@ func (h *Handler) GetCurrentTopSpecies(c *fiber.Ctx) error {
 		})
 	}
 
-	start := c.Query("from")
-	if start != "" {
-		start = utils.ParseUnixToString(start)
-	}
-
-	end := c.Query("till")
-	if end != "" {
-		end = utils.ParseUnixToString(end)
-	}
+	start, end := h.parsePeriod(c)
 
 	species, err := h.service.GetCurrentTopSpecies(h.ctx, strings.ToLower(groupName), start, end, top)
 	if err != nil {
@@ -179,42 +212,28 @@ func (h *Handler) GetCurrentTopSpecies(c *fiber.Ctx) error {
 	})
 }
 
-// GeRegionMinTemperature retrieves the current minimum temperature according to region.
+// GetAverageSensorTemperature retrieves the current top detected fish species for a sensor group.
 //
-// @Summary Get current minimum temperature according to region.
-// @Description Retrieves the current minimum temperature with optional parameters.
-// @Tags region
+// @Summary Get average temperature from sensor
+// @Description Retrieves the average temperature based on the  optional parameters.
+// @Tags sensor
 // @Accept json
 // @Produce json
-// @Param xMin query number true  "minimum X coordinate"
-// @Param xMax query number true	"maximum X coordinate"
-// @Param yMax query number true	"maximum Y coordinate"
-// @Param yMix query number true	"minimum Y coordinate"
-// @Param zMin query number true	"minimum Z coordinate"
-// @Param zMax query number true	"maximum Z coordinate"
+// @Param codename path string true "name of the group and id inside the group"
+// @Param from query string false "Start date for the period (UNIX timestamp)"
+// @Param till query string false "End date for the period (UNIX timestamp), now by default"
 // @Success 200 {number} number
+// @Failure 404 {string} string
 // @Failure 500 {string} string
-// @Router /api/v1/region/temperature/min [get]
-func (h *Handler) GeRegionMinTemperature(c *fiber.Ctx) error {
-	xMin, _ := strconv.ParseFloat(c.Query("xMin"), 64)
-	xMax, _ := strconv.ParseFloat(c.Query("xMax"), 64)
-	yMin, _ := strconv.ParseFloat(c.Query("yMin"), 64)
-	yMax, _ := strconv.ParseFloat(c.Query("yMax"), 64)
-	zMin, _ := strconv.ParseFloat(c.Query("zMin"), 64)
-	zMax, _ := strconv.ParseFloat(c.Query("zMax"), 64)
-
-	region := domain.Region{
-		XMin: xMin,
-		XMax: xMax,
-		YMin: yMin,
-		YMax: yMax,
-		ZMin: zMin,
-		ZMax: zMax,
-	}
+// @Router /api/v1/sensor/{codename}/temperature/average [get]
+func (h *Handler) GetAverageSensorTemperature(c *fiber.Ctx) error {
+	codename := c.Params("codename")
 
-	flag := "MIN"
+	group, inGroupID := utils.ParseCodename(codename)
+
+	start, end := h.parsePeriod(c)
 
-	temperature, err := h.service.GetRegionTemperature(h.ctx, region, flag)
+	temperature, err := h.service.GetSensorTemperature(h.ctx, inGroupID, group, start, end)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": true,
@@ -222,92 +241,42 @@ func (h *Handler) GeRegionMinTemperature(c *fiber.Ctx) error {
 		})
 	}
 
-	return c.JSON(fiber.Map{
-		"error":              false,
-		"msg":                nil,
-		"region temperature": temperature,
-	})
-}
-
-// GeRegionMaxTemperature retrieves the current maximum temperature according to region.
-//
-// @Summary Get current maximum temperature according to region.
-// @Description Retrieves the current maximum temperature with optional parameters.
-// @Tags region
-// @Accept json
-// @Produce json
-// @Param xMin query number true  "minimum X coordinate"
-// @Param xMax query number true	"maximum X coordinate"
-// @Param yMax query number true	"maximum Y coordinate"
-// @Param yMix query number true	"minimum Y coordinate"
-// @Param zMin query number true	"minimum Z coordinate"
-// @Param zMax query number true	"maximum Z coordinate"
-// @Success 200 {number} number
-// @Failure 500 {string} string
-// @Router /api/v1/region/temperature/max [get]
-func (h *Handler) GeRegionMaxTemperature(c *fiber.Ctx) error {
-	xMin, _ := strconv.ParseFloat(c.Query("xMin"), 64)
-	xMax, _ := strconv.ParseFloat(c.Query("xMax"), 64)
-	yMin, _ := strconv.ParseFloat(c.Query("yMin"), 64)
-	yMax, _ := strconv.ParseFloat(c.Query("yMax"), 64)
-	zMin, _ := strconv.ParseFloat(c.Query("zMin"), 64)
-	zMax, _ := strconv.ParseFloat(c.Query("zMax"), 64)
-
-	region := domain.Region{
-		XMin: xMin,
-		XMax: xMax,
-		YMin: yMin,
-		YMax: yMax,
-		ZMin: zMin,
-		ZMax: zMax,
-	}
-
-	flag := "MAX"
-
-	temperature, err := h.service.GetRegionTemperature(h.ctx, region, flag)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+	if temperature == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": true,
-			"msg":   err.Error(),
+			"msg":   fmt.Sprintf("no temperature data from scanner %s, period from %s till %s", codename, start, end),
 		})
 	}
 
 	return c.JSON(fiber.Map{
-		"error":              false,
-		"msg":                nil,
-		"region temperature": temperature,
+		"error":       false,
+		"msg":         fmt.Sprintf("temperature from scanner %s, period from %s till %s", codename, start, end),
+		"temperature": math.Round(*temperature*1000) / 1000,
 	})
 }
 
-// GetAverageSensorTemperature retrieves the current top detected fish species for a sensor group.
+// GetAverageSensorTransparency retrieves the average transparency detected by a sensor for the period.
 //
-// @Summary Get average temperature from sensor
-// @Description Retrieves the average temperature based on the  optional parameters.
+// @Summary Get average transparency from sensor
+// @Description Retrieves the average transparency based on the optional parameters.
 // @Tags sensor
 // @Accept json
 // @Produce json
 // @Param codename path string true "name of the group and id inside the group"
 // @Param from query string false "Start date for the period (UNIX timestamp)"
-// @Param till query string false "End date for the period (UNIX timestamp)"
+// @Param till query string false "End date for the period (UNIX timestamp), now by default"
 // @Success 200 {number} number
+// @Failure 404 {string} string
 // @Failure 500 {string} string
-// @Router /api/v1/sensor/{codename}/temperature/average [get]
-func (h *Handler) GetAverageSensorTemperature(c *fiber.Ctx) error {
+// @Router /api/v1/sensor/{codename}/transparency/average [get]
+func (h *Handler) GetAverageSensorTransparency(c *fiber.Ctx) error {
 	codename := c.Params("codename")
 
 	group, inGroupID := utils.ParseCodename(codename)
 
-	start := c.Query("from")
-	if start != "" {
-		start = utils.ParseUnixToString(start)
-	}
-
-	end := c.Query("till")
-	if end != "" {
-		end = utils.ParseUnixToString(end)
-	}
+	start, end := h.parsePeriod(c)
 
-	temperature, err := h.service.GetSensorTemperature(h.ctx, inGroupID, group, start, end)
+	transparency, err := h.service.GetSensorTransparency(h.ctx, inGroupID, group, start, end)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": true,
@@ -315,9 +284,16 @@ func (h *Handler) GetAverageSensorTemperature(c *fiber.Ctx) error {
 		})
 	}
 
+	if transparency == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": true,
+			"msg":   fmt.Sprintf("no transparency data from scanner %s, period from %s till %s", codename, start, end),
+		})
+	}
+
 	return c.JSON(fiber.Map{
-		"error":       false,
-		"msg":         fmt.Sprintf("temperature from scanner %s, period from %s till %s", codename, start, end),
-		"temperature": math.Round(*temperature*1000) / 1000,
+		"error":          false,
+		"msg":            fmt.Sprintf("transparency from scanner %s, period from %s till %s", codename, start, end),
+		"transparency %": math.Round(*transparency*1000) / 1000,
 	})
 }
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/internal/service"
+	"github.com/PavelDonchenko/sensor-go/internal/storage"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetSensorSeries retrieves the bucketed time series of a metric reported by a sensor.
+//
+// @Summary Get a time series from sensor
+// @Description Retrieves the metric values reported by a sensor aggregated into buckets, the start of the period is rounded down to the bucket start.
+// @Tags sensor
+// @Accept json
+// @Produce json
+// @Param codename path string true "name of the group and id inside the group"
+// @Param metric query string true "temperature or transparency"
+// @Param from query string true "Start date for the period (UNIX timestamp)"
+// @Param till query string true "End date for the period (UNIX timestamp)"
+// @Param bucket query string false "1m, 1h or 1d, 1h by default"
+// @Param agg query string false "avg, min, max or p95, avg by default"
+// @Success 200 {array} domain.SeriesPoint
+// @Failure 404 {string} string
+// @Failure 422 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/sensor/{codename}/series [get]
+func (h *Handler) GetSensorSeries(c *fiber.Ctx) error {
+	codename := c.Params("codename")
+
+	query, err := parseSeriesQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	series, err := h.service.GetSensorSeries(h.ctx, codename, query)
+	if err != nil {
+		return c.Status(seriesErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":  false,
+		"msg":    nil,
+		"series": series,
+	})
+}
+
+// GetGroupSeries retrieves the bucketed time series of a metric reported by the sensors of a group.
+//
+// @Summary Get a time series for a sensor group
+// @Description Retrieves the metric values reported by all sensors of a group aggregated into buckets, the start of the period is rounded down to the bucket start.
+// @Tags group
+// @Accept json
+// @Produce json
+// @Param groupName path string true "Name of the sensor group"
+// @Param metric query string true "temperature or transparency"
+// @Param from query string true "Start date for the period (UNIX timestamp)"
+// @Param till query string true "End date for the period (UNIX timestamp)"
+// @Param bucket query string false "1m, 1h or 1d, 1h by default"
+// @Param agg query string false "avg, min, max or p95, avg by default"
+// @Success 200 {array} domain.SeriesPoint
+// @Failure 404 {string} string
+// @Failure 422 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/group/{groupName}/series [get]
+func (h *Handler) GetGroupSeries(c *fiber.Ctx) error {
+	groupName := c.Params("groupName")
+
+	query, err := parseSeriesQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	series, err := h.service.GetGroupSeries(h.ctx, strings.ToLower(groupName), query)
+	if err != nil {
+		return c.Status(seriesErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":  false,
+		"msg":    nil,
+		"series": series,
+	})
+}
+
+func parseSeriesQuery(c *fiber.Ctx) (domain.SeriesQuery, error) {
+	query := domain.SeriesQuery{
+		Metric:      domain.Metric(strings.ToLower(c.Query("metric"))),
+		Bucket:      domain.Bucket(c.Query("bucket", string(domain.BucketHour))),
+		Aggregation: domain.Aggregation(strings.ToLower(c.Query("agg", string(domain.AggregationAvg)))),
+	}
+
+	var err error
+
+	query.From, err = parseUnixQuery(c, "from")
+	if err != nil {
+		return query, err
+	}
+
+	query.Till, err = parseUnixQuery(c, "till")
+	if err != nil {
+		return query, err
+	}
+
+	return query, nil
+}
+
+// parseUnixQuery parses an optional UNIX timestamp query parameter, the zero time is returned if it is missing.
+func parseUnixQuery(c *fiber.Ctx, key string) (time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	unix, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, errors.New(key + " must be a UNIX timestamp")
+	}
+
+	return time.Unix(unix, 0).UTC(), nil
+}
+
+func seriesErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, storage.ErrSensorNotFound), errors.Is(err, service.ErrorWrongGroupName):
+		return fiber.StatusNotFound
+	case errors.Is(err, service.ErrorWrongMetric), errors.Is(err, service.ErrorWrongBucket),
+		errors.Is(err, service.ErrorWrongAggregation), errors.Is(err, service.ErrorWrongPeriod),
+		errors.Is(err, service.ErrorTooManyPoints):
+		return fiber.StatusUnprocessableEntity
+	}
+
+	return fiber.StatusInternalServerError
+}
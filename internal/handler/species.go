@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetSpeciesCatalogue retrieves the species catalogue.
+//
+// @Summary Get the species catalogue
+// @Description Retrieves the fish species the sensors detect with their scientific names, habitats, preferred depth (meters) and temperature (Celsius) ranges, schooling behaviour and rarity.
+// @Tags species
+// @Accept json
+// @Produce json
+// @Param habitat query string false "only the species of the habitat, e.g. reef"
+// @Success 200 {array} domain.Species
+// @Router /api/v1/species [get]
+func (h *Handler) GetSpeciesCatalogue(c *fiber.Ctx) error {
+	species := h.service.GetSpeciesCatalogue(c.Query("habitat"))
+
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"msg":     nil,
+		"species": species,
+	})
+}
@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/internal/stream"
+	"github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// streamMetrics are the reading values a stream can be narrowed down to.
+var streamMetrics = []string{string(domain.MetricTemperature), string(domain.MetricTransparency), "species"}
+
+var upgrader = websocket.FastHTTPUpgrader{
+	CheckOrigin: func(*fasthttp.RequestCtx) bool {
+		return true
+	},
+}
+
+// Stream pushes the new sensor readings to the client.
+//
+// @Summary Stream live sensor readings
+// @Description Pushes every new reading, generated or ingested, over a WebSocket if the request is a WebSocket upgrade, or as Server-Sent Events otherwise. The readings can be filtered by group, sensor codename and region and narrowed down to some metrics. Readings are dropped for a client which does not keep up, the number of dropped readings is reported periodically.
+// @Tags stream
+// @Produce json
+// @Param group query string false "Name of the sensor group"
+// @Param codename query string false "name of the group and id inside the group"
+// @Param region query string false "name of a persisted region"
+// @Param metric query string false "comma separated temperature, transparency and species, all of them by default"
+// @Success 101 {object} domain.Reading
+// @Success 200 {object} domain.Reading
+// @Failure 404 {string} string
+// @Failure 422 {string} string
+// @Router /api/v1/stream [get]
+func (h *Handler) Stream(c *fiber.Ctx) error {
+	filter, metrics, err := h.parseStreamFilter(c)
+	if err != nil {
+		return c.Status(regionErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	if websocket.FastHTTPIsWebSocketUpgrade(c.Context()) {
+		// the upgrader writes the error response itself if the handshake fails
+		_ = upgrader.Upgrade(c.Context(), func(conn *websocket.Conn) {
+			h.streamWebSocket(conn, filter, metrics)
+		})
+		return nil
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	sub := h.service.SubscribeReadings(filter)
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer h.service.UnsubscribeReadings(sub)
+		h.streamEvents(w, sub, metrics)
+	})
+
+	return nil
+}
+
+func (h *Handler) streamEvents(w *bufio.Writer, sub *stream.Subscription, metrics map[string]bool) {
+	heartbeat := time.NewTicker(h.cfg.Stream.Heartbeat)
+	defer heartbeat.Stop()
+
+	var dropped uint64
+
+	for {
+		select {
+		case <-h.streams.Done():
+			return
+		case reading, ok := <-sub.C():
+			if !ok {
+				return
+			}
+
+			data, err := json.Marshal(readingPayload(reading, metrics))
+			if err != nil {
+				return
+			}
+
+			fmt.Fprintf(w, "event: reading\ndata: %s\n\n", data)
+		case <-heartbeat.C:
+			if n := sub.Dropped(); n != dropped {
+				dropped = n
+				fmt.Fprintf(w, "event: dropped\ndata: {\"dropped\": %d}\n\n", dropped)
+			} else {
+				// a comment keeps the connection alive and detects the gone clients
+				fmt.Fprint(w, ": heartbeat\n\n")
+			}
+		}
+
+		// the client is gone if the flush fails
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func (h *Handler) streamWebSocket(conn *websocket.Conn, filter stream.Filter, metrics map[string]bool) {
+	sub := h.service.SubscribeReadings(filter)
+	defer h.service.UnsubscribeReadings(sub)
+
+	// the client does not send anything, reading only detects the closed connection and answers the control frames
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(h.cfg.Stream.Heartbeat)
+	defer heartbeat.Stop()
+
+	var dropped uint64
+	var err error
+
+	for {
+		select {
+		case <-h.streams.Done():
+			return
+		case <-closed:
+			return
+		case reading, ok := <-sub.C():
+			if !ok {
+				return
+			}
+
+			err = conn.WriteJSON(readingPayload(reading, metrics))
+		case <-heartbeat.C:
+			if n := sub.Dropped(); n != dropped {
+				dropped = n
+				err = conn.WriteJSON(fiber.Map{"dropped": dropped})
+			} else {
+				err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(h.cfg.Stream.Heartbeat))
+			}
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (h *Handler) parseStreamFilter(c *fiber.Ctx) (stream.Filter, map[string]bool, error) {
+	filter := stream.Filter{
+		GroupName: strings.ToLower(c.Query("group")),
+		Codename:  strings.ToLower(c.Query("codename")),
+	}
+
+	if c.Query("region") != "" || c.Query("radius") != "" || c.Query("xMin") != "" {
+		area, err := h.parseArea(c)
+		if err != nil {
+			return filter, nil, err
+		}
+
+		filter.Area = &area
+	}
+
+	metrics := make(map[string]bool)
+
+	for _, metric := range strings.Split(strings.ToLower(c.Query("metric")), ",") {
+		metric = strings.TrimSpace(metric)
+		if metric == "" {
+			continue
+		}
+
+		known := false
+		for _, m := range streamMetrics {
+			known = known || m == metric
+		}
+
+		if !known {
+			return filter, nil, queryError("metric must be a comma separated list of " + strings.Join(streamMetrics, ", "))
+		}
+
+		metrics[metric] = true
+	}
+
+	if len(metrics) == 0 {
+		for _, metric := range streamMetrics {
+			metrics[metric] = true
+		}
+	}
+
+	return filter, metrics, nil
+}
+
+// readingPayload keeps only the requested metrics of the reading.
+func readingPayload(reading domain.Reading, metrics map[string]bool) fiber.Map {
+	payload := fiber.Map{
+		"sensor_id":   reading.SensorID,
+		"codename":    reading.Codename,
+		"group_name":  reading.GroupName,
+		"coordinates": reading.Coordinates,
+		"created_at":  reading.CreatedAt,
+	}
+
+	if metrics[string(domain.MetricTemperature)] {
+		payload["temperature"] = reading.ValidTemperature()
+	}
+
+	if metrics[string(domain.MetricTransparency)] {
+		payload["transparency"] = reading.Transparency
+	}
+
+	if metrics["species"] {
+		payload["detected_fish"] = reading.DetectedFish
+	}
+
+	return payload
+}
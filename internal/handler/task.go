@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetTasks retrieves the states of the supervised workers.
+//
+// @Summary Get supervised tasks
+// @Description Retrieves the long-lived tasks of the service in the order they were started: the scheduler and the writers of the simulated readings and the other workers. A task is running, restarting after a failure, stopped or failed for good, with the number of its restarts and its latest failure.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} object{error=bool,msg=string,tasks=[]domain.TaskStatus}
+// @Failure 401 {string} string
+// @Router /api/v1/admin/tasks [get]
+func (h *Handler) GetTasks(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"error": false,
+		"msg":   nil,
+		"tasks": h.service.GetTasks(),
+	})
+}
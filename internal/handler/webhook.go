@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/internal/service"
+	"github.com/PavelDonchenko/sensor-go/internal/storage"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateWebhook subscribes a webhook to the service events.
+//
+// @Summary Create a webhook
+// @Description Subscribes the url to the events listed in events, every event type if it is empty: reading.created, alert.firing, alert.resolved, sensor.failed, storage.error. Events are POSTed as JSON with the X-Webhook-Signature header, the HMAC-SHA256 of the body keyed with the secret, failed deliveries are retried with an exponential backoff. The secret is not returned.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param webhook body domain.CreateWebhook true "webhook to create"
+// @Success 201 {object} domain.Webhook
+// @Failure 401 {string} string
+// @Failure 422 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/webhooks [post]
+func (h *Handler) CreateWebhook(c *fiber.Ctx) error {
+	var req domain.CreateWebhook
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	webhook, err := h.service.CreateWebhook(h.ctx, req)
+	if err != nil {
+		return c.Status(webhookErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"error":   false,
+		"msg":     nil,
+		"webhook": webhook,
+	})
+}
+
+// GetWebhooks retrieves all webhooks.
+//
+// @Summary Get webhooks
+// @Description Retrieves all webhooks, the secrets are not returned.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} domain.Webhook
+// @Failure 401 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/webhooks [get]
+func (h *Handler) GetWebhooks(c *fiber.Ctx) error {
+	webhooks, err := h.service.GetWebhooks(h.ctx)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":    false,
+		"msg":      nil,
+		"webhooks": webhooks,
+	})
+}
+
+// DeleteWebhook deletes a webhook together with its delivery log.
+//
+// @Summary Delete a webhook
+// @Description Deletes a webhook together with its delivery log and dead letters.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "id of the webhook"
+// @Success 200 {string} string
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/webhooks/{id} [delete]
+func (h *Handler) DeleteWebhook(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": true,
+			"msg":   storage.ErrWebhookNotFound.Error(),
+		})
+	}
+
+	err = h.service.DeleteWebhook(h.ctx, id)
+	if err != nil {
+		return c.Status(webhookErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"msg":   nil,
+	})
+}
+
+// GetWebhookDeliveries retrieves the delivery log of a webhook.
+//
+// @Summary Get webhook deliveries
+// @Description Retrieves the delivery attempts of a webhook with their response status or error, the latest first.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "id of the webhook"
+// @Param limit query int false "maximum number of deliveries, 100 by default"
+// @Success 200 {array} domain.WebhookDelivery
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Failure 422 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/webhooks/{id}/deliveries [get]
+func (h *Handler) GetWebhookDeliveries(c *fiber.Ctx) error {
+	id, limit, err := parseWebhookLog(c)
+	if err != nil {
+		return c.Status(webhookErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	deliveries, err := h.service.GetWebhookDeliveries(h.ctx, id, limit)
+	if err != nil {
+		return c.Status(webhookErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":      false,
+		"msg":        nil,
+		"deliveries": deliveries,
+	})
+}
+
+// GetDeadLetters retrieves the events which could not be delivered to a webhook.
+//
+// @Summary Get webhook dead letters
+// @Description Retrieves the events which failed every delivery attempt or did not fit into the delivery queue, with their payload, the latest first.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "id of the webhook"
+// @Param limit query int false "maximum number of dead letters, 100 by default"
+// @Success 200 {array} domain.DeadLetter
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Failure 422 {string} string
+// @Failure 500 {string} string
+// @Router /api/v1/webhooks/{id}/dead-letters [get]
+func (h *Handler) GetDeadLetters(c *fiber.Ctx) error {
+	id, limit, err := parseWebhookLog(c)
+	if err != nil {
+		return c.Status(webhookErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	letters, err := h.service.GetDeadLetters(h.ctx, id, limit)
+	if err != nil {
+		return c.Status(webhookErrorStatus(err)).JSON(fiber.Map{
+			"error": true,
+			"msg":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":        false,
+		"msg":          nil,
+		"dead_letters": letters,
+	})
+}
+
+func parseWebhookLog(c *fiber.Ctx) (int, int, error) {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return 0, 0, storage.ErrWebhookNotFound
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit", "100"))
+	if err != nil {
+		return 0, 0, service.ErrorWrongWebhookLimit
+	}
+
+	return id, limit, nil
+}
+
+func webhookErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, storage.ErrWebhookNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, service.ErrorWrongWebhookURL), errors.Is(err, service.ErrorEmptySecret),
+		errors.Is(err, service.ErrorWrongEventType), errors.Is(err, service.ErrorWrongWebhookLimit):
+		return fiber.StatusUnprocessableEntity
+	}
+
+	return fiber.StatusInternalServerError
+}
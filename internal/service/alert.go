@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+)
+
+var (
+	ErrorEmptyRuleName        = errors.New("alert rule name must not be empty")
+	ErrorWrongRuleMetric      = errors.New("alert rule metric must be temperature, transparency or species")
+	ErrorMissingRuleSpecies   = errors.New("species alert rule must name the species")
+	ErrorWrongRuleOperator    = errors.New("alert rule operator must be >, >=, < or <=")
+	ErrorWrongRuleAggregation = errors.New("alert rule aggregation must be empty, avg, min or max")
+	ErrorWrongRuleScope       = errors.New("alert rule may be scoped to only one of a group, a sensor or a region")
+	ErrorWrongConsecutive     = errors.New("consecutive readings must not be negative")
+	ErrorWrongAlertState      = errors.New("alert state must be firing or resolved")
+	ErrorWrongAlertLimit      = errors.New("alert limit must be between 1 and 1000")
+	ErrorWrongAlertPeriod     = errors.New("till must be after from")
+)
+
+const maxAlertLimit = 1000
+
+func (s *Service) CreateAlertRule(ctx context.Context, rule domain.CreateAlertRule) (*domain.AlertRule, error) {
+	created := domain.AlertRule{
+		Name:        strings.TrimSpace(rule.Name),
+		Metric:      domain.Metric(strings.ToLower(string(rule.Metric))),
+		Species:     strings.TrimSpace(rule.Species),
+		GroupName:   strings.ToLower(rule.GroupName),
+		Codename:    strings.ToLower(rule.Codename),
+		Region:      strings.ToLower(rule.Region),
+		Aggregation: domain.Aggregation(strings.ToLower(string(rule.Aggregation))),
+		Operator:    rule.Operator,
+		Threshold:   rule.Threshold,
+		Consecutive: rule.Consecutive,
+	}
+
+	if err := validateAlertRule(created); err != nil {
+		return nil, err
+	}
+
+	if created.Consecutive == 0 {
+		created.Consecutive = 1
+	}
+
+	switch {
+	case created.GroupName != "":
+		if err := s.validateGroupName(ctx, created.GroupName); err != nil {
+			return nil, err
+		}
+	case created.Codename != "":
+		sensor, err := s.GetSensor(ctx, created.Codename)
+		if err != nil {
+			return nil, err
+		}
+		created.SensorID = &sensor.ID
+	case created.Region != "":
+		if _, err := s.db.GetRegion(ctx, created.Region); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.db.CreateAlertRule(ctx, created)
+}
+
+func (s *Service) GetAlertRules(ctx context.Context) ([]domain.AlertRule, error) {
+	return s.db.GetAlertRules(ctx)
+}
+
+func (s *Service) DeleteAlertRule(ctx context.Context, id int) error {
+	return s.db.DeleteAlertRule(ctx, id)
+}
+
+// GetAlerts returns the alert history, the latest fired alerts first.
+func (s *Service) GetAlerts(ctx context.Context, query domain.AlertQuery) ([]domain.Alert, error) {
+	if query.State != "" && query.State != domain.AlertFiring && query.State != domain.AlertResolved {
+		return nil, ErrorWrongAlertState
+	}
+
+	if query.Limit <= 0 || query.Limit > maxAlertLimit {
+		return nil, ErrorWrongAlertLimit
+	}
+
+	if !query.From.IsZero() && !query.Till.IsZero() && !query.Till.After(query.From) {
+		return nil, ErrorWrongAlertPeriod
+	}
+
+	return s.db.GetAlerts(ctx, query)
+}
+
+func validateAlertRule(rule domain.AlertRule) error {
+	if rule.Name == "" {
+		return ErrorEmptyRuleName
+	}
+
+	if !rule.Metric.Valid() && rule.Metric != domain.MetricSpecies {
+		return ErrorWrongRuleMetric
+	}
+
+	if rule.Metric == domain.MetricSpecies && rule.Species == "" {
+		return ErrorMissingRuleSpecies
+	}
+
+	if !rule.Operator.Valid() {
+		return ErrorWrongRuleOperator
+	}
+
+	switch rule.Aggregation {
+	case "", domain.AggregationAvg, domain.AggregationMin, domain.AggregationMax:
+	default:
+		return ErrorWrongRuleAggregation
+	}
+
+	scopes := 0
+	for _, scope := range []string{rule.GroupName, rule.Codename, rule.Region} {
+		if scope != "" {
+			scopes++
+		}
+	}
+
+	if scopes > 1 {
+		return ErrorWrongRuleScope
+	}
+
+	if rule.Consecutive < 0 {
+		return ErrorWrongConsecutive
+	}
+
+	return nil
+}
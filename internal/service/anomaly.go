@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+)
+
+var (
+	ErrorWrongAnomalyLimit  = errors.New("anomaly limit must be between 1 and 1000")
+	ErrorWrongAnomalyPeriod = errors.New("till must be after from")
+)
+
+const maxAnomalyLimit = 1000
+
+// AnomalyScorer tags the measurements with their anomaly score before they are saved and flags the anomalous ones once they are.
+type AnomalyScorer interface {
+	Score(measurement *domain.Measurement) []domain.Anomaly
+	Flag(reading domain.Reading, anomalies []domain.Anomaly)
+}
+
+// GetAnomalies returns the flagged readings, optionally of a group and of a period, the latest first.
+func (s *Service) GetAnomalies(ctx context.Context, query domain.AnomalyQuery) ([]domain.Anomaly, error) {
+	if query.Limit <= 0 || query.Limit > maxAnomalyLimit {
+		return nil, ErrorWrongAnomalyLimit
+	}
+
+	if !query.From.IsZero() && !query.Till.IsZero() && !query.Till.After(query.From) {
+		return nil, ErrorWrongAnomalyPeriod
+	}
+
+	if query.GroupName != "" {
+		if err := s.validateGroupName(ctx, query.GroupName); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.db.GetAnomalies(ctx, query)
+}
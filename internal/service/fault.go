@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/pkg/generations"
+)
+
+// FaultInjector injects the faults into the simulated readings, its rules may be changed while the simulation runs.
+type FaultInjector interface {
+	Rules() []domain.FaultRule
+	AddRule(rule domain.FaultRule) domain.FaultRule
+	SetEnabled(id int, enabled bool) (domain.FaultRule, error)
+	DeleteRule(id int) error
+	Active() []domain.ActiveFault
+}
+
+func (s *Service) GetFaultRules() []domain.FaultRule {
+	return s.faults.Rules()
+}
+
+func (s *Service) CreateFaultRule(ctx context.Context, rule domain.CreateFaultRule) (*domain.FaultRule, error) {
+	created := domain.FaultRule{
+		Mode:        domain.FaultMode(strings.ToLower(string(rule.Mode))),
+		GroupName:   strings.ToLower(rule.GroupName),
+		Codename:    strings.ToLower(rule.Codename),
+		Probability: rule.Probability,
+		Duration:    rule.Duration,
+		Magnitude:   rule.Magnitude,
+		Enabled:     rule.Enabled == nil || *rule.Enabled,
+	}
+
+	if err := generations.ValidateFaultRule(created); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case created.GroupName != "":
+		if err := s.validateGroupName(ctx, created.GroupName); err != nil {
+			return nil, err
+		}
+	case created.Codename != "":
+		sensor, err := s.GetSensor(ctx, created.Codename)
+		if err != nil {
+			return nil, err
+		}
+		created.Codename = sensor.Codename.String()
+	}
+
+	created = s.faults.AddRule(created)
+
+	return &created, nil
+}
+
+// UpdateFaultRule enables or disables the rule, disabling it ends the faults it injects at once.
+func (s *Service) UpdateFaultRule(id int, rule domain.UpdateFaultRule) (*domain.FaultRule, error) {
+	updated, err := s.faults.SetEnabled(id, rule.Enabled)
+	if err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+func (s *Service) DeleteFaultRule(id int) error {
+	return s.faults.DeleteRule(id)
+}
+
+// GetActiveFaults returns the drift, stuck and outage faults the sensors suffer from.
+func (s *Service) GetActiveFaults() []domain.ActiveFault {
+	return s.faults.Active()
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+)
+
+var (
+	ErrorEmptyBatch         = errors.New("at least one measurement is required")
+	ErrorBatchTooLarge      = errors.New("too many measurements in the batch")
+	ErrorMissingTemperature = errors.New("temperature is required")
+	ErrorWrongTemperature   = errors.New("temperature must be a finite number")
+	ErrorWrongTransparency  = errors.New("transparency is required and must be between 0 and 100")
+	ErrorWrongFishName      = errors.New("fish name is required")
+	ErrorWrongFishCount     = errors.New("fish count must not be negative")
+	ErrorFutureTimestamp    = errors.New("timestamp is in the future")
+	ErrorOldTimestamp       = errors.New("timestamp is too old")
+)
+
+// IngestMeasurements validates the measurements reported by a sensor and saves them in one transaction,
+// through the same storage path the simulated data takes.
+func (s *Service) IngestMeasurements(ctx context.Context, codename string, measurements []domain.IngestMeasurement) ([]domain.Measurement, error) {
+	if len(measurements) == 0 {
+		return nil, ErrorEmptyBatch
+	}
+
+	if len(measurements) > s.cfg.Ingestion.MaxBatchSize {
+		return nil, fmt.Errorf("%w, maximum is %d", ErrorBatchTooLarge, s.cfg.Ingestion.MaxBatchSize)
+	}
+
+	sensor, err := s.GetSensor(ctx, codename)
+	if err != nil {
+		return nil, err
+	}
+
+	toSave := make([]domain.Measurement, 0, len(measurements))
+
+	// the timestamps are checked against the server clock, the one the measurements without a timestamp get
+	now := time.Now()
+
+	for i, measurement := range measurements {
+		err = validateMeasurement(measurement)
+		if err != nil {
+			return nil, fmt.Errorf("measurement %d: %w", i, err)
+		}
+
+		err = s.validateTimestamp(measurement.Timestamp, now)
+		if err != nil {
+			return nil, fmt.Errorf("measurement %d: %w", i, err)
+		}
+
+		m := domain.Measurement{
+			SensorID:     sensor.ID,
+			Temperature:  *measurement.Temperature,
+			Transparency: *measurement.Transparency,
+		}
+
+		if measurement.Timestamp != nil {
+			m.CreatedAt = time.Unix(*measurement.Timestamp, 0).UTC()
+		}
+
+		for _, fish := range measurement.DetectedFish {
+			m.DetectedFish = append(m.DetectedFish, domain.DetectedFish{
+				SensorID: sensor.ID,
+				Name:     strings.TrimSpace(fish.Name),
+				Count:    fish.Count,
+			})
+		}
+
+		toSave = append(toSave, m)
+	}
+
+	anomalies := make([][]domain.Anomaly, len(toSave))
+	if s.scorer != nil {
+		for i := range toSave {
+			anomalies[i] = s.scorer.Score(&toSave[i])
+		}
+	}
+
+	saved, err := s.db.SaveMeasurements(ctx, toSave)
+	if err != nil {
+		s.hub.PublishEvent(domain.NewEvent(domain.EventStorageError, domain.SensorFailure{
+			SensorID: sensor.ID,
+			Codename: sensor.Codename.String(),
+			Error:    err.Error(),
+		}))
+		return nil, err
+	}
+
+	for i, measurement := range saved {
+		reading := domain.NewReading(*sensor, measurement)
+
+		s.hub.Publish(reading)
+
+		if s.scorer != nil {
+			s.scorer.Flag(reading, anomalies[i])
+		}
+	}
+
+	return saved, nil
+}
+
+func validateMeasurement(measurement domain.IngestMeasurement) error {
+	if measurement.Temperature == nil {
+		return ErrorMissingTemperature
+	}
+
+	if math.IsNaN(*measurement.Temperature) || math.IsInf(*measurement.Temperature, 0) {
+		return ErrorWrongTemperature
+	}
+
+	if measurement.Transparency == nil || *measurement.Transparency < 0 || *measurement.Transparency > 100 {
+		return ErrorWrongTransparency
+	}
+
+	for _, fish := range measurement.DetectedFish {
+		if strings.TrimSpace(fish.Name) == "" {
+			return ErrorWrongFishName
+		}
+
+		if fish.Count < 0 {
+			return fmt.Errorf("%w: %s", ErrorWrongFishCount, fish.Name)
+		}
+	}
+
+	return nil
+}
+
+// validateTimestamp rejects the timestamps beyond the allowed clock skew, they would stop the later measurements
+// from updating the sensor, and the ones older than the allowed age.
+func (s *Service) validateTimestamp(timestamp *int64, now time.Time) error {
+	if timestamp == nil {
+		return nil
+	}
+
+	at := time.Unix(*timestamp, 0)
+
+	if at.After(now.Add(s.cfg.Ingestion.MaxClockSkew)) {
+		return fmt.Errorf("%w, maximum clock skew is %s", ErrorFutureTimestamp, s.cfg.Ingestion.MaxClockSkew)
+	}
+
+	if at.Before(now.Add(-s.cfg.Ingestion.MaxAge)) {
+		return fmt.Errorf("%w, maximum age is %s", ErrorOldTimestamp, s.cfg.Ingestion.MaxAge)
+	}
+
+	return nil
+}
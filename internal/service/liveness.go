@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrorWrongLivenessStatus = errors.New("status must be online, late or offline")
+	ErrorNoOnlineSensors     = errors.New("no online sensors in the group")
+)
+
+// SensorLiveness knows whether the sensors report their data at the expected cadence.
+type SensorLiveness interface {
+	SensorStatuses() []domain.SensorStatus
+	SensorStatus(id uuid.UUID) domain.LivenessStatus
+}
+
+// GetSensorStatuses returns the liveness of the sensors, optionally only of a group or only the ones with the status.
+func (s *Service) GetSensorStatuses(ctx context.Context, groupName string, status domain.LivenessStatus) ([]domain.SensorStatus, error) {
+	if status != "" && !status.Valid() {
+		return nil, ErrorWrongLivenessStatus
+	}
+
+	if groupName != "" {
+		if err := s.validateGroupName(ctx, groupName); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := make([]domain.SensorStatus, 0)
+
+	for _, sensor := range s.liveness.SensorStatuses() {
+		if groupName != "" && sensor.GroupName != groupName {
+			continue
+		}
+
+		if status != "" && sensor.Status != status {
+			continue
+		}
+
+		statuses = append(statuses, sensor)
+	}
+
+	return statuses, nil
+}
+
+// GetOnlineTemperature returns the current average temperature of the group sensors which are not offline.
+func (s *Service) GetOnlineTemperature(ctx context.Context, groupName string) (*float64, error) {
+	return s.getOnlineAverage(ctx, groupName, domain.MetricTemperature)
+}
+
+// GetOnlineTransparency returns the current average transparency of the group sensors which are not offline.
+func (s *Service) GetOnlineTransparency(ctx context.Context, groupName string) (*float64, error) {
+	return s.getOnlineAverage(ctx, groupName, domain.MetricTransparency)
+}
+
+func (s *Service) getOnlineAverage(ctx context.Context, groupName string, metric domain.Metric) (*float64, error) {
+	if err := s.validateGroupName(ctx, groupName); err != nil {
+		return nil, err
+	}
+
+	sensors, err := s.db.GetGroupSensors(ctx, groupName)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]float64, 0, len(sensors))
+
+	for _, sensor := range sensors {
+		if s.liveness.SensorStatus(sensor.ID) == domain.StatusOffline {
+			continue
+		}
+
+		if metric == domain.MetricTemperature {
+			values = append(values, sensor.Temperature)
+		} else {
+			values = append(values, float64(sensor.Transparency))
+		}
+	}
+
+	if len(values) == 0 {
+		return nil, ErrorNoOnlineSensors
+	}
+
+	average := domain.AggregationAvg.Apply(values, 0)
+
+	return &average, nil
+}
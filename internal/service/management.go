@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/pkg/generations"
+	"github.com/PavelDonchenko/sensor-go/pkg/utils"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrorWrongDataOutputRate = errors.New("data output rate must be a positive number of seconds")
+	ErrorInvalidGroupName    = errors.New("group name must consist of lowercase letters only")
+	ErrorWrongSensorSource   = errors.New("sensor source must be simulated or live")
+)
+
+var groupNameRegexp = regexp.MustCompile(`^[a-z]+$`)
+
+// SensorObserver is notified about the sensors changed through the API, so the data generation follows them live.
+type SensorObserver interface {
+	SensorCreated(sensor domain.Sensor)
+	SensorUpdated(sensor domain.Sensor)
+	SensorDeleted(id uuid.UUID)
+}
+
+func (s *Service) CreateGroup(ctx context.Context, group domain.CreateGroup) (*domain.SensorGroup, error) {
+	name := strings.ToLower(strings.TrimSpace(group.Name))
+
+	// codenames are the group name followed by the index, so a group name can't contain digits
+	if !groupNameRegexp.MatchString(name) {
+		return nil, ErrorInvalidGroupName
+	}
+
+	return s.db.CreateGroup(ctx, name)
+}
+
+func (s *Service) GetGroups(ctx context.Context) ([]domain.SensorGroup, error) {
+	groups, err := s.db.GetGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range groups {
+		groups[i].Sensors, err = s.db.GetGroupSensors(ctx, groups[i].Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return groups, nil
+}
+
+func (s *Service) GetGroup(ctx context.Context, name string) (*domain.SensorGroup, error) {
+	group, err := s.db.GetGroup(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	group.Sensors, err = s.db.GetGroupSensors(ctx, group.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+func (s *Service) UpdateGroup(ctx context.Context, name string, group domain.UpdateGroup) (*domain.SensorGroup, error) {
+	newName := strings.ToLower(strings.TrimSpace(group.Name))
+
+	if !groupNameRegexp.MatchString(newName) {
+		return nil, ErrorInvalidGroupName
+	}
+
+	renamed, err := s.db.RenameGroup(ctx, name, newName)
+	if err != nil {
+		return nil, err
+	}
+
+	renamed.Sensors, err = s.db.GetGroupSensors(ctx, renamed.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.observer != nil {
+		for _, sensor := range renamed.Sensors {
+			s.observer.SensorUpdated(sensor)
+		}
+	}
+
+	return renamed, nil
+}
+
+func (s *Service) DeleteGroup(ctx context.Context, name string) error {
+	sensors, err := s.db.GetGroupSensors(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.DeleteGroup(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if s.observer != nil {
+		for _, sensor := range sensors {
+			s.observer.SensorDeleted(sensor.ID)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) CreateSensor(ctx context.Context, sensor domain.CreateSensor) (*domain.Sensor, error) {
+	if sensor.DataOutputRate <= 0 {
+		return nil, ErrorWrongDataOutputRate
+	}
+
+	if sensor.Source == "" {
+		sensor.Source = domain.SourceSimulated
+	}
+
+	if !sensor.Source.Valid() {
+		return nil, ErrorWrongSensorSource
+	}
+
+	// the same sensor registered in a run of the same seed starts from the same data
+	r := generations.NewSeeds(s.cfg.Simulation.Seed).Rand(fmt.Sprintf("%s %v", strings.ToLower(sensor.GroupName), sensor.Coordinates))
+
+	toCreate := domain.Sensor{
+		Source:         sensor.Source,
+		DataOutputRate: sensor.DataOutputRate,
+		Codename:       domain.Codename{Name: strings.ToLower(sensor.GroupName)},
+		Coordinates:    sensor.Coordinates,
+		// the latest data is unknown until the first measurement, start from the simulated one
+		Temperature:  generations.GenerateTemperature(r, s.cfg.TemperatureModel(strings.ToLower(sensor.GroupName)), sensor.Coordinates.Z, s.clock.Now()),
+		Transparency: r.Intn(101),
+	}
+
+	created, err := s.db.CreateSensor(ctx, toCreate)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.observer != nil {
+		s.observer.SensorCreated(*created)
+	}
+
+	return created, nil
+}
+
+func (s *Service) GetSensors(ctx context.Context, groupName string) ([]domain.Sensor, error) {
+	if groupName != "" {
+		return s.db.GetGroupSensors(ctx, groupName)
+	}
+
+	return s.db.GetAllSensors(ctx)
+}
+
+func (s *Service) GetSensor(ctx context.Context, codename string) (*domain.Sensor, error) {
+	group, inGroupID := utils.ParseCodename(strings.ToLower(codename))
+
+	return s.db.GetSensor(ctx, group, inGroupID)
+}
+
+func (s *Service) UpdateSensor(ctx context.Context, codename string, sensor domain.UpdateSensor) (*domain.Sensor, error) {
+	current, err := s.GetSensor(ctx, codename)
+	if err != nil {
+		return nil, err
+	}
+
+	if sensor.GroupName != nil {
+		current.Codename.Name = strings.ToLower(*sensor.GroupName)
+	}
+
+	if sensor.DataOutputRate != nil {
+		if *sensor.DataOutputRate <= 0 {
+			return nil, ErrorWrongDataOutputRate
+		}
+		current.DataOutputRate = *sensor.DataOutputRate
+	}
+
+	if sensor.Coordinates != nil {
+		current.Coordinates = *sensor.Coordinates
+	}
+
+	if sensor.Source != nil {
+		if !sensor.Source.Valid() {
+			return nil, ErrorWrongSensorSource
+		}
+		current.Source = *sensor.Source
+	}
+
+	updated, err := s.db.UpdateSensor(ctx, *current)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.observer != nil {
+		s.observer.SensorUpdated(*updated)
+	}
+
+	return updated, nil
+}
+
+func (s *Service) DeleteSensor(ctx context.Context, codename string) error {
+	sensor, err := s.GetSensor(ctx, codename)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.DeleteSensor(ctx, sensor.ID)
+	if err != nil {
+		return err
+	}
+
+	if s.observer != nil {
+		s.observer.SensorDeleted(sensor.ID)
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+)
+
+var ErrorInvalidRegionName = errors.New("region name must consist of lowercase letters and digits separated by single hyphens")
+
+var regionNameRegexp = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+func (s *Service) CreateRegion(ctx context.Context, region domain.CreateRegion) (*domain.NamedRegion, error) {
+	region.Name = strings.ToLower(strings.TrimSpace(region.Name))
+
+	if !regionNameRegexp.MatchString(region.Name) {
+		return nil, ErrorInvalidRegionName
+	}
+
+	if err := validateArea(domain.Area{Box: region.Box, Sphere: region.Sphere}); err != nil {
+		return nil, err
+	}
+
+	return s.db.CreateRegion(ctx, region)
+}
+
+func (s *Service) GetRegions(ctx context.Context) ([]domain.NamedRegion, error) {
+	return s.db.GetRegions(ctx)
+}
+
+func (s *Service) GetRegion(ctx context.Context, name string) (*domain.NamedRegion, error) {
+	return s.db.GetRegion(ctx, name)
+}
+
+func (s *Service) DeleteRegion(ctx context.Context, name string) error {
+	return s.db.DeleteRegion(ctx, name)
+}
+
+// GetRegionSeries returns the bucketed time series of a metric reported by the sensors inside a named region.
+// Rollups are kept per group and per sensor only, so region series are always computed from the raw data.
+func (s *Service) GetRegionSeries(ctx context.Context, name string, query domain.SeriesQuery) ([]domain.SeriesPoint, error) {
+	if err := validateSeriesQuery(&query); err != nil {
+		return nil, err
+	}
+
+	region, err := s.db.GetRegion(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	sensors, err := s.GetRegionSensors(ctx, region.Area())
+	if err != nil {
+		return nil, err
+	}
+
+	query.SensorIDs = sensorIDs(sensors)
+
+	return s.db.GetSeries(ctx, query)
+}
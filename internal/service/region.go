@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrorWrongRegion     = errors.New("region must be a box with the minimum coordinates not greater than the maximum ones or a sphere with a non-negative radius")
+	ErrorEmptyRegion     = errors.New("there are no sensors in the region")
+	ErrorWrongPercentile = errors.New("percentile must be between 0 and 100")
+	ErrorWrongTop        = errors.New("top must be a positive number")
+	ErrorWrongNearestK   = errors.New("k must be between 1 and 1000")
+)
+
+// maxNearestK limits the number of sensors returned by a nearest neighbour query.
+const maxNearestK = 1000
+
+// GetRegionSensors returns the sensors inside the region box or sphere.
+func (s *Service) GetRegionSensors(ctx context.Context, area domain.Area) ([]domain.Sensor, error) {
+	if err := validateArea(area); err != nil {
+		return nil, err
+	}
+
+	sensors, err := s.db.GetAreaSensors(ctx, area)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sensors) == 0 {
+		return nil, ErrorEmptyRegion
+	}
+
+	return sensors, nil
+}
+
+// GetRegionTemperature aggregates the current temperature of the sensors inside the region,
+// or the temperature they measured during the period if start is provided.
+func (s *Service) GetRegionTemperature(ctx context.Context, area domain.Area, agg domain.Aggregation, percentile float64, start, end string) (*float64, []domain.Sensor, error) {
+	return s.getRegionStat(ctx, area, domain.MetricTemperature, agg, percentile, start, end)
+}
+
+// GetRegionTransparency returns the average current transparency of the sensors inside the region,
+// or the average transparency they measured during the period if start is provided.
+func (s *Service) GetRegionTransparency(ctx context.Context, area domain.Area, start, end string) (*float64, []domain.Sensor, error) {
+	return s.getRegionStat(ctx, area, domain.MetricTransparency, domain.AggregationAvg, 0, start, end)
+}
+
+// GetRegionSpecies returns the species currently detected by the sensors inside the region.
+func (s *Service) GetRegionSpecies(ctx context.Context, area domain.Area) ([]domain.DetectedFish, []domain.Sensor, error) {
+	sensors, err := s.GetRegionSensors(ctx, area)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	species, err := s.db.GetSensorsSpecies(ctx, sensorIDs(sensors))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return species, sensors, nil
+}
+
+// GetRegionTopSpecies returns the top species detected by the sensors inside the region,
+// currently or during the period if start is provided.
+func (s *Service) GetRegionTopSpecies(ctx context.Context, area domain.Area, start, end string, top int) ([]domain.DetectedFish, []domain.Sensor, error) {
+	if top <= 0 {
+		return nil, nil, ErrorWrongTop
+	}
+
+	sensors, err := s.GetRegionSensors(ctx, area)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	species, err := s.db.GetSensorsTopSpecies(ctx, sensorIDs(sensors), start, end, top)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return species, sensors, nil
+}
+
+// GetNearestSensors returns the k sensors closest to the point with their distances, nearest first.
+func (s *Service) GetNearestSensors(ctx context.Context, point domain.Coordinates, k int) ([]domain.SensorDistance, error) {
+	if k < 1 || k > maxNearestK {
+		return nil, ErrorWrongNearestK
+	}
+
+	return s.db.GetNearestSensors(ctx, point, k)
+}
+
+func (s *Service) getRegionStat(ctx context.Context, area domain.Area, metric domain.Metric, agg domain.Aggregation, percentile float64, start, end string) (*float64, []domain.Sensor, error) {
+	if agg == domain.AggregationPercentile && (math.IsNaN(percentile) || percentile < 0 || percentile > 100) {
+		return nil, nil, ErrorWrongPercentile
+	}
+
+	sensors, err := s.GetRegionSensors(ctx, area)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if start != "" {
+		value, err := s.db.GetSensorsStat(ctx, sensorIDs(sensors), metric, agg, percentile, start, end)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return value, sensors, nil
+	}
+
+	values := make([]float64, 0, len(sensors))
+
+	for _, sensor := range sensors {
+		if metric == domain.MetricTransparency {
+			values = append(values, float64(sensor.Transparency))
+		} else {
+			values = append(values, sensor.Temperature)
+		}
+	}
+
+	value := agg.Apply(values, percentile)
+
+	return &value, sensors, nil
+}
+
+func validateArea(area domain.Area) error {
+	switch {
+	case area.Box != nil && area.Sphere != nil:
+		return ErrorWrongRegion
+	case area.Box != nil:
+		if area.Box.XMin > area.Box.XMax || area.Box.YMin > area.Box.YMax || area.Box.ZMin > area.Box.ZMax {
+			return ErrorWrongRegion
+		}
+	case area.Sphere != nil:
+		if area.Sphere.Radius < 0 {
+			return ErrorWrongRegion
+		}
+	default:
+		return ErrorWrongRegion
+	}
+
+	return nil
+}
+
+func sensorIDs(sensors []domain.Sensor) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(sensors))
+
+	for _, sensor := range sensors {
+		ids = append(ids, sensor.ID)
+	}
+
+	return ids
+}
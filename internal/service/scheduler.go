@@ -0,0 +1,26 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+)
+
+var ErrorNoScheduler = errors.New("the sensor data is not simulated")
+
+// Scheduler schedules the simulated readings and knows how far behind their due time they are written.
+type Scheduler interface {
+	SchedulerStats() domain.SchedulerStats
+}
+
+// GetSchedulerStats returns the metrics of the scheduler, the observer generating the simulated data is the scheduler.
+func (s *Service) GetSchedulerStats() (*domain.SchedulerStats, error) {
+	scheduler, ok := s.observer.(Scheduler)
+	if !ok {
+		return nil, ErrorNoScheduler
+	}
+
+	stats := scheduler.SchedulerStats()
+
+	return &stats, nil
+}
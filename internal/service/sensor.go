@@ -5,13 +5,16 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
-	"strings"
+	"time"
 
 	"github.com/PavelDonchenko/sensor-go/config"
 	"github.com/PavelDonchenko/sensor-go/internal/domain"
 	"github.com/PavelDonchenko/sensor-go/internal/storage"
+	"github.com/PavelDonchenko/sensor-go/internal/stream"
 	"github.com/PavelDonchenko/sensor-go/pkg/cache"
+	"github.com/PavelDonchenko/sensor-go/pkg/generations"
 	"github.com/PavelDonchenko/sensor-go/pkg/logging"
+	"github.com/PavelDonchenko/sensor-go/pkg/utils"
 )
 
 var ErrorWrongGroupName error = errors.New("wrong group name")
@@ -19,27 +22,99 @@ var ErrorWrongGroupName error = errors.New("wrong group name")
 type SensorService interface {
 	GetTransparency(ctx context.Context, groupName string) (*float64, error)
 	GetTemperature(ctx context.Context, groupName string) (*float64, error)
+	GetOnlineTransparency(ctx context.Context, groupName string) (*float64, error)
+	GetOnlineTemperature(ctx context.Context, groupName string) (*float64, error)
+	GetSensorStatuses(ctx context.Context, groupName string, status domain.LivenessStatus) ([]domain.SensorStatus, error)
 	GetCurrentSpecies(ctx context.Context, groupName string) ([]domain.DetectedFish, error)
 	GetCurrentTopSpecies(ctx context.Context, groupName, start, end string, top int) ([]domain.DetectedFish, error)
-	GetRegionTemperature(ctx context.Context, region domain.Region, flag string) (float64, error)
+	GetSpeciesCatalogue(habitat string) []domain.Species
+	GetFaultRules() []domain.FaultRule
+	CreateFaultRule(ctx context.Context, rule domain.CreateFaultRule) (*domain.FaultRule, error)
+	UpdateFaultRule(id int, rule domain.UpdateFaultRule) (*domain.FaultRule, error)
+	DeleteFaultRule(id int) error
+	GetActiveFaults() []domain.ActiveFault
+	GetSchedulerStats() (*domain.SchedulerStats, error)
+	GetTasks() []domain.TaskStatus
+	CreateRegion(ctx context.Context, region domain.CreateRegion) (*domain.NamedRegion, error)
+	GetRegions(ctx context.Context) ([]domain.NamedRegion, error)
+	GetRegion(ctx context.Context, name string) (*domain.NamedRegion, error)
+	DeleteRegion(ctx context.Context, name string) error
+	GetRegionSeries(ctx context.Context, name string, query domain.SeriesQuery) ([]domain.SeriesPoint, error)
+	CreateAlertRule(ctx context.Context, rule domain.CreateAlertRule) (*domain.AlertRule, error)
+	GetAlertRules(ctx context.Context) ([]domain.AlertRule, error)
+	DeleteAlertRule(ctx context.Context, id int) error
+	GetAlerts(ctx context.Context, query domain.AlertQuery) ([]domain.Alert, error)
+	CreateWebhook(ctx context.Context, webhook domain.CreateWebhook) (*domain.Webhook, error)
+	GetWebhooks(ctx context.Context) ([]domain.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int) error
+	GetWebhookDeliveries(ctx context.Context, id, limit int) ([]domain.WebhookDelivery, error)
+	GetDeadLetters(ctx context.Context, id, limit int) ([]domain.DeadLetter, error)
+	GetAnomalies(ctx context.Context, query domain.AnomalyQuery) ([]domain.Anomaly, error)
+	GetRegionSensors(ctx context.Context, area domain.Area) ([]domain.Sensor, error)
+	GetNearestSensors(ctx context.Context, point domain.Coordinates, k int) ([]domain.SensorDistance, error)
+	GetRegionTemperature(ctx context.Context, area domain.Area, agg domain.Aggregation, percentile float64, start, end string) (*float64, []domain.Sensor, error)
+	GetRegionTransparency(ctx context.Context, area domain.Area, start, end string) (*float64, []domain.Sensor, error)
+	GetRegionSpecies(ctx context.Context, area domain.Area) ([]domain.DetectedFish, []domain.Sensor, error)
+	GetRegionTopSpecies(ctx context.Context, area domain.Area, start, end string, top int) ([]domain.DetectedFish, []domain.Sensor, error)
 	GetSensorTemperature(ctx context.Context, inGroupID int, group, start, end string) (*float64, error)
+	GetTransparencyForPeriod(ctx context.Context, groupName, start, end string) (*float64, error)
+	GetSensorTransparency(ctx context.Context, inGroupID int, group, start, end string) (*float64, error)
+	CreateGroup(ctx context.Context, group domain.CreateGroup) (*domain.SensorGroup, error)
+	GetGroups(ctx context.Context) ([]domain.SensorGroup, error)
+	GetGroup(ctx context.Context, name string) (*domain.SensorGroup, error)
+	UpdateGroup(ctx context.Context, name string, group domain.UpdateGroup) (*domain.SensorGroup, error)
+	DeleteGroup(ctx context.Context, name string) error
+	CreateSensor(ctx context.Context, sensor domain.CreateSensor) (*domain.Sensor, error)
+	GetSensors(ctx context.Context, groupName string) ([]domain.Sensor, error)
+	GetSensor(ctx context.Context, codename string) (*domain.Sensor, error)
+	UpdateSensor(ctx context.Context, codename string, sensor domain.UpdateSensor) (*domain.Sensor, error)
+	DeleteSensor(ctx context.Context, codename string) error
+	GetSensorSeries(ctx context.Context, codename string, query domain.SeriesQuery) ([]domain.SeriesPoint, error)
+	GetGroupSeries(ctx context.Context, groupName string, query domain.SeriesQuery) ([]domain.SeriesPoint, error)
+	IngestMeasurements(ctx context.Context, codename string, measurements []domain.IngestMeasurement) ([]domain.Measurement, error)
+	SubscribeReadings(filter stream.Filter) *stream.Subscription
+	UnsubscribeReadings(sub *stream.Subscription)
+	Now() time.Time
 }
 
 type Service struct {
-	db    storage.SensorPostgres
-	log   logging.Logger
-	ctx   context.Context
-	cfg   config.Config
-	cache cache.CacheRedis
+	db       storage.SensorPostgres
+	log      logging.Logger
+	ctx      context.Context
+	cfg      config.Config
+	cache    cache.CacheRedis
+	observer SensorObserver
+	hub      *stream.Hub
+	scorer   AnomalyScorer
+	liveness SensorLiveness
+	species  []domain.Species
+	faults   FaultInjector
+	// supervisor runs the workers, nil if nothing is supervised
+	supervisor TaskSupervisor
+	// clock is the time of the simulation, the registered sensors start their data at it
+	clock generations.Clock
 }
 
-func NewService(ctx context.Context, db storage.SensorPostgres, log logging.Logger, cfg config.Config, cache cache.CacheRedis) *Service {
-	return &Service{db: db, log: log, ctx: ctx, cfg: cfg, cache: cache}
+// NewService creates the sensor service, observer may be nil if nobody follows the sensor changes.
+// The ingested readings are published to the hub, which also serves the live stream subscriptions,
+// and are scored by the scorer if it is not nil.
+// species is the catalogue the simulated detections are drawn from, faults inject the faults into them.
+// supervisor reports the states of the workers, it may be nil. clock is the clock of the simulation.
+func NewService(ctx context.Context, db storage.SensorPostgres, log logging.Logger, cfg config.Config, cache cache.CacheRedis,
+	observer SensorObserver, hub *stream.Hub, scorer AnomalyScorer, liveness SensorLiveness, species []domain.Species,
+	faults FaultInjector, supervisor TaskSupervisor, clock generations.Clock) *Service {
+	return &Service{db: db, log: log, ctx: ctx, cfg: cfg, cache: cache, observer: observer, hub: hub, scorer: scorer,
+		liveness: liveness, species: species, faults: faults, supervisor: supervisor, clock: clock}
+}
+
+// Now returns the time of the simulation.
+func (s *Service) Now() time.Time {
+	return s.clock.Now()
 }
 
 func (s *Service) GetTransparency(ctx context.Context, groupName string) (*float64, error) {
-	if !s.validateGroupName(groupName) {
-		return nil, ErrorWrongGroupName
+	if err := s.validateGroupName(ctx, groupName); err != nil {
+		return nil, err
 	}
 
 	cacheKey := fmt.Sprintf("transparency for %s", groupName)
@@ -77,8 +152,8 @@ func (s *Service) GetTransparency(ctx context.Context, groupName string) (*float
 }
 
 func (s *Service) GetTemperature(ctx context.Context, groupName string) (*float64, error) {
-	if !s.validateGroupName(groupName) {
-		return nil, ErrorWrongGroupName
+	if err := s.validateGroupName(ctx, groupName); err != nil {
+		return nil, err
 	}
 
 	cacheKey := fmt.Sprintf("temperature for %s", groupName)
@@ -116,8 +191,8 @@ func (s *Service) GetTemperature(ctx context.Context, groupName string) (*float6
 }
 
 func (s *Service) GetCurrentSpecies(ctx context.Context, groupName string) ([]domain.DetectedFish, error) {
-	if !s.validateGroupName(groupName) {
-		return nil, ErrorWrongGroupName
+	if err := s.validateGroupName(ctx, groupName); err != nil {
+		return nil, err
 	}
 
 	species, err := s.db.GetSpecies(ctx, groupName)
@@ -129,8 +204,17 @@ func (s *Service) GetCurrentSpecies(ctx context.Context, groupName string) ([]do
 }
 
 func (s *Service) GetCurrentTopSpecies(ctx context.Context, groupName, start, end string, top int) ([]domain.DetectedFish, error) {
-	if !s.validateGroupName(groupName) {
-		return nil, ErrorWrongGroupName
+	if err := s.validateGroupName(ctx, groupName); err != nil {
+		return nil, err
+	}
+
+	bucket, err := s.rollupBucket(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	if bucket != "" {
+		return s.db.GetTopSpeciesRollup(ctx, groupName, bucket, start, end, top)
 	}
 
 	species, err := s.db.GetTopSpecies(ctx, groupName, start, end, top)
@@ -141,16 +225,16 @@ func (s *Service) GetCurrentTopSpecies(ctx context.Context, groupName, start, en
 	return species, nil
 }
 
-func (s *Service) GetRegionTemperature(ctx context.Context, region domain.Region, flag string) (float64, error) {
-	temperature, err := s.db.GetRegionTemperature(ctx, region, flag)
+func (s *Service) GetSensorTemperature(ctx context.Context, inGroupID int, group, start, end string) (*float64, error) {
+	bucket, err := s.rollupBucket(ctx, start, end)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	return temperature, nil
-}
+	if bucket != "" {
+		return s.db.GetSensorAverageTemperatureRollup(ctx, inGroupID, group, bucket, start, end)
+	}
 
-func (s *Service) GetSensorTemperature(ctx context.Context, inGroupID int, group, start, end string) (*float64, error) {
 	temperature, err := s.db.GetSensorAverageTemperature(ctx, inGroupID, group, start, end)
 	if err != nil {
 		return nil, err
@@ -159,17 +243,91 @@ func (s *Service) GetSensorTemperature(ctx context.Context, inGroupID int, group
 	return temperature, nil
 }
 
-func (s *Service) validateGroupName(groupName string) bool {
-	exist := false
-	for _, name := range strings.Split(s.cfg.GroupNames, " ") {
-		if groupName == name {
-			exist = true
+func (s *Service) GetTransparencyForPeriod(ctx context.Context, groupName, start, end string) (*float64, error) {
+	if err := s.validateGroupName(ctx, groupName); err != nil {
+		return nil, err
+	}
+
+	bucket, err := s.rollupBucket(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	if bucket != "" {
+		return s.db.GetGroupAverageTransparencyRollup(ctx, groupName, bucket, start, end)
+	}
+
+	transparency, err := s.db.GetGroupAverageTransparency(ctx, groupName, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return transparency, nil
+}
+
+func (s *Service) GetSensorTransparency(ctx context.Context, inGroupID int, group, start, end string) (*float64, error) {
+	bucket, err := s.rollupBucket(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	if bucket != "" {
+		return s.db.GetSensorAverageTransparencyRollup(ctx, inGroupID, group, bucket, start, end)
+	}
+
+	transparency, err := s.db.GetSensorAverageTransparency(ctx, inGroupID, group, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return transparency, nil
+}
+
+// rollupBucket returns the coarsest rollup bucket which covers the whole period, or empty string if the period
+// must be computed from the raw data: its bounds are not aligned to the bucket or it is not aggregated yet.
+func (s *Service) rollupBucket(ctx context.Context, start, end string) (domain.Bucket, error) {
+	if start == "" || end == "" {
+		return "", nil
+	}
+
+	from, err := time.Parse(utils.TimeLayout, start)
+	if err != nil {
+		return "", nil
+	}
+
+	till, err := time.Parse(utils.TimeLayout, end)
+	if err != nil || !till.After(from) {
+		return "", nil
+	}
+
+	for i := len(domain.Buckets) - 1; i >= 0; i-- {
+		bucket := domain.Buckets[i]
+
+		if !bucket.Truncate(from).Equal(from) || !bucket.Truncate(till).Equal(till) {
+			continue
+		}
+
+		watermark, err := s.db.GetRollupWatermark(ctx, bucket)
+		if err != nil {
+			return "", err
+		}
+
+		if watermark != nil && !till.After(*watermark) {
+			return bucket, nil
 		}
 	}
 
-	if exist {
-		return true
+	return "", nil
+}
+
+func (s *Service) validateGroupName(ctx context.Context, groupName string) error {
+	_, err := s.db.GetGroup(ctx, groupName)
+	if err != nil {
+		if errors.Is(err, storage.ErrGroupNotFound) {
+			return ErrorWrongGroupName
+		}
+		return err
 	}
 
-	return false
+	return nil
 }
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+)
+
+// maxSeriesPoints limits the number of buckets a single series query may return.
+const maxSeriesPoints = 10000
+
+var (
+	ErrorWrongMetric      = errors.New("metric must be temperature or transparency")
+	ErrorWrongBucket      = errors.New("bucket must be 1m, 1h or 1d")
+	ErrorWrongAggregation = errors.New("agg must be avg, min, max or p95")
+	ErrorWrongPeriod      = errors.New("from and till are required and till must be after from")
+	ErrorTooManyPoints    = errors.New("too many buckets in the period")
+)
+
+// GetSensorSeries returns the bucketed time series of a metric reported by a sensor.
+func (s *Service) GetSensorSeries(ctx context.Context, codename string, query domain.SeriesQuery) ([]domain.SeriesPoint, error) {
+	sensor, err := s.GetSensor(ctx, codename)
+	if err != nil {
+		return nil, err
+	}
+
+	query.SensorID = sensor.ID
+	query.GroupName = sensor.Codename.Name
+
+	return s.getSeries(ctx, query)
+}
+
+// GetGroupSeries returns the bucketed time series of a metric reported by all sensors of a group.
+func (s *Service) GetGroupSeries(ctx context.Context, groupName string, query domain.SeriesQuery) ([]domain.SeriesPoint, error) {
+	if err := s.validateGroupName(ctx, groupName); err != nil {
+		return nil, err
+	}
+
+	query.GroupName = groupName
+
+	return s.getSeries(ctx, query)
+}
+
+// getSeries reads the aggregated part of the period from the rollups and computes the rest from the raw data.
+// Percentiles can not be merged from the rollups, so they are always computed from the raw data.
+func (s *Service) getSeries(ctx context.Context, query domain.SeriesQuery) ([]domain.SeriesPoint, error) {
+	if err := validateSeriesQuery(&query); err != nil {
+		return nil, err
+	}
+
+	if query.Aggregation == domain.AggregationP95 {
+		return s.db.GetSeries(ctx, query)
+	}
+
+	watermark, err := s.db.GetRollupWatermark(ctx, query.Bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	split := query.Bucket.Truncate(query.Till)
+	if watermark == nil {
+		split = query.From
+	} else if watermark.Before(split) {
+		split = *watermark
+	}
+
+	if !split.After(query.From) {
+		return s.db.GetSeries(ctx, query)
+	}
+
+	rollupQuery := query
+	rollupQuery.Till = split
+
+	points, err := s.db.GetSeriesRollup(ctx, rollupQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	if split.Before(query.Till) {
+		rawQuery := query
+		rawQuery.From = split
+
+		rawPoints, err := s.db.GetSeries(ctx, rawQuery)
+		if err != nil {
+			return nil, err
+		}
+
+		points = append(points, rawPoints...)
+	}
+
+	return points, nil
+}
+
+// validateSeriesQuery checks the query parameters and rounds the start of the period down to the bucket start.
+func validateSeriesQuery(query *domain.SeriesQuery) error {
+	if !query.Metric.Valid() {
+		return ErrorWrongMetric
+	}
+
+	if !query.Bucket.Valid() {
+		return ErrorWrongBucket
+	}
+
+	if !query.Aggregation.Valid() {
+		return ErrorWrongAggregation
+	}
+
+	if query.From.IsZero() || query.Till.IsZero() || !query.Till.After(query.From) {
+		return ErrorWrongPeriod
+	}
+
+	query.From = query.Bucket.Truncate(query.From)
+
+	if query.Till.Sub(query.From)/query.Bucket.Duration() > maxSeriesPoints {
+		return fmt.Errorf("%w, maximum is %d, use a coarser bucket", ErrorTooManyPoints, maxSeriesPoints)
+	}
+
+	return nil
+}
@@ -0,0 +1,20 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+)
+
+// GetSpeciesCatalogue returns the species catalogue, only the species of the habitat if it is not empty.
+func (s *Service) GetSpeciesCatalogue(habitat string) []domain.Species {
+	species := make([]domain.Species, 0, len(s.species))
+
+	for _, sp := range s.species {
+		if habitat == "" || strings.EqualFold(sp.Habitat, habitat) {
+			species = append(species, sp)
+		}
+	}
+
+	return species
+}
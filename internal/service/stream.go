@@ -0,0 +1,13 @@
+package service
+
+import "github.com/PavelDonchenko/sensor-go/internal/stream"
+
+// SubscribeReadings subscribes to the new readings matching the filter, the subscription must be cancelled
+// with UnsubscribeReadings once the subscriber is gone.
+func (s *Service) SubscribeReadings(filter stream.Filter) *stream.Subscription {
+	return s.hub.Subscribe(filter, s.cfg.Stream.BufferSize)
+}
+
+func (s *Service) UnsubscribeReadings(sub *stream.Subscription) {
+	s.hub.Unsubscribe(sub)
+}
@@ -0,0 +1,17 @@
+package service
+
+import "github.com/PavelDonchenko/sensor-go/internal/domain"
+
+// TaskSupervisor runs the long-lived tasks of the service and knows their states.
+type TaskSupervisor interface {
+	Tasks() []domain.TaskStatus
+}
+
+// GetTasks returns the states of the supervised tasks, none if nothing is supervised.
+func (s *Service) GetTasks() []domain.TaskStatus {
+	if s.supervisor == nil {
+		return make([]domain.TaskStatus, 0)
+	}
+
+	return s.supervisor.Tasks()
+}
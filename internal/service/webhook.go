@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+)
+
+var (
+	ErrorWrongWebhookURL   = errors.New("webhook url must be an absolute http or https url")
+	ErrorEmptySecret       = errors.New("webhook secret must not be empty")
+	ErrorWrongEventType    = errors.New("unknown event type")
+	ErrorWrongWebhookLimit = errors.New("limit must be between 1 and 1000")
+)
+
+const maxWebhookLimit = 1000
+
+func (s *Service) CreateWebhook(ctx context.Context, webhook domain.CreateWebhook) (*domain.Webhook, error) {
+	webhook.URL = strings.TrimSpace(webhook.URL)
+
+	u, err := url.Parse(webhook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, ErrorWrongWebhookURL
+	}
+
+	if webhook.Secret == "" {
+		return nil, ErrorEmptySecret
+	}
+
+	for _, event := range webhook.Events {
+		if !event.Valid() {
+			return nil, ErrorWrongEventType
+		}
+	}
+
+	created, err := s.db.CreateWebhook(ctx, webhook)
+	if err != nil {
+		return nil, err
+	}
+
+	created.Secret = ""
+
+	return created, nil
+}
+
+// GetWebhooks returns the webhooks without their secrets, only the delivery worker signs with them.
+func (s *Service) GetWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	webhooks, err := s.db.GetWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+
+	return webhooks, nil
+}
+
+func (s *Service) DeleteWebhook(ctx context.Context, id int) error {
+	return s.db.DeleteWebhook(ctx, id)
+}
+
+// GetWebhookDeliveries returns the delivery attempts of a webhook, the latest first.
+func (s *Service) GetWebhookDeliveries(ctx context.Context, id, limit int) ([]domain.WebhookDelivery, error) {
+	if err := s.validateWebhook(ctx, id, limit); err != nil {
+		return nil, err
+	}
+
+	return s.db.GetWebhookDeliveries(ctx, id, limit)
+}
+
+// GetDeadLetters returns the events which could not be delivered to a webhook, the latest first.
+func (s *Service) GetDeadLetters(ctx context.Context, id, limit int) ([]domain.DeadLetter, error) {
+	if err := s.validateWebhook(ctx, id, limit); err != nil {
+		return nil, err
+	}
+
+	return s.db.GetDeadLetters(ctx, id, limit)
+}
+
+func (s *Service) validateWebhook(ctx context.Context, id, limit int) error {
+	if limit <= 0 || limit > maxWebhookLimit {
+		return ErrorWrongWebhookLimit
+	}
+
+	_, err := s.db.GetWebhook(ctx, id)
+
+	return err
+}
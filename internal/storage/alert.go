@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/pkg/postgres"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrAlertRuleNotFound = errors.New("alert rule not found")
+
+const alertRuleColumns = `r.id, r.name, r.metric, COALESCE(r.species, ''), COALESCE(r.group_name, ''), r.sensor_id,
+						  COALESCE(s.group_name || s.in_group_id, ''), COALESCE(r.region_name, ''), r.aggregation,
+						  r.operator, r.threshold, r.consecutive, r.created_at`
+
+const alertColumns = `a.id, a.rule_id, r.name, a.sensor_id, COALESCE(s.group_name || s.in_group_id, ''),
+					  a.value, a.fired_at, a.resolved_value, a.resolved_at`
+
+func (d *Database) CreateAlertRule(ctx context.Context, rule domain.AlertRule) (*domain.AlertRule, error) {
+	query := `INSERT INTO alert_rule (name, metric, species, group_name, sensor_id, region_name, aggregation,
+									  operator, threshold, consecutive, created_at)
+			  VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5, NULLIF($6, ''), $7, $8, $9, $10, LOCALTIMESTAMP)
+			  RETURNING id, created_at`
+
+	err := d.DB.QueryRow(ctx, query, rule.Name, rule.Metric, rule.Species, rule.GroupName, rule.SensorID, rule.Region,
+		rule.Aggregation, rule.Operator, rule.Threshold, rule.Consecutive).Scan(&rule.ID, &rule.CreatedAt)
+	if err != nil {
+		err = postgres.ErrScan(err)
+		d.log.Error(err)
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+func (d *Database) GetAlertRules(ctx context.Context) ([]domain.AlertRule, error) {
+	query := "SELECT " + alertRuleColumns + " FROM alert_rule r LEFT JOIN sensor s ON s.id = r.sensor_id ORDER BY r.id"
+
+	rows, err := d.DB.Query(ctx, query)
+	if err != nil {
+		err = postgres.ErrDoQuery(err)
+		d.log.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := make([]domain.AlertRule, 0)
+
+	for rows.Next() {
+		var rule domain.AlertRule
+
+		err = rows.Scan(&rule.ID, &rule.Name, &rule.Metric, &rule.Species, &rule.GroupName, &rule.SensorID, &rule.Codename,
+			&rule.Region, &rule.Aggregation, &rule.Operator, &rule.Threshold, &rule.Consecutive, &rule.CreatedAt)
+		if err != nil {
+			err = postgres.ErrScan(err)
+			d.log.Error(err)
+			return nil, err
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func (d *Database) DeleteAlertRule(ctx context.Context, id int) error {
+	tag, err := d.DB.Exec(ctx, "DELETE FROM alert_rule WHERE id = $1", id)
+	if err != nil {
+		err = postgres.ErrExecQuery(err)
+		d.log.Error(err)
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrAlertRuleNotFound
+	}
+
+	return nil
+}
+
+// GetAlerts returns the alert history matching the query, the latest fired alerts first. Zero limit returns every alert.
+func (d *Database) GetAlerts(ctx context.Context, q domain.AlertQuery) ([]domain.Alert, error) {
+	query := `SELECT ` + alertColumns + `
+			  FROM alert a
+			  JOIN alert_rule r ON r.id = a.rule_id
+			  LEFT JOIN sensor s ON s.id = a.sensor_id
+			  WHERE ($1::int = 0 OR a.rule_id = $1)
+			    AND ($2::text = '' OR ($2 = 'firing') = (a.resolved_at IS NULL))
+			    AND ($3::timestamp IS NULL OR a.fired_at >= $3)
+			    AND ($4::timestamp IS NULL OR a.fired_at < $4)
+			  ORDER BY a.fired_at DESC, a.id DESC
+			  LIMIT NULLIF($5, 0)`
+
+	var from, till *time.Time
+	if !q.From.IsZero() {
+		from = &q.From
+	}
+	if !q.Till.IsZero() {
+		till = &q.Till
+	}
+
+	rows, err := d.DB.Query(ctx, query, q.RuleID, q.State, from, till, q.Limit)
+	if err != nil {
+		err = postgres.ErrDoQuery(err)
+		d.log.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	alerts := make([]domain.Alert, 0)
+
+	for rows.Next() {
+		alert, err := scanAlert(rows)
+		if err != nil {
+			err = postgres.ErrScan(err)
+			d.log.Error(err)
+			return nil, err
+		}
+
+		alerts = append(alerts, *alert)
+	}
+
+	return alerts, nil
+}
+
+// FireAlert saves a new firing alert, the rule name and the codename are filled in from the saved row.
+func (d *Database) FireAlert(ctx context.Context, alert domain.Alert) (*domain.Alert, error) {
+	query := `WITH a AS (
+				INSERT INTO alert (rule_id, sensor_id, value, fired_at)
+				VALUES ($1, $2, $3, $4)
+				RETURNING *
+			  )
+			  SELECT ` + alertColumns + `
+			  FROM a
+			  JOIN alert_rule r ON r.id = a.rule_id
+			  LEFT JOIN sensor s ON s.id = a.sensor_id`
+
+	fired, err := scanAlert(d.DB.QueryRow(ctx, query, alert.RuleID, alert.SensorID, alert.Value, alert.FiredAt))
+	if err != nil {
+		err = postgres.ErrScan(err)
+		d.log.Error(err)
+		return nil, err
+	}
+
+	return fired, nil
+}
+
+func (d *Database) ResolveAlert(ctx context.Context, id int64, value float64, resolvedAt time.Time) error {
+	query := "UPDATE alert SET resolved_value = $2, resolved_at = $3 WHERE id = $1 AND resolved_at IS NULL"
+
+	_, err := d.DB.Exec(ctx, query, id, value, resolvedAt)
+	if err != nil {
+		err = postgres.ErrExecQuery(err)
+		d.log.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func scanAlert(row pgx.Row) (*domain.Alert, error) {
+	var alert domain.Alert
+
+	err := row.Scan(&alert.ID, &alert.RuleID, &alert.RuleName, &alert.SensorID, &alert.Codename,
+		&alert.Value, &alert.FiredAt, &alert.ResolvedValue, &alert.ResolvedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	alert.State = domain.AlertFiring
+	if alert.ResolvedAt != nil {
+		alert.State = domain.AlertResolved
+	}
+
+	return &alert, nil
+}
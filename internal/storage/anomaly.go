@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/pkg/postgres"
+)
+
+func (d *Database) SaveAnomaly(ctx context.Context, anomaly domain.Anomaly) (*domain.Anomaly, error) {
+	query := `INSERT INTO anomaly (measurement_id, sensor_id, metric, value, score, z_score, spatial_score, created_at, detected_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, LOCALTIMESTAMP)
+			  RETURNING id, detected_at`
+
+	err := d.DB.QueryRow(ctx, query, anomaly.MeasurementID, anomaly.SensorID, anomaly.Metric, anomaly.Value, anomaly.Score,
+		anomaly.ZScore, anomaly.SpatialScore, anomaly.CreatedAt).Scan(&anomaly.ID, &anomaly.DetectedAt)
+	if err != nil {
+		err = postgres.ErrScan(err)
+		d.log.Error(err)
+		return nil, err
+	}
+
+	return &anomaly, nil
+}
+
+// GetAnomalies returns the flagged readings matching the query, the latest first.
+func (d *Database) GetAnomalies(ctx context.Context, q domain.AnomalyQuery) ([]domain.Anomaly, error) {
+	query := `SELECT a.id, a.measurement_id, a.sensor_id, s.group_name || s.in_group_id, s.group_name, a.metric, a.value,
+					 a.score, a.z_score, a.spatial_score, a.created_at, a.detected_at
+			  FROM anomaly a
+			  JOIN sensor s ON s.id = a.sensor_id
+			  WHERE ($1::text = '' OR s.group_name = $1)
+			    AND ($2::timestamp IS NULL OR a.created_at >= $2)
+			    AND ($3::timestamp IS NULL OR a.created_at < $3)
+			  ORDER BY a.created_at DESC, a.id DESC
+			  LIMIT $4`
+
+	var from, till *time.Time
+	if !q.From.IsZero() {
+		from = &q.From
+	}
+	if !q.Till.IsZero() {
+		till = &q.Till
+	}
+
+	rows, err := d.DB.Query(ctx, query, q.GroupName, from, till, q.Limit)
+	if err != nil {
+		err = postgres.ErrDoQuery(err)
+		d.log.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	anomalies := make([]domain.Anomaly, 0)
+
+	for rows.Next() {
+		var a domain.Anomaly
+
+		err = rows.Scan(&a.ID, &a.MeasurementID, &a.SensorID, &a.Codename, &a.GroupName, &a.Metric, &a.Value,
+			&a.Score, &a.ZScore, &a.SpatialScore, &a.CreatedAt, &a.DetectedAt)
+		if err != nil {
+			err = postgres.ErrScan(err)
+			d.log.Error(err)
+			return nil, err
+		}
+
+		anomalies = append(anomalies, a)
+	}
+
+	return anomalies, nil
+}
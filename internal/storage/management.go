@@ -0,0 +1,425 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/pkg/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrGroupNotFound  = errors.New("sensor group not found")
+	ErrGroupExists    = errors.New("sensor group already exists")
+	ErrSensorNotFound = errors.New("sensor not found")
+	ErrSensorExists   = errors.New("sensor already exists")
+)
+
+const uniqueViolation = "23505"
+
+const sensorColumns = `id, source, temperature, transparency, created_at, COALESCE(updated_at, created_at), in_group_id, group_name, data_output_rate, x, y, z`
+
+func (d *Database) CreateGroup(ctx context.Context, name string) (*domain.SensorGroup, error) {
+	tx, err := d.DB.Begin(ctx)
+	if err != nil {
+		err = postgres.ErrCreateTx(err)
+		d.log.Error(err)
+		return nil, err
+	}
+
+	// group ids are not generated by the database, serialize the creation to take the next one safely
+	_, err = tx.Exec(ctx, "LOCK TABLE sensor_group IN SHARE ROW EXCLUSIVE MODE")
+	if err != nil {
+		err = postgres.ErrExecQuery(err)
+		d.log.Error(err)
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+
+	query := `INSERT INTO sensor_group (id, name)
+			  SELECT COALESCE(MAX(id) + 1, 0), $1 FROM sensor_group
+			  RETURNING id, name`
+
+	var group domain.SensorGroup
+
+	err = tx.QueryRow(ctx, query, name).Scan(&group.ID, &group.Name)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		if isUniqueViolation(err) {
+			return nil, ErrGroupExists
+		}
+		err = postgres.ErrScan(err)
+		d.log.Error(err)
+		return nil, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		err = postgres.ErrCommit(err)
+		d.log.Error(err)
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+func (d *Database) GetGroups(ctx context.Context) ([]domain.SensorGroup, error) {
+	query := "SELECT id, name FROM sensor_group ORDER BY id"
+
+	rows, err := d.DB.Query(ctx, query)
+	if err != nil {
+		err = postgres.ErrDoQuery(err)
+		d.log.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var groups []domain.SensorGroup
+
+	for rows.Next() {
+		var group domain.SensorGroup
+		err := rows.Scan(&group.ID, &group.Name)
+		if err != nil {
+			err = postgres.ErrScan(err)
+			d.log.Error(err)
+			return nil, err
+		}
+
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+func (d *Database) GetGroup(ctx context.Context, name string) (*domain.SensorGroup, error) {
+	query := "SELECT id, name FROM sensor_group WHERE name = $1"
+
+	var group domain.SensorGroup
+
+	err := d.DB.QueryRow(ctx, query, name).Scan(&group.ID, &group.Name)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrGroupNotFound
+		}
+		err = postgres.ErrScan(err)
+		d.log.Error(err)
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+func (d *Database) RenameGroup(ctx context.Context, name, newName string) (*domain.SensorGroup, error) {
+	query := "UPDATE sensor_group SET name = $2 WHERE name = $1 RETURNING id, name"
+
+	var group domain.SensorGroup
+
+	err := d.DB.QueryRow(ctx, query, name, newName).Scan(&group.ID, &group.Name)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrGroupNotFound
+		}
+		if isUniqueViolation(err) {
+			return nil, ErrGroupExists
+		}
+		err = postgres.ErrScan(err)
+		d.log.Error(err)
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+func (d *Database) DeleteGroup(ctx context.Context, name string) error {
+	query := "DELETE FROM sensor_group WHERE name = $1"
+
+	ct, err := d.DB.Exec(ctx, query, name)
+	if err != nil {
+		err = postgres.ErrExecQuery(err)
+		d.log.Error(err)
+		return err
+	}
+
+	if ct.RowsAffected() == 0 {
+		return ErrGroupNotFound
+	}
+
+	return nil
+}
+
+func (d *Database) GetGroupSensors(ctx context.Context, groupName string) ([]domain.Sensor, error) {
+	query := "SELECT " + sensorColumns + " FROM sensor WHERE group_name = $1 ORDER BY in_group_id"
+
+	rows, err := d.DB.Query(ctx, query, groupName)
+	if err != nil {
+		err = postgres.ErrDoQuery(err)
+		d.log.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sensors []domain.Sensor
+
+	for rows.Next() {
+		var sensor domain.Sensor
+		err := scanSensor(rows, &sensor)
+		if err != nil {
+			err = postgres.ErrScan(err)
+			d.log.Error(err)
+			return nil, err
+		}
+
+		sensors = append(sensors, sensor)
+	}
+
+	return sensors, nil
+}
+
+func (d *Database) GetSensor(ctx context.Context, groupName string, inGroupID int) (*domain.Sensor, error) {
+	query := "SELECT " + sensorColumns + " FROM sensor WHERE group_name = $1 AND in_group_id = $2"
+
+	var sensor domain.Sensor
+
+	err := scanSensor(d.DB.QueryRow(ctx, query, groupName, inGroupID), &sensor)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSensorNotFound
+		}
+		err = postgres.ErrScan(err)
+		d.log.Error(err)
+		return nil, err
+	}
+
+	return &sensor, nil
+}
+
+// CreateSensor registers the sensor in its group under the index of its codename, under the next index of the group
+// if the codename has none.
+func (d *Database) CreateSensor(ctx context.Context, sensor domain.Sensor) (*domain.Sensor, error) {
+	tx, err := d.DB.Begin(ctx)
+	if err != nil {
+		err = postgres.ErrCreateTx(err)
+		d.log.Error(err)
+		return nil, err
+	}
+
+	groupID, inGroupID, err := d.takeInGroupID(ctx, tx, sensor.Codename.Name, sensor.Codename.SensorGroupID)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+
+	query := `INSERT INTO sensor (group_id, group_name, in_group_id, data_output_rate, x, y, z, transparency, temperature, source)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			  RETURNING ` + sensorColumns
+
+	var created domain.Sensor
+
+	err = scanSensor(tx.QueryRow(ctx, query, groupID, sensor.Codename.Name, inGroupID, sensor.DataOutputRate,
+		sensor.Coordinates.X, sensor.Coordinates.Y, sensor.Coordinates.Z, sensor.Transparency, sensor.Temperature, string(sensor.Source)), &created)
+	if err != nil {
+		if isUniqueViolation(err) {
+			_ = tx.Rollback(ctx)
+			return nil, ErrSensorExists
+		}
+		err = postgres.ErrScan(err)
+		d.log.Error(err)
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+
+	err = d.refreshGroupSensors(ctx, tx, created.Codename.Name)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		err = postgres.ErrCommit(err)
+		d.log.Error(err)
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// UpdateSensor saves the data output rate, the coordinates, the source and the group of the sensor.
+// A sensor moved to another group gets the next index of that group.
+func (d *Database) UpdateSensor(ctx context.Context, sensor domain.Sensor) (*domain.Sensor, error) {
+	tx, err := d.DB.Begin(ctx)
+	if err != nil {
+		err = postgres.ErrCreateTx(err)
+		d.log.Error(err)
+		return nil, err
+	}
+
+	var current domain.Sensor
+
+	currentQuery := "SELECT " + sensorColumns + " FROM sensor WHERE id = $1 FOR UPDATE"
+
+	err = scanSensor(tx.QueryRow(ctx, currentQuery, sensor.ID), &current)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSensorNotFound
+		}
+		err = postgres.ErrScan(err)
+		d.log.Error(err)
+		return nil, err
+	}
+
+	query := `UPDATE sensor SET data_output_rate = $2, x = $3, y = $4, z = $5, source = $6, updated_at = LOCALTIMESTAMP
+			  WHERE id = $1
+			  RETURNING ` + sensorColumns
+	args := []any{sensor.ID, sensor.DataOutputRate, sensor.Coordinates.X, sensor.Coordinates.Y, sensor.Coordinates.Z, string(sensor.Source)}
+
+	moved := sensor.Codename.Name != current.Codename.Name
+	if moved {
+		groupID, inGroupID, err := d.nextInGroupID(ctx, tx, sensor.Codename.Name)
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return nil, err
+		}
+
+		query = `UPDATE sensor SET data_output_rate = $2, x = $3, y = $4, z = $5, source = $6, updated_at = LOCALTIMESTAMP,
+				 group_id = $7, group_name = $8, in_group_id = $9
+				 WHERE id = $1
+				 RETURNING ` + sensorColumns
+		args = append(args, groupID, sensor.Codename.Name, inGroupID)
+	}
+
+	var updated domain.Sensor
+
+	err = scanSensor(tx.QueryRow(ctx, query, args...), &updated)
+	if err != nil {
+		err = postgres.ErrScan(err)
+		d.log.Error(err)
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+
+	if moved {
+		err = d.refreshGroupSensors(ctx, tx, current.Codename.Name, updated.Codename.Name)
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return nil, err
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		err = postgres.ErrCommit(err)
+		d.log.Error(err)
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+func (d *Database) DeleteSensor(ctx context.Context, id uuid.UUID) error {
+	tx, err := d.DB.Begin(ctx)
+	if err != nil {
+		err = postgres.ErrCreateTx(err)
+		d.log.Error(err)
+		return err
+	}
+
+	var groupName string
+
+	err = tx.QueryRow(ctx, "DELETE FROM sensor WHERE id = $1 RETURNING group_name", id).Scan(&groupName)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrSensorNotFound
+		}
+		err = postgres.ErrScan(err)
+		d.log.Error(err)
+		return err
+	}
+
+	err = d.refreshGroupSensors(ctx, tx, groupName)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		err = postgres.ErrCommit(err)
+		d.log.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// nextInGroupID takes the next sensor index of the group, indexes are never reused even after the sensor is deleted.
+func (d *Database) nextInGroupID(ctx context.Context, tx pgx.Tx, groupName string) (int, int, error) {
+	return d.takeInGroupID(ctx, tx, groupName, 0)
+}
+
+// takeInGroupID takes the index of the group, the next one if it is zero. The next indexes follow the largest one taken.
+func (d *Database) takeInGroupID(ctx context.Context, tx pgx.Tx, groupName string, index int) (int, int, error) {
+	query := `UPDATE sensor_group
+			  SET last_in_group_id = CASE WHEN $2::int > 0 THEN GREATEST(last_in_group_id, $2::int) ELSE last_in_group_id + 1 END
+			  WHERE name = $1
+			  RETURNING id, CASE WHEN $2::int > 0 THEN $2::int ELSE last_in_group_id END`
+
+	var groupID, inGroupID int
+
+	err := tx.QueryRow(ctx, query, groupName, index).Scan(&groupID, &inGroupID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, 0, ErrGroupNotFound
+		}
+		err = postgres.ErrScan(err)
+		d.log.Error(err)
+		return 0, 0, err
+	}
+
+	return groupID, inGroupID, nil
+}
+
+func (d *Database) refreshGroupSensors(ctx context.Context, tx pgx.Tx, groupNames ...string) error {
+	query := `UPDATE sensor_group
+			  SET sensors = (SELECT ARRAY_AGG(id) FROM sensor WHERE sensor.group_name = sensor_group.name)
+			  WHERE name = ANY($1)`
+
+	_, err := tx.Exec(ctx, query, groupNames)
+	if err != nil {
+		err = postgres.ErrExecQuery(err)
+		d.log.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// scanSensor scans the sensorColumns into the sensor, extra destinations are scanned from the columns following them.
+func scanSensor(row pgx.Row, sensor *domain.Sensor, extra ...any) error {
+	dest := []any{
+		&sensor.ID,
+		&sensor.Source,
+		&sensor.Temperature,
+		&sensor.Transparency,
+		&sensor.CreatedAt,
+		&sensor.UpdatedAt,
+		&sensor.Codename.SensorGroupID,
+		&sensor.Codename.Name,
+		&sensor.DataOutputRate,
+		&sensor.Coordinates.X,
+		&sensor.Coordinates.Y,
+		&sensor.Coordinates.Z,
+	}
+
+	return row.Scan(append(dest, extra...)...)
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/pkg/postgres"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrRegionNotFound = errors.New("region not found")
+	ErrRegionExists   = errors.New("region already exists")
+)
+
+const regionColumns = `name, x_min, x_max, y_min, y_max, z_min, z_max, center_x, center_y, center_z, radius, created_at`
+
+func (d *Database) CreateRegion(ctx context.Context, region domain.CreateRegion) (*domain.NamedRegion, error) {
+	query := `INSERT INTO region (` + regionColumns + `)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, LOCALTIMESTAMP)
+			  RETURNING ` + regionColumns
+
+	var box [6]*float64
+	if region.Box != nil {
+		box = [6]*float64{&region.Box.XMin, &region.Box.XMax, &region.Box.YMin, &region.Box.YMax, &region.Box.ZMin, &region.Box.ZMax}
+	}
+
+	var sphere [4]*float64
+	if region.Sphere != nil {
+		sphere = [4]*float64{&region.Sphere.Center.X, &region.Sphere.Center.Y, &region.Sphere.Center.Z, &region.Sphere.Radius}
+	}
+
+	created, err := scanRegion(d.DB.QueryRow(ctx, query, region.Name,
+		box[0], box[1], box[2], box[3], box[4], box[5], sphere[0], sphere[1], sphere[2], sphere[3]))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrRegionExists
+		}
+		err = postgres.ErrScan(err)
+		d.log.Error(err)
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (d *Database) GetRegions(ctx context.Context) ([]domain.NamedRegion, error) {
+	query := "SELECT " + regionColumns + " FROM region ORDER BY name"
+
+	rows, err := d.DB.Query(ctx, query)
+	if err != nil {
+		err = postgres.ErrDoQuery(err)
+		d.log.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	regions := make([]domain.NamedRegion, 0)
+
+	for rows.Next() {
+		region, err := scanRegion(rows)
+		if err != nil {
+			err = postgres.ErrScan(err)
+			d.log.Error(err)
+			return nil, err
+		}
+
+		regions = append(regions, *region)
+	}
+
+	return regions, nil
+}
+
+func (d *Database) GetRegion(ctx context.Context, name string) (*domain.NamedRegion, error) {
+	query := "SELECT " + regionColumns + " FROM region WHERE name = $1"
+
+	region, err := scanRegion(d.DB.QueryRow(ctx, query, name))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRegionNotFound
+		}
+		err = postgres.ErrScan(err)
+		d.log.Error(err)
+		return nil, err
+	}
+
+	return region, nil
+}
+
+func (d *Database) DeleteRegion(ctx context.Context, name string) error {
+	tag, err := d.DB.Exec(ctx, "DELETE FROM region WHERE name = $1", name)
+	if err != nil {
+		err = postgres.ErrExecQuery(err)
+		d.log.Error(err)
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrRegionNotFound
+	}
+
+	return nil
+}
+
+func scanRegion(row pgx.Row) (*domain.NamedRegion, error) {
+	var region domain.NamedRegion
+	var xMin, xMax, yMin, yMax, zMin, zMax, centerX, centerY, centerZ, radius *float64
+
+	err := row.Scan(&region.Name, &xMin, &xMax, &yMin, &yMax, &zMin, &zMax, &centerX, &centerY, &centerZ, &radius, &region.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// the table constraint guarantees that either every box or every sphere column is set
+	if radius != nil {
+		region.Sphere = &domain.Sphere{
+			Center: domain.Coordinates{X: *centerX, Y: *centerY, Z: *centerZ},
+			Radius: *radius,
+		}
+	} else {
+		region.Box = &domain.Region{XMin: *xMin, XMax: *xMax, YMin: *yMin, YMax: *yMax, ZMin: *zMin, ZMax: *zMax}
+	}
+
+	return &region, nil
+}
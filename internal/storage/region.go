@@ -0,0 +1,215 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/pkg/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// sensorPosition is the indexed cube of the sensor coordinates, the queries must use the same expression to use the index.
+const sensorPosition = "cube(ARRAY[x, y, z])"
+
+// GetAreaSensors returns the sensors inside the box or the sphere of the area.
+func (d *Database) GetAreaSensors(ctx context.Context, area domain.Area) ([]domain.Sensor, error) {
+	var filter string
+	var args []any
+
+	switch {
+	case area.Box != nil:
+		filter = sensorPosition + " <@ cube(ARRAY[$1, $2, $3]::float8[], ARRAY[$4, $5, $6]::float8[])"
+		args = []any{area.Box.XMin, area.Box.YMin, area.Box.ZMin, area.Box.XMax, area.Box.YMax, area.Box.ZMax}
+	case area.Sphere != nil:
+		// the bounding box of the sphere narrows the sensors down through the index before the exact distance check
+		filter = sensorPosition + ` <@ cube(ARRAY[$1::float8 - $4::float8, $2::float8 - $4::float8, $3::float8 - $4::float8],
+				  ARRAY[$1::float8 + $4::float8, $2::float8 + $4::float8, $3::float8 + $4::float8])
+				  AND ` + sensorPosition + " <-> cube(ARRAY[$1, $2, $3]::float8[]) <= $4::float8"
+		c := area.Sphere.Center
+		args = []any{c.X, c.Y, c.Z, area.Sphere.Radius}
+	default:
+		return nil, fmt.Errorf("area has neither a box nor a sphere")
+	}
+
+	query := fmt.Sprintf(`SELECT %s
+			  FROM sensor
+			  WHERE %s
+			  ORDER BY group_name, in_group_id`, sensorColumns, filter)
+
+	rows, err := d.DB.Query(ctx, query, args...)
+	if err != nil {
+		err = postgres.ErrDoQuery(err)
+		d.log.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sensors []domain.Sensor
+
+	for rows.Next() {
+		var sensor domain.Sensor
+		err = scanSensor(rows, &sensor)
+		if err != nil {
+			err = postgres.ErrScan(err)
+			d.log.Error(err)
+			return nil, err
+		}
+
+		sensors = append(sensors, sensor)
+	}
+
+	return sensors, nil
+}
+
+// GetNearestSensors returns the k sensors closest to the point, nearest first.
+func (d *Database) GetNearestSensors(ctx context.Context, point domain.Coordinates, k int) ([]domain.SensorDistance, error) {
+	query := fmt.Sprintf(`SELECT %[1]s, %[2]s <-> cube(ARRAY[$1, $2, $3]::float8[]) AS distance
+			  FROM sensor
+			  ORDER BY %[2]s <-> cube(ARRAY[$1, $2, $3]::float8[])
+			  LIMIT $4`, sensorColumns, sensorPosition)
+
+	rows, err := d.DB.Query(ctx, query, point.X, point.Y, point.Z, k)
+	if err != nil {
+		err = postgres.ErrDoQuery(err)
+		d.log.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	sensors := make([]domain.SensorDistance, 0, k)
+
+	for rows.Next() {
+		var sensor domain.SensorDistance
+		err = scanSensor(rows, &sensor.Sensor, &sensor.Distance)
+		if err != nil {
+			err = postgres.ErrScan(err)
+			d.log.Error(err)
+			return nil, err
+		}
+
+		sensors = append(sensors, sensor)
+	}
+
+	return sensors, nil
+}
+
+// GetSensorsStat aggregates the metric values measured by the sensors during the period,
+// percentile (0-100) is only used with domain.AggregationPercentile.
+func (d *Database) GetSensorsStat(ctx context.Context, ids []uuid.UUID, metric domain.Metric, agg domain.Aggregation, percentile float64, start, end string) (*float64, error) {
+	column, ok := metricColumns[metric]
+	if !ok {
+		return nil, fmt.Errorf("unknown metric %q", metric)
+	}
+
+	var aggregation string
+
+	switch agg {
+	case domain.AggregationPercentile:
+		aggregation = "percentile_cont($4::double precision) WITHIN GROUP (ORDER BY %s)"
+	default:
+		aggregation, ok = rawAggregations[agg]
+		if !ok {
+			return nil, fmt.Errorf("unknown aggregation %q", agg)
+		}
+	}
+
+	query := fmt.Sprintf(`SELECT %s
+			  FROM measurement
+			  WHERE sensorid = ANY($1)
+			  AND created_at >= $2 AND created_at < $3`, fmt.Sprintf(aggregation, column.column))
+
+	args := []any{ids, start, end}
+	if agg == domain.AggregationPercentile {
+		args = append(args, percentile/100)
+	}
+
+	var value *float64
+
+	err := d.DB.QueryRow(ctx, query, args...).Scan(&value)
+	if err != nil {
+		err = postgres.ErrScan(err)
+		d.log.Error(err)
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// GetSensorsSpecies returns the species detected by the last measurements of the sensors.
+func (d *Database) GetSensorsSpecies(ctx context.Context, ids []uuid.UUID) ([]domain.DetectedFish, error) {
+	query := `SELECT df.name, SUM(df.count) AS total_count
+		 FROM detected_fish df
+		 JOIN sensor s ON df.measurementid = s.measurementid
+		 WHERE s.id = ANY($1)
+		 GROUP BY df.name
+		 ORDER BY total_count DESC`
+
+	rows, err := d.DB.Query(ctx, query, ids)
+	if err != nil {
+		err = postgres.ErrDoQuery(err)
+		d.log.Error(err)
+		return nil, err
+	}
+
+	return d.scanSpecies(rows)
+}
+
+// GetSensorsTopSpecies returns the top species detected by the sensors during the period,
+// or by their last measurements if start is empty.
+func (d *Database) GetSensorsTopSpecies(ctx context.Context, ids []uuid.UUID, start, end string, top int) ([]domain.DetectedFish, error) {
+	var rows pgx.Rows
+	var err error
+
+	if start == "" {
+		query := `SELECT df.name, SUM(df.count) AS total_count
+			 FROM detected_fish df
+			 JOIN sensor s ON df.measurementid = s.measurementid
+			 WHERE s.id = ANY($1)
+			 GROUP BY df.name
+			 ORDER BY total_count DESC
+			 LIMIT $2`
+
+		rows, err = d.DB.Query(ctx, query, ids, top)
+	} else {
+		query := `SELECT name, SUM(count) AS total_count
+			 FROM detected_fish
+			 WHERE sensorid = ANY($1) AND created_at >= $3 AND created_at < $4
+			 GROUP BY name
+			 ORDER BY total_count DESC
+			 LIMIT $2`
+
+		rows, err = d.DB.Query(ctx, query, ids, top, start, end)
+	}
+	if err != nil {
+		err = postgres.ErrDoQuery(err)
+		d.log.Error(err)
+		return nil, err
+	}
+
+	return d.scanSpecies(rows)
+}
+
+func (d *Database) scanSpecies(rows pgx.Rows) ([]domain.DetectedFish, error) {
+	defer rows.Close()
+
+	var fishes []domain.DetectedFish
+
+	for rows.Next() {
+		var fish domain.DetectedFish
+		err := rows.Scan(
+			&fish.Name,
+			&fish.Count,
+		)
+		if err != nil {
+			err = postgres.ErrScan(err)
+			d.log.Error(err)
+			return nil, err
+		}
+
+		fishes = append(fishes, fish)
+	}
+
+	return fishes, nil
+}
@@ -0,0 +1,311 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/pkg/postgres"
+	"github.com/jackc/pgx/v5"
+)
+
+// metricRollups maps the metric rollup tables to the raw tables and value columns they are built from.
+var metricRollups = []struct {
+	table  string
+	source string
+	column string
+}{
+	{table: "temperature_rollup", source: "measurement", column: "temperature"},
+	{table: "transparency_rollup", source: "measurement", column: "transparency"},
+}
+
+// AggregateRollups computes every bucket complete by the time that was not aggregated yet, and the latest aggregated
+// one again, and moves the bucket watermark forward. The time is of the simulation clock, which runs ahead of the
+// database one in an accelerated simulation. Minute buckets are built from the raw tables, coarser ones from the next
+// finer rollup.
+func (d *Database) AggregateRollups(ctx context.Context, bucket domain.Bucket, now time.Time) error {
+	tx, err := d.DB.Begin(ctx)
+	if err != nil {
+		err = postgres.ErrCreateTx(err)
+		d.log.Error(err)
+		return err
+	}
+
+	// the watermark is locked till the commit, so a rewind by a measurement saved meanwhile is applied after
+	// the aggregation moves the watermark forward, not overwritten by it
+	from, aggregated, err := d.getWatermark(ctx, tx, bucket, true)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+
+	// the trailing aggregated bucket is aggregated again: a measurement stamped by the database when its transaction
+	// started may be committed after its bucket was aggregated
+	if aggregated {
+		from = from.Add(-bucket.Duration())
+	}
+
+	till := bucket.Truncate(now)
+
+	source := bucket.Source()
+	if source != "" {
+		sourceTill, exist, err := d.getWatermark(ctx, tx, source, false)
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return err
+		}
+
+		if !exist {
+			return tx.Rollback(ctx)
+		}
+
+		if sourceTill.Before(till) {
+			till = bucket.Truncate(sourceTill)
+		}
+	}
+
+	if !till.After(from) {
+		return tx.Rollback(ctx)
+	}
+
+	var queries []string
+
+	if source == "" {
+		for _, rollup := range metricRollups {
+			queries = append(queries, fmt.Sprintf(
+				`INSERT INTO %[1]s (bucket, bucket_start, group_name, sensorid, value_count, value_sum, value_min, value_max)
+				 SELECT $1, r.bucket_start, r.group_name, r.sensorid, COUNT(*), SUM(r.value), MIN(r.value), MAX(r.value)
+				 FROM (
+				 	SELECT date_trunc($2, m.created_at) AS bucket_start, s.group_name, m.sensorid, m.%[3]s AS value
+				 	FROM %[2]s m
+				 	JOIN sensor s ON s.id = m.sensorid
+				 	WHERE m.created_at >= $3 AND m.created_at < $4 AND m.%[3]s IS NOT NULL) r
+				 GROUP BY r.bucket_start, r.group_name, ROLLUP (r.sensorid)
+				 ON CONFLICT ON CONSTRAINT %[1]s_key DO UPDATE
+				 SET value_count = EXCLUDED.value_count, value_sum = EXCLUDED.value_sum,
+				     value_min = EXCLUDED.value_min, value_max = EXCLUDED.value_max`, rollup.table, rollup.source, rollup.column))
+		}
+
+		queries = append(queries,
+			`INSERT INTO species_rollup (bucket, bucket_start, group_name, sensorid, name, count)
+			 SELECT $1, r.bucket_start, r.group_name, r.sensorid, r.name, SUM(r.count)
+			 FROM (
+			 	SELECT date_trunc($2, df.created_at) AS bucket_start, s.group_name, df.sensorid, df.name, df.count
+			 	FROM detected_fish df
+			 	JOIN sensor s ON s.id = df.sensorid
+			 	WHERE df.created_at >= $3 AND df.created_at < $4) r
+			 GROUP BY r.bucket_start, r.group_name, r.name, ROLLUP (r.sensorid)
+			 ON CONFLICT ON CONSTRAINT species_rollup_key DO UPDATE
+			 SET count = EXCLUDED.count`)
+	} else {
+		for _, rollup := range metricRollups {
+			queries = append(queries, fmt.Sprintf(
+				`INSERT INTO %[1]s (bucket, bucket_start, group_name, sensorid, value_count, value_sum, value_min, value_max)
+				 SELECT $1, date_trunc($2, bucket_start), group_name, sensorid, SUM(value_count), SUM(value_sum), MIN(value_min), MAX(value_max)
+				 FROM %[1]s
+				 WHERE bucket = $5 AND bucket_start >= $3 AND bucket_start < $4
+				 GROUP BY date_trunc($2, bucket_start), group_name, sensorid
+				 ON CONFLICT ON CONSTRAINT %[1]s_key DO UPDATE
+				 SET value_count = EXCLUDED.value_count, value_sum = EXCLUDED.value_sum,
+				     value_min = EXCLUDED.value_min, value_max = EXCLUDED.value_max`, rollup.table))
+		}
+
+		queries = append(queries,
+			`INSERT INTO species_rollup (bucket, bucket_start, group_name, sensorid, name, count)
+			 SELECT $1, date_trunc($2, bucket_start), group_name, sensorid, name, SUM(count)
+			 FROM species_rollup
+			 WHERE bucket = $5 AND bucket_start >= $3 AND bucket_start < $4
+			 GROUP BY date_trunc($2, bucket_start), group_name, sensorid, name
+			 ON CONFLICT ON CONSTRAINT species_rollup_key DO UPDATE
+			 SET count = EXCLUDED.count`)
+	}
+
+	for _, query := range queries {
+		args := []any{string(bucket), bucket.Unit(), from, till}
+		if source != "" {
+			args = append(args, string(source))
+		}
+
+		_, err = tx.Exec(ctx, query, args...)
+		if err != nil {
+			err = postgres.ErrExecQuery(err)
+			d.log.Error(err)
+			_ = tx.Rollback(ctx)
+			return err
+		}
+	}
+
+	watermarkQuery := `INSERT INTO rollup_watermark (bucket, aggregated_till) VALUES ($1, $2)
+					   ON CONFLICT (bucket) DO UPDATE SET aggregated_till = EXCLUDED.aggregated_till`
+
+	_, err = tx.Exec(ctx, watermarkQuery, string(bucket), till)
+	if err != nil {
+		err = postgres.ErrExecQuery(err)
+		d.log.Error(err)
+		_ = tx.Rollback(ctx)
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		err = postgres.ErrCommit(err)
+		d.log.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// GetRollupWatermark returns the time till which the bucket is aggregated, nil if it was never aggregated.
+func (d *Database) GetRollupWatermark(ctx context.Context, bucket domain.Bucket) (*time.Time, error) {
+	till, exist, err := d.getWatermark(ctx, d.DB, bucket, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exist {
+		return nil, nil
+	}
+
+	return &till, nil
+}
+
+func (d *Database) GetSensorAverageTemperatureRollup(ctx context.Context, inGroupID int, group string, bucket domain.Bucket, start, end string) (*float64, error) {
+	return d.getSensorAverageRollup(ctx, "temperature_rollup", inGroupID, group, bucket, start, end)
+}
+
+func (d *Database) GetSensorAverageTransparencyRollup(ctx context.Context, inGroupID int, group string, bucket domain.Bucket, start, end string) (*float64, error) {
+	return d.getSensorAverageRollup(ctx, "transparency_rollup", inGroupID, group, bucket, start, end)
+}
+
+func (d *Database) GetGroupAverageTransparencyRollup(ctx context.Context, groupName string, bucket domain.Bucket, start, end string) (*float64, error) {
+	query := `SELECT SUM(value_sum) / NULLIF(SUM(value_count), 0)
+			  FROM transparency_rollup
+			  WHERE group_name = $1
+			  AND sensorid IS NULL
+			  AND bucket = $2
+			  AND bucket_start >= $3 AND bucket_start < $4`
+
+	var transparency *float64
+
+	err := d.DB.QueryRow(ctx, query, groupName, string(bucket), start, end).Scan(&transparency)
+	if err != nil {
+		err = postgres.ErrScan(err)
+		d.log.Error(err)
+		return nil, err
+	}
+
+	return transparency, nil
+}
+
+func (d *Database) GetTopSpeciesRollup(ctx context.Context, groupName string, bucket domain.Bucket, start, end string, top int) ([]domain.DetectedFish, error) {
+	query := `SELECT name, SUM(count)::bigint AS total_count
+			  FROM species_rollup
+			  WHERE group_name = $1
+			  AND sensorid IS NULL
+			  AND bucket = $2
+			  AND bucket_start >= $3 AND bucket_start < $4
+			  GROUP BY name
+			  ORDER BY total_count DESC
+			  LIMIT $5`
+
+	rows, err := d.DB.Query(ctx, query, groupName, string(bucket), start, end, top)
+	if err != nil {
+		err = postgres.ErrDoQuery(err)
+		d.log.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fishes []domain.DetectedFish
+
+	for rows.Next() {
+		var fish domain.DetectedFish
+		err := rows.Scan(
+			&fish.Name,
+			&fish.Count,
+		)
+		if err != nil {
+			err = postgres.ErrScan(err)
+			d.log.Error(err)
+			return nil, err
+		}
+
+		fishes = append(fishes, fish)
+	}
+
+	return fishes, nil
+}
+
+func (d *Database) getSensorAverageRollup(ctx context.Context, table string, inGroupID int, group string, bucket domain.Bucket, start, end string) (*float64, error) {
+	query := fmt.Sprintf(`SELECT SUM(r.value_sum) / NULLIF(SUM(r.value_count), 0)
+			  FROM %s r
+			  JOIN sensor s ON r.sensorid = s.id
+			  WHERE s.group_name = $1
+			  AND s.in_group_id = $2
+			  AND r.bucket = $3
+			  AND r.bucket_start >= $4 AND r.bucket_start < $5`, table)
+
+	var average *float64
+
+	err := d.DB.QueryRow(ctx, query, group, inGroupID, string(bucket), start, end).Scan(&average)
+	if err != nil {
+		err = postgres.ErrScan(err)
+		d.log.Error(err)
+		return nil, err
+	}
+
+	return average, nil
+}
+
+// rewindRollups moves the watermarks back to the buckets containing t, so a measurement saved after its
+// buckets were aggregated is taken into account on the next aggregation.
+func (d *Database) rewindRollups(ctx context.Context, tx pgx.Tx, t time.Time) error {
+	buckets := make([]string, 0, len(domain.Buckets))
+	units := make([]string, 0, len(domain.Buckets))
+
+	for _, bucket := range domain.Buckets {
+		buckets = append(buckets, string(bucket))
+		units = append(units, bucket.Unit())
+	}
+
+	query := `UPDATE rollup_watermark w SET aggregated_till = date_trunc(b.unit, $1::timestamp)
+			  FROM unnest($2::text[], $3::text[]) AS b(bucket, unit)
+			  WHERE w.bucket = b.bucket AND w.aggregated_till > $1::timestamp`
+
+	_, err := tx.Exec(ctx, query, t, buckets, units)
+	if err != nil {
+		err = postgres.ErrExecQuery(err)
+		d.log.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func (d *Database) getWatermark(ctx context.Context, q querier, bucket domain.Bucket, lock bool) (time.Time, bool, error) {
+	query := "SELECT aggregated_till FROM rollup_watermark WHERE bucket = $1"
+	if lock {
+		query += " FOR UPDATE"
+	}
+
+	var till time.Time
+
+	err := q.QueryRow(ctx, query, string(bucket)).Scan(&till)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Unix(0, 0).UTC(), false, nil
+		}
+		err = postgres.ErrScan(err)
+		d.log.Error(err)
+		return time.Time{}, false, err
+	}
+
+	return till, true, nil
+}
@@ -3,9 +3,8 @@ package storage
 import (
 	"context"
 	"database/sql"
-	"errors"
-	"fmt"
-	"math/rand"
+	"math"
+	"time"
 
 	"github.com/PavelDonchenko/sensor-go/config"
 	"github.com/PavelDonchenko/sensor-go/internal/domain"
@@ -18,26 +17,71 @@ import (
 )
 
 type SensorPostgres interface {
-	SaveDetectedFish(ctx context.Context, fish domain.DetectedFish) (*domain.DetectedFish, error)
-	UpdateSensorData(ctx context.Context, sensor domain.Sensor) error
+	SaveMeasurement(ctx context.Context, measurement domain.Measurement) (*domain.Measurement, error)
+	SaveMeasurements(ctx context.Context, measurements []domain.Measurement) ([]domain.Measurement, error)
+	CopyMeasurements(ctx context.Context, measurements []domain.Measurement) ([]domain.Measurement, error)
 	GetAllSensors(ctx context.Context) ([]domain.Sensor, error)
 	GetTransparency(ctx context.Context, groupName string) (float64, error)
 	GetTemperature(ctx context.Context, groupName string) (float64, error)
 	GetSpecies(ctx context.Context, groupName string) ([]domain.DetectedFish, error)
 	GetTopSpecies(ctx context.Context, groupName, start, end string, top int) ([]domain.DetectedFish, error)
-	GetRegionTemperature(ctx context.Context, region domain.Region, flag string) (float64, error)
-	SaveTemperature(ctx context.Context, t float64, uuid uuid.UUID) error
+	GetAreaSensors(ctx context.Context, area domain.Area) ([]domain.Sensor, error)
+	GetNearestSensors(ctx context.Context, point domain.Coordinates, k int) ([]domain.SensorDistance, error)
+	CreateRegion(ctx context.Context, region domain.CreateRegion) (*domain.NamedRegion, error)
+	GetRegions(ctx context.Context) ([]domain.NamedRegion, error)
+	GetRegion(ctx context.Context, name string) (*domain.NamedRegion, error)
+	DeleteRegion(ctx context.Context, name string) error
+	CreateAlertRule(ctx context.Context, rule domain.AlertRule) (*domain.AlertRule, error)
+	GetAlertRules(ctx context.Context) ([]domain.AlertRule, error)
+	DeleteAlertRule(ctx context.Context, id int) error
+	GetAlerts(ctx context.Context, query domain.AlertQuery) ([]domain.Alert, error)
+	FireAlert(ctx context.Context, alert domain.Alert) (*domain.Alert, error)
+	ResolveAlert(ctx context.Context, id int64, value float64, resolvedAt time.Time) error
+	CreateWebhook(ctx context.Context, webhook domain.CreateWebhook) (*domain.Webhook, error)
+	GetWebhooks(ctx context.Context) ([]domain.Webhook, error)
+	GetWebhook(ctx context.Context, id int) (*domain.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int) error
+	SaveWebhookDelivery(ctx context.Context, delivery domain.WebhookDelivery) error
+	GetWebhookDeliveries(ctx context.Context, webhookID, limit int) ([]domain.WebhookDelivery, error)
+	SaveDeadLetter(ctx context.Context, letter domain.DeadLetter) error
+	GetDeadLetters(ctx context.Context, webhookID, limit int) ([]domain.DeadLetter, error)
+	SaveAnomaly(ctx context.Context, anomaly domain.Anomaly) (*domain.Anomaly, error)
+	GetAnomalies(ctx context.Context, query domain.AnomalyQuery) ([]domain.Anomaly, error)
+	GetSensorsStat(ctx context.Context, ids []uuid.UUID, metric domain.Metric, agg domain.Aggregation, percentile float64, start, end string) (*float64, error)
+	GetSensorsSpecies(ctx context.Context, ids []uuid.UUID) ([]domain.DetectedFish, error)
+	GetSensorsTopSpecies(ctx context.Context, ids []uuid.UUID, start, end string, top int) ([]domain.DetectedFish, error)
 	GetSensorAverageTemperature(ctx context.Context, inGroupID int, group, start, end string) (*float64, error)
+	AggregateRollups(ctx context.Context, bucket domain.Bucket, now time.Time) error
+	GetRollupWatermark(ctx context.Context, bucket domain.Bucket) (*time.Time, error)
+	GetSensorAverageTemperatureRollup(ctx context.Context, inGroupID int, group string, bucket domain.Bucket, start, end string) (*float64, error)
+	GetSensorAverageTransparency(ctx context.Context, inGroupID int, group, start, end string) (*float64, error)
+	GetGroupAverageTransparency(ctx context.Context, groupName, start, end string) (*float64, error)
+	GetSensorAverageTransparencyRollup(ctx context.Context, inGroupID int, group string, bucket domain.Bucket, start, end string) (*float64, error)
+	GetGroupAverageTransparencyRollup(ctx context.Context, groupName string, bucket domain.Bucket, start, end string) (*float64, error)
+	GetTopSpeciesRollup(ctx context.Context, groupName string, bucket domain.Bucket, start, end string, top int) ([]domain.DetectedFish, error)
+	GetSeries(ctx context.Context, query domain.SeriesQuery) ([]domain.SeriesPoint, error)
+	GetSeriesRollup(ctx context.Context, query domain.SeriesQuery) ([]domain.SeriesPoint, error)
+	CreateGroup(ctx context.Context, name string) (*domain.SensorGroup, error)
+	GetGroups(ctx context.Context) ([]domain.SensorGroup, error)
+	GetGroup(ctx context.Context, name string) (*domain.SensorGroup, error)
+	RenameGroup(ctx context.Context, name, newName string) (*domain.SensorGroup, error)
+	DeleteGroup(ctx context.Context, name string) error
+	GetGroupSensors(ctx context.Context, groupName string) ([]domain.Sensor, error)
+	GetSensor(ctx context.Context, groupName string, inGroupID int) (*domain.Sensor, error)
+	CreateSensor(ctx context.Context, sensor domain.Sensor) (*domain.Sensor, error)
+	UpdateSensor(ctx context.Context, sensor domain.Sensor) (*domain.Sensor, error)
+	DeleteSensor(ctx context.Context, id uuid.UUID) error
 }
 
 type Database struct {
-	DB  *pgxpool.Pool
-	Cfg config.Config
-	log logging.Logger
+	DB    *pgxpool.Pool
+	Cfg   config.Config
+	Clock generations.Clock
+	log   logging.Logger
 }
 
 func NewDatabase(DB *pgxpool.Pool, cfg config.Config, log logging.Logger) *Database {
-	return &Database{DB: DB, Cfg: cfg, log: log}
+	return &Database{DB: DB, Cfg: cfg, Clock: generations.RealClock{}, log: log}
 }
 
 func (d *Database) CreateSensorGroup(ctx context.Context, name string, id int) error {
@@ -60,10 +104,12 @@ func (d *Database) CreateSensorsForGroup(ctx context.Context, group []string, se
 		return err
 	}
 
+	r := generations.NewSeeds(d.Cfg.Simulation.Seed).Rand("sensors")
+
 	for groupID, groupName := range group {
 		for i := 1; i <= sensorCount; i++ {
 			// Generate random coordinates within the group's range
-			coordinates := generations.GenerateCoordinates(groupID)
+			coordinates := generations.GenerateCoordinates(r, groupID)
 
 			codename := domain.Codename{
 				Name:          groupName,
@@ -73,9 +119,9 @@ func (d *Database) CreateSensorsForGroup(ctx context.Context, group []string, se
 			sensor := &domain.Sensor{
 				Codename:       codename,
 				Coordinates:    coordinates,
-				Transparency:   rand.Intn(101),
-				Temperature:    generations.GenerateTemperature(coordinates.Z),
-				DataOutputRate: generations.GenerateRandomInt(), // Random data output rate between 5, 10, 15, 20, 25
+				Transparency:   r.Intn(101),
+				Temperature:    generations.GenerateTemperature(r, d.Cfg.TemperatureModel(groupName), coordinates.Z, d.Clock.Now()),
+				DataOutputRate: generations.GenerateRandomInt(r), // Random data output rate between 5, 10, 15, 20, 25
 			}
 
 			query := "INSERT INTO sensor (group_id, group_name, in_group_id, data_output_rate, x, y, z, transparency, temperature) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)"
@@ -94,6 +140,10 @@ func (d *Database) CreateSensorsForGroup(ctx context.Context, group []string, se
 							SET sensors = (
 							SELECT ARRAY_AGG(id) 
 							FROM sensor 
+							WHERE sensor.group_name = sensor_group.name),
+							last_in_group_id = (
+							SELECT MAX(in_group_id)
+							FROM sensor
 							WHERE sensor.group_name = sensor_group.name)
                     		WHERE EXISTS (SELECT 1 FROM sensor WHERE sensor.group_name = sensor_group.name)`
 
@@ -115,39 +165,252 @@ func (d *Database) CreateSensorsForGroup(ctx context.Context, group []string, se
 	return nil
 }
 
-func (d *Database) SaveTemperature(ctx context.Context, t float64, uuid uuid.UUID) error {
-	query := `INSERT INTO temperature (degrees, sensorid) VALUES ($1, $2)`
+func (d *Database) SaveMeasurement(ctx context.Context, measurement domain.Measurement) (*domain.Measurement, error) {
+	measurements, err := d.SaveMeasurements(ctx, []domain.Measurement{measurement})
+	if err != nil {
+		return nil, err
+	}
+
+	return &measurements[0], nil
+}
 
-	_, err := d.DB.Exec(ctx, query, t, uuid)
+// SaveMeasurements writes a batch of measurements in one transaction.
+func (d *Database) SaveMeasurements(ctx context.Context, measurements []domain.Measurement) ([]domain.Measurement, error) {
+	tx, err := d.DB.Begin(ctx)
 	if err != nil {
-		err = postgres.ErrScan(err)
+		err = postgres.ErrCreateTx(err)
 		d.log.Error(err)
-		return err
+		return nil, err
 	}
-	return nil
+
+	saved := make([]domain.Measurement, 0, len(measurements))
+
+	for _, measurement := range measurements {
+		err = d.saveMeasurement(ctx, tx, &measurement)
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return nil, err
+		}
+
+		saved = append(saved, measurement)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		err = postgres.ErrCommit(err)
+		d.log.Error(err)
+		return nil, err
+	}
+
+	return saved, nil
 }
 
-func (d *Database) SaveDetectedFish(ctx context.Context, fish domain.DetectedFish) (*domain.DetectedFish, error) {
-	var detectedFish domain.DetectedFish
-	query := "INSERT INTO detected_fish (name, count, sensorid) VALUES ($1, $2, $3) RETURNING id, name, count"
-	err := d.DB.QueryRow(ctx, query, fish.Name, fish.Count, fish.SensorID).Scan(&detectedFish.ID, &detectedFish.Name, &detectedFish.Count)
+// CopyMeasurements bulk inserts the timestamped measurements in one transaction,
+// the measurements and the fish without an id get a new one.
+func (d *Database) CopyMeasurements(ctx context.Context, measurements []domain.Measurement) ([]domain.Measurement, error) {
+	if len(measurements) == 0 {
+		return nil, nil
+	}
+
+	saved := make([]domain.Measurement, 0, len(measurements))
+	measurementRows := make([][]any, 0, len(measurements))
+	fishRows := make([][]any, 0)
+	latest := make(map[uuid.UUID]domain.Measurement)
+	earliest := measurements[0].CreatedAt
+
+	for _, measurement := range measurements {
+		if measurement.ID == uuid.Nil {
+			measurement.ID = uuid.New()
+		}
+		measurement.DetectedFish = append([]domain.DetectedFish(nil), measurement.DetectedFish...)
+
+		var fault *string
+		if measurement.Fault != "" {
+			fault = &measurement.Fault
+		}
+
+		measurementRows = append(measurementRows, []any{measurement.ID, measurement.SensorID, savedTemperature(measurement),
+			measurement.Transparency, measurement.CreatedAt, fault, measurement.AnomalyScore})
+
+		for i := range measurement.DetectedFish {
+			fish := &measurement.DetectedFish[i]
+			if fish.ID == uuid.Nil {
+				fish.ID = uuid.New()
+			}
+			fish.SensorID = measurement.SensorID
+			fish.MeasurementID = measurement.ID
+
+			fishRows = append(fishRows, []any{fish.ID, fish.Name, fish.Count, measurement.SensorID, measurement.ID,
+				measurement.CreatedAt})
+		}
+
+		saved = append(saved, measurement)
+
+		if last, ok := latest[measurement.SensorID]; !ok || measurement.CreatedAt.After(last.CreatedAt) {
+			latest[measurement.SensorID] = measurement
+		}
+
+		if measurement.CreatedAt.Before(earliest) {
+			earliest = measurement.CreatedAt
+		}
+	}
+
+	tx, err := d.DB.Begin(ctx)
 	if err != nil {
-		err = postgres.ErrScan(err)
+		err = postgres.ErrCreateTx(err)
+		d.log.Error(err)
+		return nil, err
+	}
+
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{"measurement"},
+		[]string{"id", "sensorid", "temperature", "transparency", "created_at", "fault", "anomaly_score"}, pgx.CopyFromRows(measurementRows))
+	if err != nil {
+		err = postgres.ErrExecQuery(err)
 		d.log.Error(err)
+		_ = tx.Rollback(ctx)
 		return nil, err
 	}
-	return &detectedFish, nil
+
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{"detected_fish"},
+		[]string{"id", "name", "count", "sensorid", "measurementid", "created_at"}, pgx.CopyFromRows(fishRows))
+	if err != nil {
+		err = postgres.ErrExecQuery(err)
+		d.log.Error(err)
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+
+	// an older measurement must not overwrite the latest sensor data
+	sensorIDs := make([]string, 0, len(latest))
+	transparencies := make([]int, 0, len(latest))
+	temperatures := make([]*float64, 0, len(latest))
+	measurementIDs := make([]string, 0, len(latest))
+	createdAts := make([]time.Time, 0, len(latest))
+
+	for _, measurement := range latest {
+		sensorIDs = append(sensorIDs, measurement.SensorID.String())
+		transparencies = append(transparencies, measurement.Transparency)
+		temperatures = append(temperatures, savedTemperature(measurement))
+		measurementIDs = append(measurementIDs, measurement.ID.String())
+		createdAts = append(createdAts, measurement.CreatedAt)
+	}
+
+	sensorQuery := `UPDATE sensor s SET transparency = l.transparency, temperature = COALESCE(l.temperature, s.temperature),
+					measurementid = l.measurementid::uuid, updated_at = l.created_at, measured_at = l.created_at
+					FROM unnest($1::text[], $2::int[], $3::float8[], $4::text[], $5::timestamp[])
+						AS l(sensorid, transparency, temperature, measurementid, created_at)
+					WHERE s.id = l.sensorid::uuid AND (s.measured_at IS NULL OR s.measured_at <= l.created_at)`
+
+	_, err = tx.Exec(ctx, sensorQuery, sensorIDs, transparencies, temperatures, measurementIDs, createdAts)
+	if err != nil {
+		err = postgres.ErrExecQuery(err)
+		d.log.Error(err)
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+
+	err = d.rewindRollups(ctx, tx, earliest)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		err = postgres.ErrCommit(err)
+		d.log.Error(err)
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+// savedTemperature returns NULL for the NaN of an invalid reading.
+func savedTemperature(measurement domain.Measurement) *float64 {
+	if math.IsNaN(measurement.Temperature) {
+		return nil
+	}
+
+	return &measurement.Temperature
 }
 
-func (d *Database) UpdateSensorData(ctx context.Context, sensor domain.Sensor) error {
-	var fishesID []uuid.UUID
-	for _, fish := range sensor.DetectedFish {
-		fishesID = append(fishesID, fish.ID)
+func (d *Database) saveMeasurement(ctx context.Context, tx pgx.Tx, measurement *domain.Measurement) error {
+	var createdAt *time.Time
+	if !measurement.CreatedAt.IsZero() {
+		createdAt = &measurement.CreatedAt
 	}
 
-	sensorQuery := "UPDATE sensor SET transparency = $1, temperature = $2, fishes = $3 WHERE id = $4"
+	measurementQuery := `INSERT INTO measurement (sensorid, temperature, transparency, created_at, fault, anomaly_score)
+						 VALUES ($1, $2, $3, COALESCE($4, LOCALTIMESTAMP), NULLIF($5, ''), $6)
+						 RETURNING id, created_at`
 
-	ct, err := d.DB.Exec(ctx, sensorQuery, sensor.Transparency, sensor.Temperature, fishesID, sensor.ID)
+	err := tx.QueryRow(ctx, measurementQuery, measurement.SensorID, savedTemperature(*measurement), measurement.Transparency, createdAt,
+		measurement.Fault, measurement.AnomalyScore).
+		Scan(&measurement.ID, &measurement.CreatedAt)
+	if err != nil {
+		err = postgres.ErrScan(err)
+		d.log.Error(err)
+		return err
+	}
+
+	if len(measurement.DetectedFish) > 0 {
+		names := make([]string, 0, len(measurement.DetectedFish))
+		counts := make([]int, 0, len(measurement.DetectedFish))
+
+		for _, fish := range measurement.DetectedFish {
+			names = append(names, fish.Name)
+			counts = append(counts, fish.Count)
+		}
+
+		fishQuery := `INSERT INTO detected_fish (name, count, sensorid, measurementid, created_at)
+					  SELECT f.name, f.count, $3::uuid, $4::uuid, $5::timestamp
+					  FROM unnest($1::text[], $2::int[]) AS f(name, count)
+					  RETURNING id`
+
+		rows, err := tx.Query(ctx, fishQuery, names, counts, measurement.SensorID, measurement.ID, measurement.CreatedAt)
+		if err != nil {
+			err = postgres.ErrDoQuery(err)
+			d.log.Error(err)
+			return err
+		}
+
+		fishes := make([]domain.DetectedFish, len(measurement.DetectedFish))
+		copy(fishes, measurement.DetectedFish)
+
+		for i := 0; rows.Next(); i++ {
+			err = rows.Scan(&fishes[i].ID)
+			if err != nil {
+				rows.Close()
+				err = postgres.ErrScan(err)
+				d.log.Error(err)
+				return err
+			}
+
+			fishes[i].SensorID = measurement.SensorID
+			fishes[i].MeasurementID = measurement.ID
+		}
+		rows.Close()
+
+		if err = rows.Err(); err != nil {
+			err = postgres.ErrDoQuery(err)
+			d.log.Error(err)
+			return err
+		}
+
+		measurement.DetectedFish = fishes
+	}
+
+	// an older measurement delivered late must not overwrite the latest sensor data
+	sensorQuery := `UPDATE sensor SET
+						transparency = CASE WHEN measured_at > $4 THEN transparency ELSE $1 END,
+						temperature = CASE WHEN measured_at > $4 THEN temperature ELSE COALESCE($2, temperature) END,
+						measurementid = CASE WHEN measured_at > $4 THEN measurementid ELSE $3 END,
+						updated_at = CASE WHEN measured_at > $4 THEN updated_at ELSE $4 END,
+						measured_at = GREATEST(measured_at, $4)
+					WHERE id = $5`
+
+	ct, err := tx.Exec(ctx, sensorQuery, measurement.Transparency, savedTemperature(*measurement), measurement.ID,
+		measurement.CreatedAt, measurement.SensorID)
 	if err != nil {
 		err = postgres.ErrExecQuery(err)
 		d.log.Error(err)
@@ -155,21 +418,20 @@ func (d *Database) UpdateSensorData(ctx context.Context, sensor domain.Sensor) e
 	}
 
 	if ct.RowsAffected() == 0 {
-		return errors.New("sensor not found")
+		return ErrSensorNotFound
 	}
 
-	//for _, fish := range sensor.DetectedFish {
-	//	_, err = d.SaveDetectedFish(ctx, fish)
-	//	if err != nil {
-	//		return fmt.Errorf("error save fetected fish for sensor: %s, err: %v", sensor.ID, err)
-	//	}
-	//}
+	// an accelerated simulation may have aggregated the buckets of the measurement already
+	err = d.rewindRollups(ctx, tx, measurement.CreatedAt)
+	if err != nil {
+		return err
+	}
 
 	return nil
 }
 
 func (d *Database) GetAllSensors(ctx context.Context) ([]domain.Sensor, error) {
-	query := "SELECT id, temperature, transparency, created_at, in_group_id, group_name, data_output_rate, x, y, z FROM sensor"
+	query := "SELECT " + sensorColumns + " FROM sensor"
 
 	rows, err := d.DB.Query(ctx, query)
 	if err != nil {
@@ -183,18 +445,7 @@ func (d *Database) GetAllSensors(ctx context.Context) ([]domain.Sensor, error) {
 
 	for rows.Next() {
 		var sensor domain.Sensor
-		err := rows.Scan(
-			&sensor.ID,
-			&sensor.Temperature,
-			&sensor.Transparency,
-			&sensor.CreatedAt,
-			&sensor.Codename.SensorGroupID,
-			&sensor.Codename.Name,
-			&sensor.DataOutputRate,
-			&sensor.Coordinates.X,
-			&sensor.Coordinates.Y,
-			&sensor.Coordinates.Z,
-		)
+		err := scanSensor(rows, &sensor)
 		if err != nil {
 			err = postgres.ErrScan(err)
 			d.log.Error(err)
@@ -240,10 +491,7 @@ func (d *Database) GetTemperature(ctx context.Context, groupName string) (float6
 func (d *Database) GetSpecies(ctx context.Context, groupName string) ([]domain.DetectedFish, error) {
 	query := `SELECT df.name, SUM(df.count) AS total_count 
 		 FROM detected_fish df 
-         WHERE df.id IN (
-		 SELECT DISTINCT df.id 
-		 FROM detected_fish df 
-		 JOIN sensor s ON df.id = ANY(s.fishes) AND s.group_name = $1)
+		 JOIN sensor s ON df.measurementid = s.measurementid AND s.group_name = $1
 		 GROUP BY df.name`
 
 	var fishes []domain.DetectedFish
@@ -254,6 +502,7 @@ func (d *Database) GetSpecies(ctx context.Context, groupName string) ([]domain.D
 		d.log.Error(err)
 		return nil, err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		var fish domain.DetectedFish
@@ -279,17 +528,14 @@ func (d *Database) GetTopSpecies(ctx context.Context, groupName, start, end stri
 	if start == "" {
 		query = `SELECT df.name, SUM(df.count) AS total_count 
 		 FROM detected_fish df 
-         WHERE df.id IN (
-		 SELECT DISTINCT df.id 
-		 FROM detected_fish df 
-		 JOIN sensor s ON df.id = ANY(s.fishes) AND s.group_name = $1)
+		 JOIN sensor s ON df.measurementid = s.measurementid AND s.group_name = $1
 		 GROUP BY df.name
 		 ORDER BY total_count DESC
 		 LIMIT $2`
 	} else {
 		query = `SELECT df.name, SUM(df.count) AS total_count  FROM detected_fish as df
          JOIN sensor s on s.id = df.sensorid
-         WHERE group_name = $1 AND s.created_at between $3 AND $4
+         WHERE s.group_name = $1 AND df.created_at >= $3 AND df.created_at < $4
          GROUP BY df.name
          ORDER BY total_count DESC
          LIMIT $2`
@@ -310,6 +556,7 @@ func (d *Database) GetTopSpecies(ctx context.Context, groupName, start, end stri
 		d.log.Error(err)
 		return nil, err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		var fish domain.DetectedFish
@@ -329,46 +576,64 @@ func (d *Database) GetTopSpecies(ctx context.Context, groupName, start, end stri
 	return fishes, nil
 }
 
-func (d *Database) GetRegionTemperature(ctx context.Context, region domain.Region, flag string) (float64, error) {
-	query := fmt.Sprintf(`SELECT %s(temperature) 
-								 FROM sensor 
-								 WHERE x >= $1 
-								 AND x <= $2 
-								 AND y >= $3 
-								 AND y <= $4 
-								 AND z >= $5 
-								 AND z <= $6`, flag)
-
-	var temperature float64
-	err := d.DB.QueryRow(ctx, query, region.XMin, region.XMax, region.XMin, region.XMax, region.XMin, region.XMax).Scan(&temperature)
+func (d *Database) GetSensorAverageTemperature(ctx context.Context, inGroupID int, group, start, end string) (*float64, error) {
+	query := `SELECT AVG(m.temperature)
+			  FROM measurement as m
+              JOIN sensor s ON m.sensorid = s.id
+              WHERE s.group_name = $1
+              AND s.in_group_id = $2
+			  AND m.created_at >= $3 AND m.created_at < $4`
+
+	var temperature *float64
+
+	err := d.DB.QueryRow(ctx, query, group, inGroupID, start, end).Scan(&temperature)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
 		err = postgres.ErrScan(err)
 		d.log.Error(err)
-		return 0, err
+		return nil, err
 	}
 
 	return temperature, nil
 }
 
-func (d *Database) GetSensorAverageTemperature(ctx context.Context, inGroupID int, group, start, end string) (*float64, error) {
-	query := `SELECT AVG(degrees)
-			  FROM temperature as t
-              JOIN sensor s ON t.sensorid = s.id
+func (d *Database) GetSensorAverageTransparency(ctx context.Context, inGroupID int, group, start, end string) (*float64, error) {
+	query := `SELECT AVG(m.transparency)
+			  FROM measurement as m
+              JOIN sensor s ON m.sensorid = s.id
               WHERE s.group_name = $1
               AND s.in_group_id = $2
-			  AND t.created_at between $3 AND $4`
+			  AND m.created_at >= $3 AND m.created_at < $4`
 
-	var temperature *float64
+	var transparency *float64
 
-	err := d.DB.QueryRow(ctx, query, group, inGroupID, start, end).Scan(&temperature)
+	err := d.DB.QueryRow(ctx, query, group, inGroupID, start, end).Scan(&transparency)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		}
 		err = postgres.ErrScan(err)
 		d.log.Error(err)
 		return nil, err
 	}
 
-	return temperature, nil
+	return transparency, nil
+}
+
+func (d *Database) GetGroupAverageTransparency(ctx context.Context, groupName, start, end string) (*float64, error) {
+	query := `SELECT AVG(m.transparency)
+			  FROM measurement as m
+              JOIN sensor s ON m.sensorid = s.id
+              WHERE s.group_name = $1
+			  AND m.created_at >= $2 AND m.created_at < $3`
+
+	var transparency *float64
+
+	err := d.DB.QueryRow(ctx, query, groupName, start, end).Scan(&transparency)
+	if err != nil {
+		err = postgres.ErrScan(err)
+		d.log.Error(err)
+		return nil, err
+	}
+
+	return transparency, nil
 }
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/pkg/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// metricColumns maps the metrics to the measurement columns and the rollup tables they are stored in.
+var metricColumns = map[domain.Metric]struct {
+	column string
+	rollup string
+}{
+	domain.MetricTemperature:  {column: "temperature", rollup: "temperature_rollup"},
+	domain.MetricTransparency: {column: "transparency", rollup: "transparency_rollup"},
+}
+
+// rawAggregations are the SQL aggregates of the raw metric values, %s is the value column.
+var rawAggregations = map[domain.Aggregation]string{
+	domain.AggregationAvg: "AVG(%s)::double precision",
+	domain.AggregationMin: "MIN(%s)::double precision",
+	domain.AggregationMax: "MAX(%s)::double precision",
+	domain.AggregationP95: "percentile_cont(0.95) WITHIN GROUP (ORDER BY %s)",
+}
+
+// rollupAggregations are the SQL aggregates of the rollup rows, a percentile can not be built from them.
+var rollupAggregations = map[domain.Aggregation]string{
+	domain.AggregationAvg: "SUM(value_sum) / NULLIF(SUM(value_count), 0)",
+	domain.AggregationMin: "MIN(value_min)",
+	domain.AggregationMax: "MAX(value_max)",
+}
+
+// GetSeries computes the series buckets from the raw measurements.
+func (d *Database) GetSeries(ctx context.Context, q domain.SeriesQuery) ([]domain.SeriesPoint, error) {
+	metric, ok := metricColumns[q.Metric]
+	if !ok {
+		return nil, fmt.Errorf("unknown metric %q", q.Metric)
+	}
+
+	aggregation, ok := rawAggregations[q.Aggregation]
+	if !ok {
+		return nil, fmt.Errorf("unknown aggregation %q", q.Aggregation)
+	}
+
+	column := "m." + metric.column
+
+	filter, arg := "s.group_name = $4", any(q.GroupName)
+	switch {
+	case q.SensorID != uuid.Nil:
+		filter, arg = "m.sensorid = $4", q.SensorID
+	case len(q.SensorIDs) > 0:
+		filter, arg = "m.sensorid = ANY($4)", q.SensorIDs
+	}
+
+	query := fmt.Sprintf(`SELECT date_trunc($1, m.created_at) AS bucket_start, %[1]s, COUNT(%[2]s)
+			  FROM measurement m
+			  JOIN sensor s ON s.id = m.sensorid
+			  WHERE m.created_at >= $2 AND m.created_at < $3
+			  AND %[2]s IS NOT NULL
+			  AND %[3]s
+			  GROUP BY bucket_start
+			  ORDER BY bucket_start`, fmt.Sprintf(aggregation, column), column, filter)
+
+	rows, err := d.DB.Query(ctx, query, q.Bucket.Unit(), q.From, q.Till, arg)
+	if err != nil {
+		err = postgres.ErrDoQuery(err)
+		d.log.Error(err)
+		return nil, err
+	}
+
+	return d.scanSeries(rows)
+}
+
+// GetSeriesRollup reads the series buckets from the rollup of the query bucket, the period must be aggregated already.
+func (d *Database) GetSeriesRollup(ctx context.Context, q domain.SeriesQuery) ([]domain.SeriesPoint, error) {
+	metric, ok := metricColumns[q.Metric]
+	if !ok {
+		return nil, fmt.Errorf("unknown metric %q", q.Metric)
+	}
+
+	aggregation, ok := rollupAggregations[q.Aggregation]
+	if !ok {
+		return nil, fmt.Errorf("aggregation %q is not available in rollups", q.Aggregation)
+	}
+
+	filter, arg := "group_name = $4 AND sensorid IS NULL", any(q.GroupName)
+	if q.SensorID != uuid.Nil {
+		filter, arg = "sensorid = $4", q.SensorID
+	}
+
+	query := fmt.Sprintf(`SELECT bucket_start, %s, SUM(value_count)::bigint
+			  FROM %s
+			  WHERE bucket = $1
+			  AND bucket_start >= $2 AND bucket_start < $3
+			  AND %s
+			  GROUP BY bucket_start
+			  ORDER BY bucket_start`, aggregation, metric.rollup, filter)
+
+	rows, err := d.DB.Query(ctx, query, string(q.Bucket), q.From, q.Till, arg)
+	if err != nil {
+		err = postgres.ErrDoQuery(err)
+		d.log.Error(err)
+		return nil, err
+	}
+
+	return d.scanSeries(rows)
+}
+
+func (d *Database) scanSeries(rows pgx.Rows) ([]domain.SeriesPoint, error) {
+	defer rows.Close()
+
+	points := make([]domain.SeriesPoint, 0)
+
+	for rows.Next() {
+		var point domain.SeriesPoint
+		err := rows.Scan(
+			&point.BucketStart,
+			&point.Value,
+			&point.Count,
+		)
+		if err != nil {
+			err = postgres.ErrScan(err)
+			d.log.Error(err)
+			return nil, err
+		}
+
+		points = append(points, point)
+	}
+
+	if err := rows.Err(); err != nil {
+		err = postgres.ErrDoQuery(err)
+		d.log.Error(err)
+		return nil, err
+	}
+
+	return points, nil
+}
@@ -0,0 +1,213 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/pkg/postgres"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+const webhookColumns = `id, url, secret, events, created_at`
+
+func (d *Database) CreateWebhook(ctx context.Context, webhook domain.CreateWebhook) (*domain.Webhook, error) {
+	query := `INSERT INTO webhook (url, secret, events, created_at)
+			  VALUES ($1, $2, $3, LOCALTIMESTAMP)
+			  RETURNING ` + webhookColumns
+
+	events := make([]string, 0, len(webhook.Events))
+	for _, event := range webhook.Events {
+		events = append(events, string(event))
+	}
+
+	created, err := scanWebhook(d.DB.QueryRow(ctx, query, webhook.URL, webhook.Secret, events))
+	if err != nil {
+		err = postgres.ErrScan(err)
+		d.log.Error(err)
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (d *Database) GetWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	query := "SELECT " + webhookColumns + " FROM webhook ORDER BY id"
+
+	rows, err := d.DB.Query(ctx, query)
+	if err != nil {
+		err = postgres.ErrDoQuery(err)
+		d.log.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := make([]domain.Webhook, 0)
+
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			err = postgres.ErrScan(err)
+			d.log.Error(err)
+			return nil, err
+		}
+
+		webhooks = append(webhooks, *webhook)
+	}
+
+	return webhooks, nil
+}
+
+func (d *Database) GetWebhook(ctx context.Context, id int) (*domain.Webhook, error) {
+	query := "SELECT " + webhookColumns + " FROM webhook WHERE id = $1"
+
+	webhook, err := scanWebhook(d.DB.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrWebhookNotFound
+		}
+		err = postgres.ErrScan(err)
+		d.log.Error(err)
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+func (d *Database) DeleteWebhook(ctx context.Context, id int) error {
+	tag, err := d.DB.Exec(ctx, "DELETE FROM webhook WHERE id = $1", id)
+	if err != nil {
+		err = postgres.ErrExecQuery(err)
+		d.log.Error(err)
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (d *Database) SaveWebhookDelivery(ctx context.Context, delivery domain.WebhookDelivery) error {
+	query := `INSERT INTO webhook_delivery (webhook_id, event_id, event_type, attempt, status_code, error, success,
+											duration_ms, created_at)
+			  VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8, LOCALTIMESTAMP)`
+
+	_, err := d.DB.Exec(ctx, query, delivery.WebhookID, delivery.EventID, delivery.EventType, delivery.Attempt,
+		delivery.StatusCode, delivery.Error, delivery.Success, delivery.DurationMs)
+	if err != nil {
+		err = postgres.ErrExecQuery(err)
+		d.log.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// GetWebhookDeliveries returns the delivery attempts of a webhook, the latest first.
+func (d *Database) GetWebhookDeliveries(ctx context.Context, webhookID, limit int) ([]domain.WebhookDelivery, error) {
+	query := `SELECT id, webhook_id, event_id, event_type, attempt, status_code, COALESCE(error, ''), success,
+					 duration_ms, created_at
+			  FROM webhook_delivery
+			  WHERE webhook_id = $1
+			  ORDER BY created_at DESC, id DESC
+			  LIMIT $2`
+
+	rows, err := d.DB.Query(ctx, query, webhookID, limit)
+	if err != nil {
+		err = postgres.ErrDoQuery(err)
+		d.log.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]domain.WebhookDelivery, 0)
+
+	for rows.Next() {
+		var delivery domain.WebhookDelivery
+
+		err = rows.Scan(&delivery.ID, &delivery.WebhookID, &delivery.EventID, &delivery.EventType, &delivery.Attempt,
+			&delivery.StatusCode, &delivery.Error, &delivery.Success, &delivery.DurationMs, &delivery.CreatedAt)
+		if err != nil {
+			err = postgres.ErrScan(err)
+			d.log.Error(err)
+			return nil, err
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, nil
+}
+
+func (d *Database) SaveDeadLetter(ctx context.Context, letter domain.DeadLetter) error {
+	query := `INSERT INTO webhook_dead_letter (webhook_id, event_id, event_type, payload, attempts, error, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, LOCALTIMESTAMP)`
+
+	_, err := d.DB.Exec(ctx, query, letter.WebhookID, letter.EventID, letter.EventType, []byte(letter.Payload),
+		letter.Attempts, letter.Error)
+	if err != nil {
+		err = postgres.ErrExecQuery(err)
+		d.log.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// GetDeadLetters returns the events which could not be delivered to a webhook, the latest first.
+func (d *Database) GetDeadLetters(ctx context.Context, webhookID, limit int) ([]domain.DeadLetter, error) {
+	query := `SELECT id, webhook_id, event_id, event_type, payload, attempts, error, created_at
+			  FROM webhook_dead_letter
+			  WHERE webhook_id = $1
+			  ORDER BY created_at DESC, id DESC
+			  LIMIT $2`
+
+	rows, err := d.DB.Query(ctx, query, webhookID, limit)
+	if err != nil {
+		err = postgres.ErrDoQuery(err)
+		d.log.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	letters := make([]domain.DeadLetter, 0)
+
+	for rows.Next() {
+		var letter domain.DeadLetter
+		var payload []byte
+
+		err = rows.Scan(&letter.ID, &letter.WebhookID, &letter.EventID, &letter.EventType, &payload, &letter.Attempts,
+			&letter.Error, &letter.CreatedAt)
+		if err != nil {
+			err = postgres.ErrScan(err)
+			d.log.Error(err)
+			return nil, err
+		}
+
+		letter.Payload = payload
+		letters = append(letters, letter)
+	}
+
+	return letters, nil
+}
+
+func scanWebhook(row pgx.Row) (*domain.Webhook, error) {
+	var webhook domain.Webhook
+	var events []string
+
+	err := row.Scan(&webhook.ID, &webhook.URL, &webhook.Secret, &events, &webhook.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.Events = make([]domain.EventType, 0, len(events))
+	for _, event := range events {
+		webhook.Events = append(webhook.Events, domain.EventType(event))
+	}
+
+	return &webhook, nil
+}
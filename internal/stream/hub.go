@@ -0,0 +1,163 @@
+package stream
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+)
+
+// Publisher receives every new reading and the other service events, Hub implements it.
+type Publisher interface {
+	Publish(reading domain.Reading)
+	PublishEvent(event domain.Event)
+}
+
+// Filter selects the readings a subscriber gets, empty fields match every reading.
+type Filter struct {
+	GroupName string
+	Codename  string
+	Area      *domain.Area
+}
+
+// Matches reports whether the reading passes the filter.
+func (f Filter) Matches(reading domain.Reading) bool {
+	if f.GroupName != "" && f.GroupName != reading.GroupName {
+		return false
+	}
+
+	if f.Codename != "" && f.Codename != reading.Codename {
+		return false
+	}
+
+	if f.Area != nil && !f.Area.Contains(reading.Coordinates) {
+		return false
+	}
+
+	return true
+}
+
+// Subscription is a buffered feed of the readings matching its filter.
+type Subscription struct {
+	filter  Filter
+	ch      chan domain.Reading
+	dropped atomic.Uint64
+}
+
+// C delivers the readings, it is closed when the subscription is cancelled.
+func (s *Subscription) C() <-chan domain.Reading {
+	return s.ch
+}
+
+// Dropped returns the number of readings dropped because the subscriber did not keep up.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// EventSubscription is a buffered feed of the service events.
+type EventSubscription struct {
+	ch      chan domain.Event
+	dropped atomic.Uint64
+}
+
+// C delivers the events, it is closed when the subscription is cancelled.
+func (s *EventSubscription) C() <-chan domain.Event {
+	return s.ch
+}
+
+// Dropped returns the number of events dropped because the subscriber did not keep up.
+func (s *EventSubscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Hub fans the readings and the events out to the subscribers. Publishing never blocks: a reading is dropped
+// for a subscriber whose buffer is full, so a slow client can not stall the data producers.
+type Hub struct {
+	mu     sync.RWMutex
+	subs   map[*Subscription]struct{}
+	events map[*EventSubscription]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		subs:   make(map[*Subscription]struct{}),
+		events: make(map[*EventSubscription]struct{}),
+	}
+}
+
+// Subscribe registers a subscriber with a buffer of the given size.
+func (h *Hub) Subscribe(filter Filter, buffer int) *Subscription {
+	sub := &Subscription{
+		filter: filter,
+		ch:     make(chan domain.Reading, buffer),
+	}
+
+	h.mu.Lock()
+	h.subs[sub] = struct{}{}
+	h.mu.Unlock()
+
+	return sub
+}
+
+// Unsubscribe removes the subscriber and closes its channel.
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subs[sub]; ok {
+		delete(h.subs, sub)
+		close(sub.ch)
+	}
+}
+
+func (h *Hub) Publish(reading domain.Reading) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for sub := range h.subs {
+		if !sub.filter.Matches(reading) {
+			continue
+		}
+
+		select {
+		case sub.ch <- reading:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// SubscribeEvents registers an event subscriber with a buffer of the given size.
+func (h *Hub) SubscribeEvents(buffer int) *EventSubscription {
+	sub := &EventSubscription{ch: make(chan domain.Event, buffer)}
+
+	h.mu.Lock()
+	h.events[sub] = struct{}{}
+	h.mu.Unlock()
+
+	return sub
+}
+
+// UnsubscribeEvents removes the event subscriber and closes its channel.
+func (h *Hub) UnsubscribeEvents(sub *EventSubscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.events[sub]; ok {
+		delete(h.events, sub)
+		close(sub.ch)
+	}
+}
+
+func (h *Hub) PublishEvent(event domain.Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for sub := range h.events {
+		select {
+		case sub.ch <- event:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package generations
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock tells the time of the simulation and makes its tickers.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers the ticks of a Clock.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// RealClock is the wall clock in UTC.
+type RealClock struct{}
+
+func (RealClock) Now() time.Time {
+	return time.Now().UTC()
+}
+
+func (RealClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
+
+// AcceleratedClock runs speed times faster than the wall clock from its start.
+type AcceleratedClock struct {
+	start     time.Time
+	realStart time.Time
+	speed     float64
+}
+
+func NewAcceleratedClock(start time.Time, speed float64) *AcceleratedClock {
+	return &AcceleratedClock{start: start, realStart: time.Now(), speed: speed}
+}
+
+func (c *AcceleratedClock) Now() time.Time {
+	return c.at(time.Now())
+}
+
+// NewTicker ticks every d of the simulation time, i.e. every d/speed of the wall clock. Like the wall clock tickers
+// it drops the ticks a slow receiver misses.
+func (c *AcceleratedClock) NewTicker(d time.Duration) Ticker {
+	period := time.Duration(float64(d) / c.speed)
+	if period <= 0 {
+		period = time.Nanosecond
+	}
+
+	t := &acceleratedTicker{
+		ticker: time.NewTicker(period),
+		c:      make(chan time.Time, 1),
+		stop:   make(chan struct{}),
+	}
+
+	go func() {
+		for {
+			select {
+			case tick := <-t.ticker.C:
+				select {
+				case t.c <- c.at(tick):
+				default:
+				}
+			case <-t.stop:
+				return
+			}
+		}
+	}()
+
+	return t
+}
+
+// at converts the wall clock time to the simulation time in UTC.
+func (c *AcceleratedClock) at(t time.Time) time.Time {
+	return c.start.Add(time.Duration(float64(t.Sub(c.realStart)) * c.speed)).UTC()
+}
+
+type acceleratedTicker struct {
+	ticker *time.Ticker
+	c      chan time.Time
+
+	once sync.Once
+	stop chan struct{}
+}
+
+func (t *acceleratedTicker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *acceleratedTicker) Stop() {
+	t.once.Do(func() {
+		t.ticker.Stop()
+		close(t.stop)
+	})
+}
+
+// ManualClock is a clock which only moves when it is advanced, e.g. in tests.
+type ManualClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*manualTicker
+	// created is signalled when a ticker is created
+	created *sync.Cond
+}
+
+func NewManualClock(start time.Time) *ManualClock {
+	c := &ManualClock{now: start}
+	c.created = sync.NewCond(&c.mu)
+
+	return c
+}
+
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *ManualClock) NewTicker(d time.Duration) Ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &manualTicker{
+		c:      make(chan time.Time),
+		period: d,
+		next:   c.now.Add(d),
+		stop:   make(chan struct{}),
+	}
+	c.tickers = append(c.tickers, t)
+	c.created.Broadcast()
+
+	return t
+}
+
+// BlockUntil waits until n tickers are running, e.g. until the worker started in a goroutine created its ticker,
+// so the clock is not advanced past the ticks the worker should get.
+func (c *ManualClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for {
+		running := 0
+		for _, t := range c.tickers {
+			if !t.stopped() {
+				running++
+			}
+		}
+
+		if running >= n {
+			return
+		}
+
+		c.created.Wait()
+	}
+}
+
+// Advance moves the clock forward by d and fires the ticks on the way in their order. Unlike the wall clock tickers
+// no tick is dropped, Advance waits until every tick is received or its ticker is stopped.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	c.mu.Unlock()
+
+	for {
+		c.mu.Lock()
+
+		// the stopped tickers are dropped, the earliest due one fires first
+		active := c.tickers[:0]
+		for _, t := range c.tickers {
+			if !t.stopped() {
+				active = append(active, t)
+			}
+		}
+		c.tickers = active
+
+		sort.SliceStable(c.tickers, func(i, j int) bool { return c.tickers[i].next.Before(c.tickers[j].next) })
+
+		if len(c.tickers) == 0 || c.tickers[0].next.After(target) {
+			c.now = target
+			c.mu.Unlock()
+			return
+		}
+
+		t := c.tickers[0]
+		c.now = t.next
+		t.next = t.next.Add(t.period)
+		at := c.now
+
+		// the receiver may look at the clock, it is not locked while the tick is delivered
+		c.mu.Unlock()
+
+		select {
+		case t.c <- at:
+		case <-t.stop:
+		}
+	}
+}
+
+type manualTicker struct {
+	c      chan time.Time
+	period time.Duration
+	next   time.Time
+
+	once sync.Once
+	stop chan struct{}
+}
+
+func (t *manualTicker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *manualTicker) Stop() {
+	t.once.Do(func() { close(t.stop) })
+}
+
+func (t *manualTicker) stopped() bool {
+	select {
+	case <-t.stop:
+		return true
+	default:
+		return false
+	}
+}
@@ -6,19 +6,19 @@ import (
 	"github.com/PavelDonchenko/sensor-go/internal/domain"
 )
 
-func GenerateCoordinates(id int) domain.Coordinates {
+func GenerateCoordinates(r *rand.Rand, id int) domain.Coordinates {
 	minOffset := float64(id) * -0.1 // Minimum offset value
 	maxOffset := float64(id) * 0.1  // Maximum offset value
 
 	// Generate random offset values for X, Y, Z coordinates within the specified range
-	offsetX := minOffset + rand.Float64()*(maxOffset-minOffset)
-	offsetY := minOffset + rand.Float64()*(maxOffset-minOffset)
-	offsetZ := minOffset + rand.Float64()*(maxOffset-minOffset)
+	offsetX := minOffset + r.Float64()*(maxOffset-minOffset)
+	offsetY := minOffset + r.Float64()*(maxOffset-minOffset)
+	offsetZ := minOffset + r.Float64()*(maxOffset-minOffset)
 
 	// Generate a random base coordinate within a specific range
-	baseX := -10.0 + rand.Float64()*(10.0-(-10.0))
-	baseY := -10.0 + rand.Float64()*(10.0-(-10.0))
-	baseZ := -10.0 + rand.Float64()*(0.0-(-10.0))
+	baseX := -10.0 + r.Float64()*(10.0-(-10.0))
+	baseY := -10.0 + r.Float64()*(10.0-(-10.0))
+	baseZ := -10.0 + r.Float64()*(0.0-(-10.0))
 
 	// Calculate the final coordinates by applying the offsets to the base coordinate
 	x := baseX + offsetX
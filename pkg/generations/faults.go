@@ -0,0 +1,260 @@
+package generations
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrFaultRuleNotFound     = errors.New("fault rule not found")
+	ErrorWrongFaultMode      = errors.New("fault mode must be drift, stuck, spike, invalid, out_of_range, dropout or outage")
+	ErrorWrongFaultScope     = errors.New("fault rule may be scoped to only one of a group or a sensor")
+	ErrorWrongProbability    = errors.New("fault probability must be between 0 and 1")
+	ErrorWrongFaultDuration  = errors.New("drift, stuck and outage faults must last a positive duration")
+	ErrorWrongFaultMagnitude = errors.New("drift faults must have a non-zero magnitude, spike faults a positive one")
+)
+
+// outOfRangeTemperature is the reading of a disconnected probe.
+const outOfRangeTemperature = -127
+
+// Faults injects the faults of its rules into the simulated measurements. The rules may be added, toggled
+// and deleted while the simulation runs, a sensor suffers from at most one lasting fault at a time.
+type Faults struct {
+	mu     sync.Mutex
+	rules  []domain.FaultRule
+	nextID int
+	active map[uuid.UUID]*activeFault
+	// now is the time of the latest measurement, the simulation clock may run ahead of the wall clock
+	now time.Time
+}
+
+type activeFault struct {
+	domain.ActiveFault
+	magnitude float64
+	// temperature and transparency are the values a stuck sensor repeats
+	temperature  float64
+	transparency int
+}
+
+// NewFaults creates the faults of the configured rules, the rules are numbered in their order.
+func NewFaults(cfg config.Config) (*Faults, error) {
+	f := &Faults{active: make(map[uuid.UUID]*activeFault)}
+
+	for i, rule := range cfg.Faults.Rules {
+		if err := ValidateFaultRule(rule); err != nil {
+			return nil, fmt.Errorf("fault rule %d: %w", i+1, err)
+		}
+
+		f.add(rule)
+	}
+
+	return f, nil
+}
+
+// ValidateFaultRule checks the rule has the settings its mode needs.
+func ValidateFaultRule(rule domain.FaultRule) error {
+	switch {
+	case !rule.Mode.Valid():
+		return ErrorWrongFaultMode
+	case rule.GroupName != "" && rule.Codename != "":
+		return ErrorWrongFaultScope
+	case rule.Probability < 0 || rule.Probability > 1:
+		return ErrorWrongProbability
+	case rule.Mode.Lasting() && rule.Duration <= 0:
+		return ErrorWrongFaultDuration
+	case rule.Mode == domain.FaultDrift && rule.Magnitude == 0:
+		return ErrorWrongFaultMagnitude
+	case rule.Mode == domain.FaultSpike && rule.Magnitude <= 0:
+		return ErrorWrongFaultMagnitude
+	}
+
+	return nil
+}
+
+// Rules returns the fault rules in the order they are tried in.
+func (f *Faults) Rules() []domain.FaultRule {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append(make([]domain.FaultRule, 0, len(f.rules)), f.rules...)
+}
+
+// AddRule adds the rule and returns it with its id.
+func (f *Faults) AddRule(rule domain.FaultRule) domain.FaultRule {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.add(rule)
+}
+
+func (f *Faults) add(rule domain.FaultRule) domain.FaultRule {
+	f.nextID++
+	rule.ID = f.nextID
+	f.rules = append(f.rules, rule)
+
+	return rule
+}
+
+// SetEnabled toggles the rule, the lasting faults of a disabled rule end at once.
+func (f *Faults) SetEnabled(id int, enabled bool) (domain.FaultRule, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i := range f.rules {
+		if f.rules[i].ID == id {
+			f.rules[i].Enabled = enabled
+			if !enabled {
+				f.end(id)
+			}
+
+			return f.rules[i], nil
+		}
+	}
+
+	return domain.FaultRule{}, ErrFaultRuleNotFound
+}
+
+// DeleteRule deletes the rule and ends its lasting faults.
+func (f *Faults) DeleteRule(id int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i := range f.rules {
+		if f.rules[i].ID == id {
+			f.rules = append(f.rules[:i], f.rules[i+1:]...)
+			f.end(id)
+
+			return nil
+		}
+	}
+
+	return ErrFaultRuleNotFound
+}
+
+// end ends the lasting faults of the rule.
+func (f *Faults) end(id int) {
+	for sensorID, fault := range f.active {
+		if fault.RuleID == id {
+			delete(f.active, sensorID)
+		}
+	}
+}
+
+// Active returns the lasting faults the sensors suffer from as of the latest measurement, the ones ending first first.
+func (f *Faults) Active() []domain.ActiveFault {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	faults := make([]domain.ActiveFault, 0, len(f.active))
+	for _, fault := range f.active {
+		if fault.Until.After(f.now) {
+			faults = append(faults, fault.ActiveFault)
+		}
+	}
+
+	sort.Slice(faults, func(i, j int) bool { return faults[i].Until.Before(faults[j].Until) })
+
+	return faults
+}
+
+// Forget ends the lasting fault of the sensor which is no longer simulated.
+func (f *Faults) Forget(id uuid.UUID) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.active, id)
+}
+
+// Apply injects the fault the sensor suffers from into its measurement and tags the measurement with the fault mode.
+// It returns false if the measurement is lost. A healthy sensor starts the fault of the first enabled rule
+// which draws it, every rule applying to the sensor is drawn from r, so the same seed injects the same faults.
+func (f *Faults) Apply(r *rand.Rand, sensor domain.Sensor, m *domain.Measurement) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	at := m.CreatedAt
+	if at.After(f.now) {
+		f.now = at
+	}
+
+	if fault, ok := f.active[sensor.ID]; ok {
+		if at.Before(fault.Until) {
+			return fault.apply(r, m)
+		}
+
+		delete(f.active, sensor.ID)
+	}
+
+	var started *domain.FaultRule
+	for i := range f.rules {
+		rule := f.rules[i]
+		if !rule.Enabled || !rule.Applies(sensor) {
+			continue
+		}
+
+		if r.Float64() < rule.Probability && started == nil {
+			started = &f.rules[i]
+		}
+	}
+
+	if started == nil {
+		return true
+	}
+
+	fault := &activeFault{
+		ActiveFault: domain.ActiveFault{
+			SensorID: sensor.ID,
+			Codename: sensor.Codename.String(),
+			RuleID:   started.ID,
+			Mode:     started.Mode,
+			Since:    at,
+			Until:    at,
+		},
+		magnitude:    started.Magnitude,
+		temperature:  m.Temperature,
+		transparency: m.Transparency,
+	}
+
+	if started.Mode.Lasting() {
+		fault.Until = at.Add(time.Duration(started.Duration) * time.Second)
+		f.active[sensor.ID] = fault
+	}
+
+	return fault.apply(r, m)
+}
+
+// apply injects the fault into the measurement.
+func (a *activeFault) apply(r *rand.Rand, m *domain.Measurement) bool {
+	m.Fault = string(a.Mode)
+
+	switch a.Mode {
+	case domain.FaultDrift:
+		m.Temperature = roundTemperature(m.Temperature + a.magnitude*m.CreatedAt.Sub(a.Since).Hours())
+	case domain.FaultStuck:
+		m.Temperature = a.temperature
+		m.Transparency = a.transparency
+	case domain.FaultSpike:
+		if r.Intn(2) == 0 {
+			m.Temperature = roundTemperature(m.Temperature + a.magnitude)
+		} else {
+			m.Temperature = roundTemperature(m.Temperature - a.magnitude)
+		}
+	case domain.FaultInvalid:
+		m.Temperature = math.NaN()
+	case domain.FaultOutOfRange:
+		m.Temperature = outOfRangeTemperature
+	case domain.FaultDropout, domain.FaultOutage:
+		return false
+	}
+
+	return true
+}
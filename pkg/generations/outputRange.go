@@ -4,10 +4,10 @@ import (
 	"math/rand"
 )
 
-func GenerateRandomInt() int {
+func GenerateRandomInt(r *rand.Rand) int {
 	values := []int{5, 10, 15, 20, 25}
 
-	randomIndex := rand.Intn(len(values))
+	randomIndex := r.Intn(len(values))
 
 	randomInt := values[randomIndex]
 
@@ -0,0 +1,522 @@
+package generations
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// scenarioGroupName is the group name a codename can be made of, the index follows it.
+var scenarioGroupName = regexp.MustCompile(`^[a-z]+$`)
+
+// Scenario declares the sensor field of a simulation and the events which happen in it, instead of the groups and
+// the random sensors of the config.
+type Scenario struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	// Seed overrides the simulation.seed if it is not zero.
+	Seed   int64           `yaml:"seed"`
+	Groups []ScenarioGroup `yaml:"groups"`
+	Events []ScenarioEvent `yaml:"events"`
+}
+
+// ScenarioGroup is a sensor group with its sensors and its water.
+type ScenarioGroup struct {
+	Name string `yaml:"name"`
+	// DataOutputRate is the rate of the sensors without a rate of their own, in seconds. A random one of 5, 10, 15,
+	// 20 or 25 is drawn for every sensor if it is zero.
+	DataOutputRate int `yaml:"data_output_rate"`
+	// Temperature overrides the fields it names of the temperature model the config has for the group.
+	Temperature yaml.Node   `yaml:"temperature"`
+	Placements  []Placement `yaml:"placements"`
+
+	model config.TemperatureModel
+}
+
+// Placement places one sensor At the coordinates, or several along a Grid, a Line or a Mooring.
+type Placement struct {
+	At      *domain.Coordinates `yaml:"at"`
+	Grid    *GridPlacement      `yaml:"grid"`
+	Line    *LinePlacement      `yaml:"line"`
+	Mooring *MooringPlacement   `yaml:"mooring"`
+	// DataOutputRate overrides the rate of the group, Source is simulated if it is empty.
+	DataOutputRate int                 `yaml:"data_output_rate"`
+	Source         domain.SensorSource `yaml:"source"`
+}
+
+// GridPlacement places Rows by Columns sensors at the depth of the Origin, Spacing apart along x and y.
+type GridPlacement struct {
+	Origin  domain.Coordinates `yaml:"origin"`
+	Spacing float64            `yaml:"spacing"`
+	Rows    int                `yaml:"rows"`
+	Columns int                `yaml:"columns"`
+}
+
+// LinePlacement places Count sensors evenly on the line From To, both ends included.
+type LinePlacement struct {
+	From  domain.Coordinates `yaml:"from"`
+	To    domain.Coordinates `yaml:"to"`
+	Count int                `yaml:"count"`
+}
+
+// MooringPlacement places Count sensors evenly on a vertical line at X and Y from the Top to the Bottom depth,
+// the depths are the z coordinates, zero at the surface and negative below it.
+type MooringPlacement struct {
+	X      float64 `yaml:"x"`
+	Y      float64 `yaml:"y"`
+	Top    float64 `yaml:"top"`
+	Bottom float64 `yaml:"bottom"`
+	Count  int     `yaml:"count"`
+}
+
+// ScenarioEventType is what happens at a scenario event.
+type ScenarioEventType string
+
+const (
+	// EventTemperature changes the temperature by Delta degrees, e.g. a warm current.
+	EventTemperature ScenarioEventType = "temperature"
+	// EventTransparency changes the transparency by Delta percent, e.g. a plankton bloom.
+	EventTransparency ScenarioEventType = "transparency"
+	// EventOffline takes the sensors offline, they report no readings.
+	EventOffline ScenarioEventType = "offline"
+)
+
+// ScenarioEvent happens At the time since the start of the simulation to the sensors of the Group, the Sensor of
+// the codename or every sensor if neither is set. It lasts the Duration, till the end of the simulation if it is zero.
+// A temperature or transparency change builds up over the Ramp and fades over it at the end.
+type ScenarioEvent struct {
+	At       Offset            `yaml:"at"`
+	Type     ScenarioEventType `yaml:"type"`
+	Group    string            `yaml:"group"`
+	Sensor   string            `yaml:"sensor"`
+	Delta    float64           `yaml:"delta"`
+	Duration time.Duration     `yaml:"duration"`
+	Ramp     time.Duration     `yaml:"ramp"`
+}
+
+// Offset is the time since the start of the simulation, written as a duration, e.g. 10m, or as t+10m.
+type Offset time.Duration
+
+func (o *Offset) UnmarshalYAML(value *yaml.Node) error {
+	d, err := time.ParseDuration(strings.TrimPrefix(strings.TrimSpace(value.Value), "t+"))
+	if err != nil || d < 0 {
+		return fmt.Errorf("line %d: offset %q must be a duration since the start like 10m or t+10m", value.Line, value.Value)
+	}
+
+	*o = Offset(d)
+
+	return nil
+}
+
+func (e ScenarioEvent) String() string {
+	target := "every sensor"
+	switch {
+	case e.Group != "":
+		target = e.Group
+	case e.Sensor != "":
+		target = e.Sensor
+	}
+
+	lasting := ""
+	if e.Duration > 0 {
+		lasting = " for " + e.Duration.String()
+	}
+
+	switch e.Type {
+	case EventTemperature:
+		return fmt.Sprintf("t+%s: temperature of %s changes by %+g°C%s", time.Duration(e.At), target, e.Delta, lasting)
+	case EventTransparency:
+		return fmt.Sprintf("t+%s: transparency of %s changes by %+g%%%s", time.Duration(e.At), target, e.Delta, lasting)
+	}
+
+	return fmt.Sprintf("t+%s: %s goes offline%s", time.Duration(e.At), target, lasting)
+}
+
+// LoadScenario reads the scenario file and checks it, every problem found is reported. The temperature models of
+// the groups start from the models of the config.
+func LoadScenario(path string, cfg config.Config) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read scenario: %w", err)
+	}
+
+	var scenario Scenario
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	if err = decoder.Decode(&scenario); err != nil {
+		return nil, fmt.Errorf("decode scenario %s: %w", path, err)
+	}
+
+	if err = scenario.validate(cfg); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s:\n%w", path, err)
+	}
+
+	return &scenario, nil
+}
+
+// Apply makes the config simulate the scenario, its seed and the temperature models of its groups replace the configured ones.
+func (s *Scenario) Apply(cfg *config.Config) {
+	if s.Seed != 0 {
+		cfg.Simulation.Seed = s.Seed
+	}
+
+	groups := make(map[string]config.TemperatureModel, len(cfg.Temperature.Groups)+len(s.Groups))
+	for name, model := range cfg.Temperature.Groups {
+		groups[name] = model
+	}
+
+	for _, group := range s.Groups {
+		groups[group.Name] = group.model
+	}
+
+	cfg.Temperature.Groups = groups
+}
+
+// Sensors places the sensors of the scenario as of the time, the sensors of a group are numbered from 1 in the order
+// of its placements. Their rates, if not set, and their first readings are drawn from r.
+func (s *Scenario) Sensors(r *rand.Rand, at time.Time) []domain.Sensor {
+	sensors := make([]domain.Sensor, 0)
+
+	for _, group := range s.Groups {
+		index := 0
+
+		for _, placement := range group.Placements {
+			for _, coordinates := range placement.coordinates() {
+				index++
+
+				rate := placement.DataOutputRate
+				if rate == 0 {
+					rate = group.DataOutputRate
+				}
+				if rate == 0 {
+					rate = GenerateRandomInt(r)
+				}
+
+				source := placement.Source
+				if source == "" {
+					source = domain.SourceSimulated
+				}
+
+				sensors = append(sensors, domain.Sensor{
+					Source:         source,
+					Codename:       domain.Codename{Name: group.Name, SensorGroupID: index},
+					Coordinates:    coordinates,
+					DataOutputRate: rate,
+					Transparency:   r.Intn(101),
+					Temperature:    GenerateTemperature(r, group.model, coordinates.Z, at),
+				})
+			}
+		}
+	}
+
+	return sensors
+}
+
+// Temperature returns the temperature of the sensor changed by the events of the scenario elapsed since its start.
+func (s *Scenario) Temperature(sensor domain.Sensor, elapsed time.Duration, temperature float64) float64 {
+	if s == nil {
+		return temperature
+	}
+
+	return roundTemperature(temperature + s.change(EventTemperature, sensor, elapsed))
+}
+
+// Transparency returns the transparency of the sensor changed by the events of the scenario elapsed since its start.
+func (s *Scenario) Transparency(sensor domain.Sensor, elapsed time.Duration, transparency int) int {
+	if s == nil {
+		return transparency
+	}
+
+	changed := int(math.Round(float64(transparency) + s.change(EventTransparency, sensor, elapsed)))
+
+	switch {
+	case changed < 0:
+		return 0
+	case changed > 100:
+		return 100
+	}
+
+	return changed
+}
+
+// Offline reports whether an event of the scenario elapsed since its start keeps the sensor offline.
+func (s *Scenario) Offline(sensor domain.Sensor, elapsed time.Duration) bool {
+	if s == nil {
+		return false
+	}
+
+	for _, event := range s.Events {
+		if event.Type == EventOffline && event.applies(sensor) && event.active(elapsed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// change sums the changes of the events of the type acting on the sensor.
+func (s *Scenario) change(eventType ScenarioEventType, sensor domain.Sensor, elapsed time.Duration) float64 {
+	change := 0.0
+
+	for _, event := range s.Events {
+		if event.Type != eventType || !event.applies(sensor) || !event.active(elapsed) {
+			continue
+		}
+
+		// the change builds up over the ramp and fades over it before the end
+		strength := 1.0
+		if event.Ramp > 0 {
+			strength = math.Min(strength, float64(elapsed-time.Duration(event.At))/float64(event.Ramp))
+			if event.Duration > 0 {
+				strength = math.Min(strength, float64(time.Duration(event.At)+event.Duration-elapsed)/float64(event.Ramp))
+			}
+		}
+
+		change += event.Delta * strength
+	}
+
+	return change
+}
+
+func (e ScenarioEvent) applies(sensor domain.Sensor) bool {
+	switch {
+	case e.Sensor != "":
+		return e.Sensor == sensor.Codename.String()
+	case e.Group != "":
+		return e.Group == sensor.Codename.Name
+	}
+
+	return true
+}
+
+func (e ScenarioEvent) active(elapsed time.Duration) bool {
+	at := time.Duration(e.At)
+
+	return elapsed >= at && (e.Duration == 0 || elapsed < at+e.Duration)
+}
+
+// coordinates returns the coordinates of the sensors the placement places.
+func (p Placement) coordinates() []domain.Coordinates {
+	switch {
+	case p.At != nil:
+		return []domain.Coordinates{*p.At}
+	case p.Grid != nil:
+		coordinates := make([]domain.Coordinates, 0, p.Grid.Rows*p.Grid.Columns)
+		for row := 0; row < p.Grid.Rows; row++ {
+			for column := 0; column < p.Grid.Columns; column++ {
+				coordinates = append(coordinates, domain.Coordinates{
+					X: p.Grid.Origin.X + float64(column)*p.Grid.Spacing,
+					Y: p.Grid.Origin.Y + float64(row)*p.Grid.Spacing,
+					Z: p.Grid.Origin.Z,
+				})
+			}
+		}
+		return coordinates
+	case p.Line != nil:
+		return spread(p.Line.From, p.Line.To, p.Line.Count)
+	case p.Mooring != nil:
+		return spread(domain.Coordinates{X: p.Mooring.X, Y: p.Mooring.Y, Z: p.Mooring.Top},
+			domain.Coordinates{X: p.Mooring.X, Y: p.Mooring.Y, Z: p.Mooring.Bottom}, p.Mooring.Count)
+	}
+
+	return nil
+}
+
+// spread returns count points evenly spaced from one point to another, both included.
+func spread(from, to domain.Coordinates, count int) []domain.Coordinates {
+	if count == 1 {
+		return []domain.Coordinates{from}
+	}
+
+	coordinates := make([]domain.Coordinates, 0, count)
+	for i := 0; i < count; i++ {
+		f := float64(i) / float64(count-1)
+		coordinates = append(coordinates, domain.Coordinates{
+			X: from.X + (to.X-from.X)*f,
+			Y: from.Y + (to.Y-from.Y)*f,
+			Z: from.Z + (to.Z-from.Z)*f,
+		})
+	}
+
+	return coordinates
+}
+
+// validate checks the scenario and resolves the temperature models of its groups.
+func (s *Scenario) validate(cfg config.Config) error {
+	var problems []error
+
+	problem := func(format string, args ...any) {
+		problems = append(problems, fmt.Errorf(format, args...))
+	}
+
+	if len(s.Groups) == 0 {
+		problem("groups: the scenario must declare at least one group")
+	}
+
+	groups := make(map[string]bool, len(s.Groups))
+	codenames := make(map[string]bool)
+
+	for i := range s.Groups {
+		group := &s.Groups[i]
+		path := fmt.Sprintf("groups[%d]", i)
+
+		switch {
+		case !scenarioGroupName.MatchString(group.Name):
+			problem("%s: name %q must be lowercase letters only, the sensor index follows it in the codenames", path, group.Name)
+		case groups[group.Name]:
+			problem("%s: group %s is declared twice", path, group.Name)
+		}
+		groups[group.Name] = true
+
+		if group.DataOutputRate < 0 {
+			problem("%s: data_output_rate must not be negative", path)
+		}
+
+		group.model = cfg.TemperatureModel(group.Name)
+		if !group.Temperature.IsZero() {
+			if err := decodeTemperatureModel(&group.Temperature, &group.model); err != nil {
+				problem("%s.temperature: %v", path, err)
+			} else if group.model.Noise < 0 || group.model.ReversionTime < 0 || group.model.ThermoclineDepth < 0 {
+				problem("%s.temperature: noise, reversion_time and thermocline_depth must not be negative", path)
+			}
+		}
+
+		if len(group.Placements) == 0 {
+			problem("%s: group %s must have at least one placement", path, group.Name)
+		}
+
+		index := 0
+		for j, placement := range group.Placements {
+			if err := placement.validate(); err != nil {
+				problem("%s.placements[%d]: %v", path, j, err)
+				continue
+			}
+
+			for range placement.coordinates() {
+				index++
+				codenames[fmt.Sprintf("%s%d", group.Name, index)] = true
+			}
+		}
+	}
+
+	for i, event := range s.Events {
+		path := fmt.Sprintf("events[%d]", i)
+
+		switch event.Type {
+		case EventTemperature, EventTransparency:
+			if event.Delta == 0 {
+				problem("%s: %s event must change it by a non-zero delta", path, event.Type)
+			}
+		case EventOffline:
+		default:
+			problem("%s: type %q must be temperature, transparency or offline", path, event.Type)
+		}
+
+		switch {
+		case event.Group != "" && event.Sensor != "":
+			problem("%s: event may happen to only one of a group or a sensor", path)
+		case event.Group != "" && !groups[event.Group]:
+			problem("%s: group %s is not declared in the scenario", path, event.Group)
+		case event.Sensor != "" && !codenames[event.Sensor]:
+			problem("%s: sensor %s is not placed by the scenario", path, event.Sensor)
+		}
+
+		switch {
+		case event.Duration < 0 || event.Ramp < 0:
+			problem("%s: duration and ramp must not be negative", path)
+		case event.Duration > 0 && 2*event.Ramp > event.Duration:
+			problem("%s: the change must build up and fade within the duration, the ramp is at most half of it", path)
+		}
+	}
+
+	return errors.Join(problems...)
+}
+
+func (p Placement) validate() error {
+	kinds := 0
+	for _, set := range []bool{p.At != nil, p.Grid != nil, p.Line != nil, p.Mooring != nil} {
+		if set {
+			kinds++
+		}
+	}
+
+	if kinds != 1 {
+		return errors.New("placement must have exactly one of at, grid, line or mooring")
+	}
+
+	if p.DataOutputRate < 0 {
+		return errors.New("data_output_rate must not be negative")
+	}
+
+	if p.Source != "" && !p.Source.Valid() {
+		return fmt.Errorf("source %q must be simulated or live", p.Source)
+	}
+
+	switch {
+	case p.At != nil:
+		if p.At.Z > 0 {
+			return errors.New("at: z must not be above the surface")
+		}
+	case p.Grid != nil:
+		if p.Grid.Rows <= 0 || p.Grid.Columns <= 0 {
+			return errors.New("grid: rows and columns must be positive")
+		}
+		if p.Grid.Spacing <= 0 {
+			return errors.New("grid: spacing must be positive")
+		}
+		if p.Grid.Origin.Z > 0 {
+			return errors.New("grid: origin z must not be above the surface")
+		}
+	case p.Line != nil:
+		if p.Line.Count <= 0 {
+			return errors.New("line: count must be positive")
+		}
+		if p.Line.From.Z > 0 || p.Line.To.Z > 0 {
+			return errors.New("line: z must not be above the surface")
+		}
+	case p.Mooring != nil:
+		if p.Mooring.Count <= 0 {
+			return errors.New("mooring: count must be positive")
+		}
+		if p.Mooring.Top > 0 || p.Mooring.Bottom > p.Mooring.Top {
+			return errors.New("mooring: top must not be above the surface and bottom must not be above top")
+		}
+	}
+
+	return nil
+}
+
+// decodeTemperatureModel overrides the fields of the model the node names, an unknown field is an error.
+func decodeTemperatureModel(node *yaml.Node, model *config.TemperatureModel) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: temperature must be a mapping of the model fields", node.Line)
+	}
+
+	fields := make(map[string]bool)
+	modelType := reflect.TypeOf(*model)
+	for i := 0; i < modelType.NumField(); i++ {
+		fields[modelType.Field(i).Tag.Get("yaml")] = true
+	}
+
+	// the keys and the values alternate in the content of a mapping
+	for i := 0; i < len(node.Content); i += 2 {
+		if key := node.Content[i]; !fields[key.Value] {
+			return fmt.Errorf("line %d: unknown field %s", key.Line, key.Value)
+		}
+	}
+
+	return node.Decode(model)
+}
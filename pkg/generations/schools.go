@@ -0,0 +1,258 @@
+package generations
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/google/uuid"
+)
+
+// schoolWeight is how often a species forms one of the schools of the field.
+var schoolWeight = map[domain.Rarity]int{
+	domain.RarityCommon:   6,
+	domain.RarityUncommon: 3,
+	domain.RarityRare:     1,
+}
+
+// School is a school of fish swimming across the sensor field, z is negative below the surface like the sensor coordinates.
+type School struct {
+	ID       int                `json:"id"`
+	Species  string             `json:"species"`
+	Size     int                `json:"size"`
+	Position domain.Coordinates `json:"position"`
+	Velocity domain.Coordinates `json:"velocity"`
+
+	heading float64
+	depth   domain.Range
+}
+
+// Schools simulates the schools of the schooling species of the catalogue. The schools move in steps, after every step
+// each followed sensor sees the schools within the detection radius, so a school passing between two readings of a sensor
+// is still reported by the next one. The steps do not depend on the order the sensors read in, a run is reproduced
+// from the random source.
+//
+// A sensor is followed from its Follow, which also moves it, to its Forget. Every step records a sighting of the schools
+// near each followed sensor, Detect hands the sightings of the sensor up to its reading time over and keeps the later
+// ones, which another sensor reading ahead of it stepped into, for its next reading.
+type Schools struct {
+	cfg  config.Config
+	rand *rand.Rand
+
+	mu      sync.Mutex
+	schools []*School
+	last    time.Time
+	sensors map[uuid.UUID]domain.Coordinates
+	// seen are the schools every sensor saw since its previous reading
+	seen map[uuid.UUID][]sighting
+}
+
+// sighting is a school seen by a sensor at a step.
+type sighting struct {
+	at     time.Time
+	school *School
+	count  int
+}
+
+// NewSchools spawns the schools at random positions of the field, there are none if the catalogue has no schooling species.
+func NewSchools(r *rand.Rand, cfg config.Config, species []domain.Species, now time.Time) *Schools {
+	s := &Schools{
+		cfg:     cfg,
+		rand:    r,
+		last:    now,
+		sensors: make(map[uuid.UUID]domain.Coordinates),
+		seen:    make(map[uuid.UUID][]sighting),
+	}
+
+	var pool []domain.Species
+	for _, sp := range species {
+		if sp.Schooling {
+			for i := 0; i < schoolWeight[sp.Rarity]; i++ {
+				pool = append(pool, sp)
+			}
+		}
+	}
+
+	if len(pool) == 0 {
+		return s
+	}
+
+	for id := 1; id <= cfg.Schools.Count; id++ {
+		sp := pool[r.Intn(len(pool))]
+
+		school := &School{
+			ID:      id,
+			Species: sp.Name,
+			Size:    int(math.Max(1, float64(sp.MaxCount/2+r.Intn(sp.MaxCount/2+1)))),
+			Position: domain.Coordinates{
+				X: (r.Float64()*2 - 1) * cfg.Schools.FieldSize,
+				Y: (r.Float64()*2 - 1) * cfg.Schools.FieldSize,
+				Z: -r.Float64() * cfg.Schools.MaxDepth,
+			},
+			heading: r.Float64() * 2 * math.Pi,
+			depth:   sp.Depth,
+		}
+		s.steer(school)
+
+		s.schools = append(s.schools, school)
+	}
+
+	return s
+}
+
+// Follow starts looking for the schools around the sensor at the position.
+func (s *Schools) Follow(id uuid.UUID, position domain.Coordinates) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sensors[id] = position
+}
+
+// Detect returns the fish of the schools the sensor saw since its previous reading up to the time.
+func (s *Schools) Detect(id uuid.UUID, now time.Time) []domain.DetectedFish {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.advance(now)
+
+	// another sensor may have moved the schools past the time already, its later sightings wait for the next reading
+	seen := make(map[*School]int)
+	later := s.seen[id][:0]
+
+	for _, sight := range s.seen[id] {
+		if sight.at.After(now) {
+			later = append(later, sight)
+			continue
+		}
+
+		if sight.count > seen[sight.school] {
+			seen[sight.school] = sight.count
+		}
+	}
+	s.seen[id] = later
+
+	counts := make(map[string]int)
+	for school, count := range seen {
+		counts[school.Species] += count
+	}
+
+	detected := make([]domain.DetectedFish, 0, len(counts))
+	for name, count := range counts {
+		detected = append(detected, domain.DetectedFish{Name: name, Count: count})
+	}
+
+	sort.Slice(detected, func(i, j int) bool { return detected[i].Name < detected[j].Name })
+
+	return detected
+}
+
+// Snapshot moves the schools up to the time and returns their copies.
+func (s *Schools) Snapshot(now time.Time) []School {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.advance(now)
+
+	schools := make([]School, 0, len(s.schools))
+	for _, school := range s.schools {
+		schools = append(schools, *school)
+	}
+
+	return schools
+}
+
+// Forget stops following the sensor.
+func (s *Schools) Forget(id uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sensors, id)
+	delete(s.seen, id)
+}
+
+// advance moves the schools step by step up to the time, every sensor looks around after every step.
+func (s *Schools) advance(now time.Time) {
+	step := s.cfg.Schools.Step
+
+	// the config rejects such a step, the schools stand still rather than the loop never ending
+	if step <= 0 {
+		return
+	}
+
+	for !s.last.Add(step).After(now) {
+		s.last = s.last.Add(step)
+
+		for _, school := range s.schools {
+			s.move(school, step.Seconds())
+		}
+
+		for id := range s.sensors {
+			s.observe(id, s.last)
+		}
+	}
+}
+
+// observe records the schools within the detection radius of the sensor, the closer the school the more of its fish are seen.
+func (s *Schools) observe(id uuid.UUID, at time.Time) {
+	position := s.sensors[id]
+	radius := s.cfg.Schools.DetectionRadius
+
+	for _, school := range s.schools {
+		distance := school.Position.Distance(position)
+		if distance > radius {
+			continue
+		}
+
+		count := int(math.Max(1, math.Round(float64(school.Size)*(1-(distance/radius)*(distance/radius)))))
+
+		s.seen[id] = append(s.seen[id], sighting{at: at, school: school, count: count})
+	}
+}
+
+// move lets the heading of the school wander and keeps the school inside the field.
+func (s *Schools) move(school *School, seconds float64) {
+	school.heading += s.rand.NormFloat64() * s.cfg.Schools.TurnRate * math.Sqrt(seconds)
+	s.steer(school)
+
+	school.Position.X += school.Velocity.X * seconds
+	school.Position.Y += school.Velocity.Y * seconds
+	school.Position.Z += school.Velocity.Z * seconds
+
+	size := s.cfg.Schools.FieldSize
+
+	// the school turns back at the edges of the field
+	if math.Abs(school.Position.X) > size {
+		school.Position.X = math.Copysign(size, school.Position.X)
+		school.heading = math.Pi - school.heading
+	}
+
+	if math.Abs(school.Position.Y) > size {
+		school.Position.Y = math.Copysign(size, school.Position.Y)
+		school.heading = -school.heading
+	}
+
+	school.Position.Z = math.Max(-s.cfg.Schools.MaxDepth, math.Min(0, school.Position.Z))
+
+	s.steer(school)
+}
+
+// steer points the velocity along the heading, the school also sinks or rises towards its preferred depth.
+func (s *Schools) steer(school *School) {
+	speed := s.cfg.Schools.Speed
+
+	school.Velocity.X = speed * math.Cos(school.heading)
+	school.Velocity.Y = speed * math.Sin(school.heading)
+	school.Velocity.Z = 0
+
+	depth := -school.Position.Z
+	switch {
+	case depth < school.depth.Min && depth < s.cfg.Schools.MaxDepth:
+		school.Velocity.Z = -speed / 4
+	case depth > school.depth.Max:
+		school.Velocity.Z = speed / 4
+	}
+}
@@ -0,0 +1,41 @@
+package generations
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"time"
+)
+
+// Seeds derives the random sources of the simulation from a single seed. Every part of the simulation, e.g. every sensor,
+// draws from a source of its own, so a run is reproduced from the seed no matter how the goroutines are scheduled.
+type Seeds struct {
+	seed int64
+}
+
+// NewSeeds creates the sources of the seed, a zero seed is replaced with a random one.
+func NewSeeds(seed int64) Seeds {
+	if seed == 0 {
+		seed = RandomSeed()
+	}
+
+	return Seeds{seed: seed}
+}
+
+// RandomSeed returns a seed for a run which is not reproduced from a configured one.
+func RandomSeed() int64 {
+	return time.Now().UnixNano()
+}
+
+// Seed returns the seed the sources are derived from.
+func (s Seeds) Seed() int64 {
+	return s.seed
+}
+
+// Rand returns the random source of the part of the simulation named by the key, the same key gets the same numbers.
+// The source must not be shared between goroutines.
+func (s Seeds) Rand(key string) *rand.Rand {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+
+	return rand.New(rand.NewSource(s.seed ^ int64(h.Sum64())))
+}
@@ -0,0 +1,111 @@
+package generations
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed species.yaml
+var defaultSpecies []byte
+
+const (
+	// depthTolerance and temperatureTolerance are how far outside its preferred range a species is still met now and then.
+	depthTolerance       = 5.0
+	temperatureTolerance = 2.0
+)
+
+// detectionChance is the chance to detect a species at a tick in its preferred habitat.
+var detectionChance = map[domain.Rarity]float64{
+	domain.RarityCommon:   0.4,
+	domain.RarityUncommon: 0.15,
+	domain.RarityRare:     0.03,
+}
+
+// LoadSpecies reads the species catalogue from the YAML or JSON file, the embedded catalogue is used if the path is empty.
+func LoadSpecies(path string) ([]domain.Species, error) {
+	data := defaultSpecies
+
+	if path != "" {
+		var err error
+
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read species catalogue: %w", err)
+		}
+	}
+
+	var species []domain.Species
+
+	// JSON is a subset of YAML, the same decoder reads both
+	if err := yaml.Unmarshal(data, &species); err != nil {
+		return nil, fmt.Errorf("decode species catalogue: %w", err)
+	}
+
+	names := make(map[string]bool, len(species))
+
+	for _, s := range species {
+		switch {
+		case s.Name == "":
+			return nil, errors.New("species catalogue: species without a name")
+		case names[s.Name]:
+			return nil, fmt.Errorf("species catalogue: %s is listed twice", s.Name)
+		case s.Depth.Min > s.Depth.Max, s.Temperature.Min > s.Temperature.Max:
+			return nil, fmt.Errorf("species catalogue: %s has an empty depth or temperature range", s.Name)
+		case s.MaxCount <= 0:
+			return nil, fmt.Errorf("species catalogue: %s must have a positive max_count", s.Name)
+		case !s.Rarity.Valid():
+			return nil, fmt.Errorf("species catalogue: %s has unknown rarity %q", s.Name, s.Rarity)
+		}
+
+		names[s.Name] = true
+	}
+
+	return species, nil
+}
+
+// DetectFish returns the fish a sensor at the depth detects in the water of the temperature. Every species is met
+// by its rarity in its preferred habitat and more and more seldom the farther the sensor is from it.
+func DetectFish(r *rand.Rand, species []domain.Species, depth, temperature float64) []domain.DetectedFish {
+	depth = math.Abs(depth)
+
+	var detected []domain.DetectedFish
+
+	for _, s := range species {
+		suitability := fit(depth, s.Depth, depthTolerance) * fit(temperature, s.Temperature, temperatureTolerance)
+
+		if r.Float64() >= detectionChance[s.Rarity]*suitability {
+			continue
+		}
+
+		count := 1 + r.Intn(s.MaxCount)
+		if s.Schooling {
+			// a school is met as a whole, it is smaller outside the preferred habitat
+			count = int(math.Max(1, math.Round(float64(s.MaxCount)*suitability*(0.5+r.Float64()/2))))
+		}
+
+		detected = append(detected, domain.DetectedFish{Name: s.Name, Count: count})
+	}
+
+	return detected
+}
+
+// fit is 1 inside the range and fades with the distance from it.
+func fit(value float64, r domain.Range, tolerance float64) float64 {
+	var distance float64
+
+	switch {
+	case value < r.Min:
+		distance = r.Min - value
+	case value > r.Max:
+		distance = value - r.Max
+	}
+
+	return math.Exp(-(distance / tolerance) * (distance / tolerance))
+}
@@ -3,16 +3,89 @@ package generations
 import (
 	"math"
 	"math/rand"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/config"
 )
 
-func GenerateTemperature(depth float64) float64 {
-	randomFloat := rand.Float64() + 1
+const daysInYear = 365.25
+
+// ProfileTemperature returns the temperature the model gives at the depth at the time, without the sensor deviation.
+func ProfileTemperature(model config.TemperatureModel, depth float64, at time.Time) float64 {
+	depth = math.Abs(depth)
+	at = at.UTC()
+
+	hour := float64(at.Hour()) + float64(at.Minute())/60 + float64(at.Second())/3600
+	diurnal := model.DiurnalAmplitude * math.Cos(2*math.Pi*(hour-model.DiurnalPeakHour)/24)
+	if model.DiurnalDepth > 0 {
+		diurnal *= math.Exp(-depth / model.DiurnalDepth)
+	}
+
+	seasonal := model.SeasonalAmplitude * math.Cos(2*math.Pi*(float64(at.YearDay())-model.SeasonalPeakDay)/daysInYear)
+
+	temperature := model.Surface + seasonal + diurnal
+
+	if depth > model.ThermoclineDepth {
+		temperature -= model.ThermoclineGradient * (depth - model.ThermoclineDepth)
+		// the deep water is colder than the surface in every season
+		temperature = math.Max(temperature, math.Min(model.Deep, model.Surface+seasonal))
+	}
+
+	return roundTemperature(temperature)
+}
+
+// GenerateTemperature returns a temperature the model gives at the depth at the time, the starting point of a new sensor.
+func GenerateTemperature(r *rand.Rand, model config.TemperatureModel, depth float64, at time.Time) float64 {
+	return roundTemperature(ProfileTemperature(model, depth, at) + r.NormFloat64()*model.Noise)
+}
+
+// Temperature is the simulated temperature of a single sensor, its deviation from the profile is a mean-reverting
+// random walk, so a reading is correlated with the previous one no matter how often the sensor reports.
+type Temperature struct {
+	rand      *rand.Rand
+	model     config.TemperatureModel
+	depth     float64
+	deviation float64
+	last      time.Time
+}
 
-	scaledFloat := randomFloat * 2
+// NewTemperature starts the simulation from the latest known temperature of the sensor.
+func NewTemperature(r *rand.Rand, model config.TemperatureModel, depth, latest float64, at time.Time) *Temperature {
+	deviation := latest - ProfileTemperature(model, depth, at)
 
-	result := scaledFloat + math.Abs(depth)*3
+	// a latest temperature far off the profile, e.g. generated by another model, is not carried over
+	if math.Abs(deviation) > 3*model.Noise {
+		deviation = r.NormFloat64() * model.Noise
+	}
+
+	return &Temperature{
+		rand:      r,
+		model:     model,
+		depth:     depth,
+		deviation: deviation,
+		last:      at,
+	}
+}
 
-	roundedResult := math.Round(result*1000) / 1000
+// Next moves the deviation on to the time and returns the temperature of the sensor.
+func (t *Temperature) Next(at time.Time) float64 {
+	elapsed := at.Sub(t.last)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	t.last = at
+
+	// the exact discretisation of the Ornstein-Uhlenbeck process, the deviation keeps its spread of Noise
+	decay := 0.0
+	if t.model.ReversionTime > 0 {
+		decay = math.Exp(-float64(elapsed) / float64(t.model.ReversionTime))
+	}
+
+	t.deviation = t.deviation*decay + t.model.Noise*math.Sqrt(1-decay*decay)*t.rand.NormFloat64()
+
+	return roundTemperature(ProfileTemperature(t.model, t.depth, at) + t.deviation)
+}
 
-	return roundedResult
+func roundTemperature(temperature float64) float64 {
+	return math.Round(temperature*1000) / 1000
 }
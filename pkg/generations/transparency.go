@@ -2,7 +2,7 @@ package generations
 
 import "math/rand"
 
-func GenerateTransparency(previousTransparency int) int {
+func GenerateTransparency(r *rand.Rand, previousTransparency int) int {
 	minTransparency := previousTransparency - 10
 	if minTransparency < 0 {
 		minTransparency = 0
@@ -12,5 +12,5 @@ func GenerateTransparency(previousTransparency int) int {
 		maxTransparency = 100
 	}
 
-	return rand.Intn(maxTransparency-minTransparency+1) + minTransparency
+	return r.Intn(maxTransparency-minTransparency+1) + minTransparency
 }
@@ -0,0 +1,77 @@
+package mqtt
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	paho "github.com/eclipse/paho.mqtt.golang"
+)
+
+// connectTimeout limits a single connection attempt.
+const connectTimeout = 10 * time.Second
+
+var ErrConnectTimeout = errors.New("timeout while connecting to the MQTT broker")
+
+// NewClient connects to the MQTT broker with attempts. Once connected, the client reconnects by itself
+// after a connection loss and onConnect is called after every (re)connection, so subscriptions can be renewed there.
+func NewClient(cfg *config.Config, onConnect paho.OnConnectHandler) (paho.Client, error) {
+	opts := paho.NewClientOptions().
+		AddBroker(cfg.MQTT.Broker).
+		SetClientID(cfg.MQTT.ClientID).
+		SetUsername(cfg.MQTT.Username).
+		SetPassword(cfg.MQTT.Password).
+		SetAutoReconnect(true).
+		SetMaxReconnectInterval(cfg.MQTT.MaxReconnectInterval).
+		SetOnConnectHandler(onConnect).
+		SetConnectionLostHandler(func(_ paho.Client, err error) {
+			log.Printf("connection to the MQTT broker is lost: %v. Reconnecting...", err)
+		})
+
+	client := paho.NewClient(opts)
+
+	err := DoWithBackoff(func() error {
+		token := client.Connect()
+		if !token.WaitTimeout(connectTimeout) {
+			fmt.Println("failed to connect to the MQTT broker... Going to do the next attempt")
+			return ErrConnectTimeout
+		}
+
+		if err := token.Error(); err != nil {
+			fmt.Println("failed to connect to the MQTT broker... Going to do the next attempt")
+			return err
+		}
+
+		return nil
+	}, cfg.MQTT.MaxAttempts, cfg.MQTT.RetryDelay, cfg.MQTT.MaxRetryDelay)
+	if err != nil {
+		return nil, fmt.Errorf("all attempts are exceeded, unable to connect to the MQTT broker: %w", err)
+	}
+
+	return client, nil
+}
+
+// DoWithBackoff works like postgres.DoWithTries, but doubles the delay after every failed attempt up to maxDelay.
+func DoWithBackoff(fn func() error, attempts int, delay, maxDelay time.Duration) (err error) {
+	for attempts > 0 {
+		if err = fn(); err != nil {
+			attempts--
+			if attempts == 0 {
+				break
+			}
+
+			time.Sleep(delay)
+
+			delay *= 2
+			if delay > maxDelay {
+				delay = maxDelay
+			}
+
+			continue
+		}
+		return nil
+	}
+	return
+}
@@ -1,11 +1,72 @@
 package postgres
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
 
 	"github.com/jackc/pgx/v5/pgconn"
 )
 
+// wrappedError is an error with the message of its wrapper, it unwraps to its cause,
+// so the callers can still tell what failed, e.g. by IsTransient.
+type wrappedError struct {
+	message string
+	cause   error
+}
+
+func (e *wrappedError) Error() string {
+	return e.message
+}
+
+func (e *wrappedError) Unwrap() error {
+	return e.cause
+}
+
+func wrap(cause error, format string, a ...any) error {
+	return &wrappedError{message: fmt.Sprintf(format, a...), cause: cause}
+}
+
+// IsTransient reports whether the failed operation may succeed if it is retried: the connection failed or timed out,
+// the transaction lost a serialization conflict or a deadlock, or the server is short of resources or restarting.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// connection exception, transaction rollback, insufficient resources and operator intervention
+		switch pgErr.Code[:2] {
+		case "08", "40", "53", "57":
+			return true
+		}
+
+		return false
+	}
+
+	var netErr net.Error
+
+	return pgconn.SafeToRetry(err) || pgconn.Timeout(err) || errors.As(err, &netErr)
+}
+
+// IsForeignKeyViolation reports whether the operation failed as a row it writes refers to a missing one,
+// e.g. a measurement of a sensor deleted meanwhile.
+func IsForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
+
+// IsUniqueViolation reports whether the operation failed as a row it writes conflicts with a row of the constraint,
+// e.g. a measurement written already under the same id.
+func IsUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == constraint
+}
+
 // parsePgError returns parsed pgconn.PgError.
 // If err is not pgconn.PgError, returns the same err.
 func parsePgError(err error) error {
@@ -19,29 +80,29 @@ func parsePgError(err error) error {
 }
 
 func ErrCommit(err error) error {
-	return fmt.Errorf("failed to commit Tx due to error: %v", err)
+	return wrap(err, "failed to commit Tx due to error: %v", err)
 }
 
 func ErrRollback(err error) error {
-	return fmt.Errorf("failed to rollback Tx due to error: %v", err)
+	return wrap(err, "failed to rollback Tx due to error: %v", err)
 }
 
 func ErrCreateTx(err error) error {
-	return fmt.Errorf("failed to create Tx due to error: %v", err)
+	return wrap(err, "failed to create Tx due to error: %v", err)
 }
 
 func ErrCreateQuery(err error) error {
-	return fmt.Errorf("failed to create SQL Query due to error: %v", parsePgError(err))
+	return wrap(err, "failed to create SQL Query due to error: %v", parsePgError(err))
 }
 
 func ErrScan(err error) error {
-	return fmt.Errorf("failed to scan due to error: %v", parsePgError(err))
+	return wrap(err, "failed to scan due to error: %v", parsePgError(err))
 }
 
 func ErrDoQuery(err error) error {
-	return fmt.Errorf("failed to query due to error: %v", parsePgError(err))
+	return wrap(err, "failed to query due to error: %v", parsePgError(err))
 }
 
 func ErrExecQuery(err error) error {
-	return fmt.Errorf("failed to  execute query due to error: %v", parsePgError(err))
+	return wrap(err, "failed to  execute query due to error: %v", parsePgError(err))
 }
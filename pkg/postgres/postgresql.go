@@ -12,7 +12,8 @@ import (
 
 // NewClient Create Postgres pgx connection with attempts
 func NewClient(ctx context.Context, cfg *config.Config) (pool *pgxpool.Pool, err error) {
-	dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/%s", cfg.Postgres.Username, cfg.Postgres.Password, cfg.Postgres.Host, cfg.Postgres.Port, cfg.Postgres.Database)
+	// the timestamps are stored without time zone in UTC, LOCALTIMESTAMP of the session must be in UTC too
+	dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?timezone=UTC", cfg.Postgres.Username, cfg.Postgres.Password, cfg.Postgres.Host, cfg.Postgres.Port, cfg.Postgres.Database)
 
 	if len(dsn) < 40 {
 		return nil, fmt.Errorf("wrong connection sring")
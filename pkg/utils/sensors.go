@@ -2,9 +2,11 @@ package utils
 
 import (
 	"context"
+	"errors"
 	"strings"
 
 	"github.com/PavelDonchenko/sensor-go/internal/storage"
+	"github.com/PavelDonchenko/sensor-go/pkg/generations"
 )
 
 func GenerateSensors(ctx context.Context, db storage.Database) error {
@@ -23,3 +25,24 @@ func GenerateSensors(ctx context.Context, db storage.Database) error {
 	}
 	return nil
 }
+
+// GenerateScenarioSensors creates the groups and places the sensors of the scenario under their codenames.
+func GenerateScenarioSensors(ctx context.Context, db storage.Database, scenario *generations.Scenario) error {
+	for _, group := range scenario.Groups {
+		_, err := db.CreateGroup(ctx, group.Name)
+		if err != nil && !errors.Is(err, storage.ErrGroupExists) {
+			return err
+		}
+	}
+
+	r := generations.NewSeeds(db.Cfg.Simulation.Seed).Rand("sensors")
+
+	for _, sensor := range scenario.Sensors(r, db.Clock.Now()) {
+		_, err := db.CreateSensor(ctx, sensor)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -5,10 +5,14 @@ import (
 	"time"
 )
 
+// TimeLayout is the layout of the date/time strings passed to the storage queries.
+const TimeLayout = "2006-01-02 15:04:05.000000"
+
 func ParseUnixToString(unix string) string {
 	intUnix, _ := strconv.ParseInt(unix, 10, 64)
 
-	t := time.Unix(intUnix, 0)
+	// the stored timestamps are in UTC
+	t := time.Unix(intUnix, 0).UTC()
 
-	return t.Format("2006-01-02 15:04:05.000000")
+	return t.Format(TimeLayout)
 }
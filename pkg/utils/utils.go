@@ -8,6 +8,9 @@ import (
 func ParseCodename(s string) (string, int) {
 	r := regexp.MustCompile(`^(\D+)(\d+)$`)
 	matches := r.FindStringSubmatch(s)
+	if matches == nil {
+		return "", 0
+	}
 
 	alpha := matches[1]
 	numStr := matches[2]
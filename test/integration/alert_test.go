@@ -0,0 +1,193 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/pkg/logging"
+	"github.com/PavelDonchenko/sensor-go/workers"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type AlertTestSuite struct {
+	TestSuite
+}
+
+func TestAlertSuite(t *testing.T) {
+	suite.Run(t, new(AlertTestSuite))
+}
+
+func (r *AlertTestSuite) TestAlertRules() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+
+	}()
+
+	testCases := []struct {
+		name               string
+		method             string
+		url                string
+		body               string
+		expectedStatusCode int
+	}{
+		{
+			name:               "OK group temperature rule",
+			method:             http.MethodPost,
+			url:                "/api/v1/alerts/rules",
+			body:               `{"name": "hot gamma", "metric": "temperature", "group_name": "gamma", "operator": ">", "threshold": 30, "consecutive": 2}`,
+			expectedStatusCode: 201,
+		},
+		{
+			name:               "OK species rule",
+			method:             http.MethodPost,
+			url:                "/api/v1/alerts/rules",
+			body:               `{"name": "sailfish", "metric": "species", "species": "Sailfish", "codename": "alpha1", "operator": ">", "threshold": 15}`,
+			expectedStatusCode: 201,
+		},
+		{
+			name:               "OK aggregated rule",
+			method:             http.MethodPost,
+			url:                "/api/v1/alerts/rules",
+			body:               `{"name": "murky alpha", "metric": "transparency", "group_name": "alpha", "aggregation": "avg", "operator": "<", "threshold": 20}`,
+			expectedStatusCode: 201,
+		},
+		{
+			name:               "OK rules",
+			method:             http.MethodGet,
+			url:                "/api/v1/alerts/rules",
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "OK alerts",
+			method:             http.MethodGet,
+			url:                "/api/v1/alerts?state=firing&from=0",
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "error unknown group",
+			method:             http.MethodPost,
+			url:                "/api/v1/alerts/rules",
+			body:               `{"name": "hot", "metric": "temperature", "group_name": "omega", "operator": ">", "threshold": 30}`,
+			expectedStatusCode: 404,
+		},
+		{
+			name:               "error unknown region",
+			method:             http.MethodPost,
+			url:                "/api/v1/alerts/rules",
+			body:               `{"name": "hot", "metric": "temperature", "region": "nowhere", "operator": ">", "threshold": 30}`,
+			expectedStatusCode: 404,
+		},
+		{
+			name:               "error wrong operator",
+			method:             http.MethodPost,
+			url:                "/api/v1/alerts/rules",
+			body:               `{"name": "hot", "metric": "temperature", "operator": "=>", "threshold": 30}`,
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "error species rule without species",
+			method:             http.MethodPost,
+			url:                "/api/v1/alerts/rules",
+			body:               `{"name": "fish", "metric": "species", "operator": ">", "threshold": 15}`,
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "error several scopes",
+			method:             http.MethodPost,
+			url:                "/api/v1/alerts/rules",
+			body:               `{"name": "hot", "metric": "temperature", "group_name": "alpha", "codename": "alpha1", "operator": ">", "threshold": 30}`,
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "error wrong alert state",
+			method:             http.MethodGet,
+			url:                "/api/v1/alerts?state=pending",
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "error unknown rule",
+			method:             http.MethodDelete,
+			url:                "/api/v1/alerts/rules/100000",
+			expectedStatusCode: 404,
+		},
+	}
+
+	for _, test := range testCases {
+		r.Run(test.name, func() {
+			app := fiber.New()
+
+			req, _ := http.NewRequest(test.method, test.url, strings.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			r.handler.Register(app)
+
+			resp, _ := app.Test(req, -1)
+
+			assert.Equal(r.T(), test.expectedStatusCode, resp.StatusCode)
+		})
+	}
+}
+
+func (r *AlertTestSuite) TestAlertFiresAndResolves() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rule, err := r.sensorService.CreateAlertRule(ctx, domain.CreateAlertRule{
+		Name:        "hot alpha1",
+		Metric:      domain.MetricTemperature,
+		Codename:    "alpha1",
+		Operator:    domain.OperatorGreater,
+		Threshold:   30,
+		Consecutive: 2,
+	})
+	assert.NoError(r.T(), err)
+
+	cfg := config.GetConfig("../../config.yaml")
+
+	evaluator := workers.NewAlertEvaluator(ctx, r.sensorStorage, logging.GetLogger(), *cfg, r.hub)
+	go evaluator.Process()
+
+	// the evaluator subscribes before it loads the rules
+	time.Sleep(100 * time.Millisecond)
+
+	alerts := func(state domain.AlertState) func() bool {
+		return func() bool {
+			alerts, err := r.sensorService.GetAlerts(ctx, domain.AlertQuery{RuleID: rule.ID, State: state, Limit: 10})
+			return err == nil && len(alerts) == 1
+		}
+	}
+
+	ingest := func(temperature float64) {
+		_, err := r.sensorService.IngestMeasurements(ctx, "alpha1", []domain.IngestMeasurement{
+			{Temperature: &temperature, Transparency: new(int)},
+		})
+		assert.NoError(r.T(), err)
+	}
+
+	ingest(35)
+	ingest(36)
+	assert.Eventually(r.T(), alerts(domain.AlertFiring), time.Second, 10*time.Millisecond)
+
+	ingest(20)
+	assert.Eventually(r.T(), alerts(domain.AlertResolved), time.Second, 10*time.Millisecond)
+}
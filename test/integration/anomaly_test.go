@@ -0,0 +1,188 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/internal/stream"
+	"github.com/PavelDonchenko/sensor-go/pkg/logging"
+	"github.com/PavelDonchenko/sensor-go/workers"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type AnomalyTestSuite struct {
+	TestSuite
+}
+
+func TestAnomalySuite(t *testing.T) {
+	suite.Run(t, new(AnomalyTestSuite))
+}
+
+func (r *AnomalyTestSuite) TestGetAnomalies() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+
+	}()
+
+	testCases := []struct {
+		name               string
+		url                string
+		expectedStatusCode int
+	}{
+		{
+			name:               "OK anomalies",
+			url:                "/api/v1/anomalies",
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "OK anomalies of a group for period",
+			url:                "/api/v1/anomalies?group=alpha&from=0&till=4102444800",
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "error unknown group",
+			url:                "/api/v1/anomalies?group=omega",
+			expectedStatusCode: 404,
+		},
+		{
+			name:               "error wrong period",
+			url:                "/api/v1/anomalies?from=4102444800&till=0",
+			expectedStatusCode: 422,
+		},
+	}
+
+	for _, test := range testCases {
+		r.Run(test.name, func() {
+			app := fiber.New()
+
+			req, _ := http.NewRequest(http.MethodGet, test.url, http.NoBody)
+
+			r.handler.Register(app)
+
+			resp, _ := app.Test(req, -1)
+
+			assert.Equal(r.T(), test.expectedStatusCode, resp.StatusCode)
+		})
+	}
+}
+
+func (r *AnomalyTestSuite) TestDetectAnomaly() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+
+	}()
+
+	ctx := context.Background()
+
+	go r.detector.Process()
+
+	sub := r.sensorService.SubscribeReadings(stream.Filter{Codename: "alpha1"})
+	defer r.sensorService.UnsubscribeReadings(sub)
+
+	transparency := 50
+	measurements := make([]domain.IngestMeasurement, 0)
+
+	// the sensor is scored against itself from the tenth reading on
+	for _, temperature := range []float64{12, 12.2, 11.9, 12.1, 12, 11.8, 12, 12.1, 11.9, 12, 40} {
+		temperature := temperature
+		measurements = append(measurements, domain.IngestMeasurement{Temperature: &temperature, Transparency: &transparency})
+	}
+
+	saved, err := r.sensorService.IngestMeasurements(ctx, "alpha1", measurements)
+	assert.NoError(r.T(), err)
+	assert.Len(r.T(), saved, len(measurements))
+
+	// every saved and published reading carries its score, only the last one scores above the threshold
+	for i, measurement := range saved {
+		reading := <-sub.C()
+
+		assert.NotNil(r.T(), measurement.AnomalyScore)
+		assert.Equal(r.T(), measurement.AnomalyScore, reading.AnomalyScore)
+
+		if i < len(saved)-1 {
+			assert.Less(r.T(), *measurement.AnomalyScore, 3.0)
+		} else {
+			assert.Greater(r.T(), *measurement.AnomalyScore, 3.0)
+		}
+	}
+
+	var stored *float64
+	err = r.sensorStorage.DB.QueryRow(ctx, "SELECT anomaly_score FROM measurement WHERE id = $1", saved[len(saved)-1].ID).
+		Scan(&stored)
+	assert.NoError(r.T(), err)
+	assert.Equal(r.T(), saved[len(saved)-1].AnomalyScore, stored)
+
+	assert.Eventually(r.T(), func() bool {
+		anomalies, err := r.sensorService.GetAnomalies(ctx, domain.AnomalyQuery{GroupName: "alpha", Limit: 10})
+		return err == nil && len(anomalies) == 1 &&
+			anomalies[0].Metric == domain.MetricTemperature && anomalies[0].Value == 40 &&
+			anomalies[0].MeasurementID == saved[len(saved)-1].ID
+	}, time.Second, 10*time.Millisecond)
+}
+
+func (r *AnomalyTestSuite) TestSpatialNeighboursIn3D() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+
+	}()
+
+	ctx := context.Background()
+
+	// the sensor below the scored one is the nearest on the surface, but the farthest in the water
+	coordinates := []domain.Coordinates{
+		{X: 100000, Y: 100000, Z: -10},
+		{X: 100100, Y: 100000, Z: -10},
+		{X: 100000, Y: 100100, Z: -10},
+		{X: 100000, Y: 100000, Z: -510},
+	}
+
+	sensors := make([]*domain.Sensor, 0, len(coordinates))
+	for _, c := range coordinates {
+		sensor, err := r.sensorService.CreateSensor(ctx, domain.CreateSensor{
+			GroupName:      "alpha",
+			Source:         domain.SourceLive,
+			DataOutputRate: 1,
+			Coordinates:    c,
+		})
+		assert.NoError(r.T(), err)
+
+		sensors = append(sensors, sensor)
+	}
+
+	cfg := config.GetConfig("../../config.yaml")
+	cfg.Anomaly.Neighbours = 2
+	cfg.Anomaly.MinSamples = 1000
+
+	detector := workers.NewAnomalyDetector(ctx, r.sensorStorage, logging.GetLogger(), *cfg, nil)
+	detector.LoadNeighbours()
+
+	for i, temperature := range []float64{20, 20.2, 4} {
+		detector.Score(&domain.Measurement{SensorID: sensors[i+1].ID, Temperature: temperature, Transparency: 50})
+	}
+
+	// the scored sensor agrees with the two sensors at its depth, the one below is not compared
+	measurement := domain.Measurement{SensorID: sensors[0].ID, Temperature: 20.1, Transparency: 50}
+	anomalies := detector.Score(&measurement)
+
+	assert.Empty(r.T(), anomalies)
+	assert.NotNil(r.T(), measurement.AnomalyScore)
+	assert.InDelta(r.T(), 0, *measurement.AnomalyScore, 1e-9)
+}
@@ -0,0 +1,81 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/PavelDonchenko/sensor-go/pkg/generations"
+	"github.com/PavelDonchenko/sensor-go/pkg/logging"
+	"github.com/PavelDonchenko/sensor-go/workers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type BackfillTestSuite struct {
+	TestSuite
+}
+
+func TestBackfillSuite(t *testing.T) {
+	suite.Run(t, new(BackfillTestSuite))
+}
+
+func (r *BackfillTestSuite) TestBackfill() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+
+	}()
+
+	ctx := context.Background()
+
+	cfg := config.GetConfig("../../config.yaml")
+	cfg.Simulation.Seed = 11
+	cfg.Backfill.BatchSize = 100
+
+	species, err := generations.LoadSpecies(cfg.Species.Catalogue)
+	assert.NoError(r.T(), err)
+
+	sensors, err := r.sensorStorage.GetAllSensors(ctx)
+	assert.NoError(r.T(), err)
+
+	till := time.Date(2023, time.July, 14, 0, 0, 0, 0, time.UTC)
+	from := till.Add(-time.Hour)
+
+	expected := 0
+	for _, sensor := range sensors {
+		expected += int(time.Hour / (time.Duration(sensor.DataOutputRate) * time.Second))
+	}
+
+	backfill := workers.NewBackfill(ctx, r.sensorStorage, logging.GetLogger(), *cfg, species, nil, nil)
+
+	count, err := backfill.Run(from, till)
+	assert.NoError(r.T(), err)
+	assert.Equal(r.T(), expected, count)
+
+	var saved int
+	err = r.sensorStorage.DB.QueryRow(ctx, "SELECT count(*) FROM measurement WHERE created_at > $1 AND created_at <= $2",
+		from, till).Scan(&saved)
+	assert.NoError(r.T(), err)
+	assert.Equal(r.T(), expected, saved)
+
+	// the history is older than the seeded data, the latest data of the sensors is kept
+	after, err := r.sensorStorage.GetAllSensors(ctx)
+	assert.NoError(r.T(), err)
+
+	latest := make(map[string]float64, len(sensors))
+	for _, sensor := range sensors {
+		latest[sensor.Codename.String()] = sensor.Temperature
+	}
+
+	for _, sensor := range after {
+		assert.Equal(r.T(), latest[sensor.Codename.String()], sensor.Temperature)
+	}
+
+	_, err = backfill.Run(till, from)
+	assert.ErrorIs(r.T(), err, workers.ErrorWrongBackfillPeriod)
+}
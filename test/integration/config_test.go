@@ -0,0 +1,45 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// ConfigTestSuite checks the config validation, it needs neither the database nor the cache.
+type ConfigTestSuite struct {
+	suite.Suite
+}
+
+func TestConfigSuite(t *testing.T) {
+	suite.Run(t, new(ConfigTestSuite))
+}
+
+func (r *ConfigTestSuite) TestValidate() {
+	testCases := []struct {
+		name   string
+		change func(cfg *config.Config)
+	}{
+		{name: "error simulation speed", change: func(cfg *config.Config) { cfg.Simulation.Speed = 0 }},
+		{name: "error scheduler tick", change: func(cfg *config.Config) { cfg.Scheduler.Tick = 0 }},
+		{name: "error scheduler workers", change: func(cfg *config.Config) { cfg.Scheduler.Workers = -1 }},
+		{name: "error scheduler batch size", change: func(cfg *config.Config) { cfg.Scheduler.BatchSize = 0 }},
+		{name: "error backfill batch size", change: func(cfg *config.Config) { cfg.Backfill.BatchSize = 0 }},
+		{name: "error replay speed", change: func(cfg *config.Config) { cfg.Replay.Speed = -2 }},
+		{name: "error ingestion max age", change: func(cfg *config.Config) { cfg.Ingestion.MaxAge = 0 }},
+	}
+
+	cfg := config.GetConfig("../../config.yaml")
+	assert.NoError(r.T(), cfg.Validate())
+
+	for _, test := range testCases {
+		r.Run(test.name, func() {
+			changed := *cfg
+			test.change(&changed)
+
+			assert.Error(r.T(), changed.Validate())
+		})
+	}
+}
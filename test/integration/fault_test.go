@@ -0,0 +1,416 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/internal/handler"
+	"github.com/PavelDonchenko/sensor-go/pkg/generations"
+	"github.com/PavelDonchenko/sensor-go/pkg/logging"
+	"github.com/PavelDonchenko/sensor-go/workers"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type FaultTestSuite struct {
+	TestSuite
+}
+
+func TestFaultSuite(t *testing.T) {
+	suite.Run(t, new(FaultTestSuite))
+}
+
+func (r *FaultTestSuite) TestFaultRules() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+
+	}()
+
+	testCases := []struct {
+		name               string
+		method             string
+		url                string
+		body               string
+		apiKey             string
+		expectedStatusCode int
+	}{
+		{
+			name:               "OK group spike rule",
+			method:             http.MethodPost,
+			url:                "/api/v1/admin/faults",
+			body:               `{"mode": "spike", "group_name": "gamma", "probability": 0.05, "magnitude": 10}`,
+			apiKey:             "admin-secret",
+			expectedStatusCode: 201,
+		},
+		{
+			name:               "OK sensor outage rule",
+			method:             http.MethodPost,
+			url:                "/api/v1/admin/faults",
+			body:               `{"mode": "outage", "codename": "alpha1", "probability": 0.01, "duration": 300, "enabled": false}`,
+			apiKey:             "admin-secret",
+			expectedStatusCode: 201,
+		},
+		{
+			name:               "OK negative drift rule",
+			method:             http.MethodPost,
+			url:                "/api/v1/admin/faults",
+			body:               `{"mode": "drift", "codename": "alpha2", "probability": 0.01, "duration": 3600, "magnitude": -2}`,
+			apiKey:             "admin-secret",
+			expectedStatusCode: 201,
+		},
+		{
+			name:               "OK out of range rule",
+			method:             http.MethodPost,
+			url:                "/api/v1/admin/faults",
+			body:               `{"mode": "out_of_range", "codename": "alpha3", "probability": 0.01}`,
+			apiKey:             "admin-secret",
+			expectedStatusCode: 201,
+		},
+		{
+			name:               "OK rules",
+			method:             http.MethodGet,
+			url:                "/api/v1/admin/faults",
+			apiKey:             "admin-secret",
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "OK active faults",
+			method:             http.MethodGet,
+			url:                "/api/v1/admin/faults/active",
+			apiKey:             "admin-secret",
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "OK enable rule",
+			method:             http.MethodPatch,
+			url:                "/api/v1/admin/faults/1",
+			body:               `{"enabled": true}`,
+			apiKey:             "admin-secret",
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "error missing API key",
+			method:             http.MethodGet,
+			url:                "/api/v1/admin/faults",
+			expectedStatusCode: 401,
+		},
+		{
+			name:               "error wrong API key",
+			method:             http.MethodPost,
+			url:                "/api/v1/admin/faults",
+			body:               `{"mode": "dropout", "probability": 0.1}`,
+			apiKey:             "ingestion-secret",
+			expectedStatusCode: 401,
+		},
+		{
+			name:               "error unknown mode",
+			method:             http.MethodPost,
+			url:                "/api/v1/admin/faults",
+			body:               `{"mode": "melt", "probability": 0.1}`,
+			apiKey:             "admin-secret",
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "error wrong probability",
+			method:             http.MethodPost,
+			url:                "/api/v1/admin/faults",
+			body:               `{"mode": "dropout", "probability": 1.5}`,
+			apiKey:             "admin-secret",
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "error stuck without duration",
+			method:             http.MethodPost,
+			url:                "/api/v1/admin/faults",
+			body:               `{"mode": "stuck", "probability": 0.1}`,
+			apiKey:             "admin-secret",
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "error negative spike",
+			method:             http.MethodPost,
+			url:                "/api/v1/admin/faults",
+			body:               `{"mode": "spike", "probability": 0.1, "magnitude": -10}`,
+			apiKey:             "admin-secret",
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "error drift without magnitude",
+			method:             http.MethodPost,
+			url:                "/api/v1/admin/faults",
+			body:               `{"mode": "drift", "probability": 0.1, "duration": 3600}`,
+			apiKey:             "admin-secret",
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "error unknown group",
+			method:             http.MethodPost,
+			url:                "/api/v1/admin/faults",
+			body:               `{"mode": "dropout", "group_name": "omega", "probability": 0.1}`,
+			apiKey:             "admin-secret",
+			expectedStatusCode: 404,
+		},
+		{
+			name:               "error unknown rule",
+			method:             http.MethodDelete,
+			url:                "/api/v1/admin/faults/100000",
+			apiKey:             "admin-secret",
+			expectedStatusCode: 404,
+		},
+	}
+
+	for _, test := range testCases {
+		r.Run(test.name, func() {
+			app := fiber.New()
+
+			req, _ := http.NewRequest(test.method, test.url, strings.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/json")
+			if test.apiKey != "" {
+				req.Header.Set("X-API-Key", test.apiKey)
+			}
+
+			r.handler.Register(app)
+
+			resp, _ := app.Test(req, -1)
+
+			assert.Equal(r.T(), test.expectedStatusCode, resp.StatusCode)
+		})
+	}
+}
+
+func (r *FaultTestSuite) TestAdminDisabledWithoutAPIKey() {
+	cfg := config.GetConfig("../../config.yaml")
+	cfg.Admin.APIKey = ""
+
+	app := fiber.New()
+
+	handler.NewHandler(context.Background(), *cfg, r.sensorService).Register(app)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/admin/faults", nil)
+	req.Header.Set("X-API-Key", "")
+
+	resp, _ := app.Test(req, -1)
+
+	assert.Equal(r.T(), http.StatusNotFound, resp.StatusCode)
+}
+
+func (r *FaultTestSuite) TestFaultsTagged() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+
+	}()
+
+	ctx := context.Background()
+
+	cfg := config.GetConfig("../../config.yaml")
+	cfg.Simulation.Seed = 5
+	cfg.Faults.Rules = nil
+
+	species, err := generations.LoadSpecies(cfg.Species.Catalogue)
+	assert.NoError(r.T(), err)
+
+	faults, err := generations.NewFaults(*cfg)
+	assert.NoError(r.T(), err)
+
+	faults.AddRule(domain.FaultRule{Mode: domain.FaultSpike, GroupName: "alpha", Probability: 1, Magnitude: 5, Enabled: true})
+	faults.AddRule(domain.FaultRule{Mode: domain.FaultDropout, Codename: "gamma1", Probability: 1, Enabled: true})
+
+	sensors, err := r.sensorStorage.GetAllSensors(ctx)
+	assert.NoError(r.T(), err)
+
+	till := time.Date(2023, time.July, 14, 0, 0, 0, 0, time.UTC)
+	from := till.Add(-time.Hour)
+
+	spiked := 0
+	for _, sensor := range sensors {
+		if sensor.Codename.Name == "alpha" {
+			spiked += int(time.Hour / (time.Duration(sensor.DataOutputRate) * time.Second))
+		}
+	}
+
+	_, err = workers.NewBackfill(ctx, r.sensorStorage, logging.GetLogger(), *cfg, species, faults, nil).Run(from, till)
+	assert.NoError(r.T(), err)
+
+	var tagged int
+	err = r.sensorStorage.DB.QueryRow(ctx, "SELECT count(*) FROM measurement WHERE fault = 'spike' AND created_at > $1",
+		from).Scan(&tagged)
+	assert.NoError(r.T(), err)
+	assert.Equal(r.T(), spiked, tagged)
+
+	var dropped int
+	err = r.sensorStorage.DB.QueryRow(ctx, `SELECT count(*) FROM measurement m JOIN sensor s ON s.id = m.sensorid
+		WHERE s.group_name = 'gamma' AND s.in_group_id = 1 AND m.created_at > $1`, from).Scan(&dropped)
+	assert.NoError(r.T(), err)
+	assert.Zero(r.T(), dropped)
+}
+
+func (r *FaultTestSuite) TestInvalidFaultSavedAsNull() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+
+	}()
+
+	ctx := context.Background()
+
+	cfg := config.GetConfig("../../config.yaml")
+	cfg.Simulation.Seed = 5
+	cfg.Faults.Rules = nil
+
+	species, err := generations.LoadSpecies(cfg.Species.Catalogue)
+	assert.NoError(r.T(), err)
+
+	faults, err := generations.NewFaults(*cfg)
+	assert.NoError(r.T(), err)
+
+	faults.AddRule(domain.FaultRule{Mode: domain.FaultInvalid, Codename: "alpha1", Probability: 1, Enabled: true})
+
+	sensor, err := r.sensorStorage.GetSensor(ctx, "alpha", 1)
+	assert.NoError(r.T(), err)
+
+	till := time.Date(2023, time.July, 14, 0, 0, 0, 0, time.UTC)
+	from := till.Add(-time.Hour)
+
+	_, err = workers.NewBackfill(ctx, r.sensorStorage, logging.GetLogger(), *cfg, species, faults, nil).Run(from, till)
+	assert.NoError(r.T(), err)
+
+	var invalid, null int
+	err = r.sensorStorage.DB.QueryRow(ctx, `SELECT count(*), count(*) FILTER (WHERE temperature IS NULL) FROM measurement
+		WHERE fault = 'invalid' AND sensorid = $1 AND created_at > $2`, sensor.ID, from).Scan(&invalid, &null)
+	assert.NoError(r.T(), err)
+	assert.Equal(r.T(), int(time.Hour/(time.Duration(sensor.DataOutputRate)*time.Second)), invalid)
+	assert.Equal(r.T(), invalid, null)
+
+	// the readings carry the NaN temperature as null
+	payload, err := json.Marshal(domain.NewReading(*sensor, domain.Measurement{Temperature: math.NaN(), Fault: "invalid"}))
+	assert.NoError(r.T(), err)
+	assert.Contains(r.T(), string(payload), `"temperature":null`)
+
+	var reading domain.Reading
+	err = json.Unmarshal(payload, &reading)
+	assert.NoError(r.T(), err)
+	assert.True(r.T(), math.IsNaN(reading.Temperature))
+}
+
+func (r *FaultTestSuite) TestOutOfRangeFaultSaved() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+
+	}()
+
+	ctx := context.Background()
+
+	cfg := config.GetConfig("../../config.yaml")
+	cfg.Simulation.Seed = 5
+	cfg.Faults.Rules = nil
+
+	species, err := generations.LoadSpecies(cfg.Species.Catalogue)
+	assert.NoError(r.T(), err)
+
+	faults, err := generations.NewFaults(*cfg)
+	assert.NoError(r.T(), err)
+
+	faults.AddRule(domain.FaultRule{Mode: domain.FaultOutOfRange, Codename: "alpha1", Probability: 1, Enabled: true})
+
+	sensor, err := r.sensorStorage.GetSensor(ctx, "alpha", 1)
+	assert.NoError(r.T(), err)
+
+	till := time.Date(2023, time.July, 14, 0, 0, 0, 0, time.UTC)
+	from := till.Add(-time.Hour)
+
+	_, err = workers.NewBackfill(ctx, r.sensorStorage, logging.GetLogger(), *cfg, species, faults, nil).Run(from, till)
+	assert.NoError(r.T(), err)
+
+	var tagged, outOfRange int
+	err = r.sensorStorage.DB.QueryRow(ctx, `SELECT count(*), count(*) FILTER (WHERE temperature = -127) FROM measurement
+		WHERE fault = 'out_of_range' AND sensorid = $1 AND created_at > $2`, sensor.ID, from).Scan(&tagged, &outOfRange)
+	assert.NoError(r.T(), err)
+	assert.Equal(r.T(), int(time.Hour/(time.Duration(sensor.DataOutputRate)*time.Second)), tagged)
+	assert.Equal(r.T(), tagged, outOfRange)
+}
+
+func (r *FaultTestSuite) TestUpdatedSensorKeepsItsFault() {
+	err := Truncate(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+	}()
+
+	r.sensorStorage.Clock = generations.NewManualClock(simulationStart)
+
+	err = SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := config.GetConfig("../../config.yaml")
+	cfg.Faults.Rules = nil
+
+	species, err := generations.LoadSpecies(cfg.Species.Catalogue)
+	assert.NoError(r.T(), err)
+
+	faults, err := generations.NewFaults(*cfg)
+	assert.NoError(r.T(), err)
+
+	faults.AddRule(domain.FaultRule{Mode: domain.FaultStuck, Codename: "alpha1", Probability: 1, Duration: 3600, Enabled: true})
+
+	sensor, err := r.sensorStorage.GetSensor(ctx, "alpha", 1)
+	assert.NoError(r.T(), err)
+
+	sensors, err := r.sensorStorage.GetAllSensors(ctx)
+	assert.NoError(r.T(), err)
+
+	due := 0
+	for _, s := range sensors {
+		due += int(time.Minute / (time.Duration(s.DataOutputRate) * time.Second))
+	}
+
+	clock := generations.NewManualClock(simulationStart)
+
+	worker := workers.NewWorker(ctx, r.sensorStorage, logging.GetLogger(), *cfg, nil, nil, species, faults, nil, clock, nil)
+	worker.Process()
+
+	clock.Advance(time.Minute)
+
+	assert.Eventually(r.T(), func() bool {
+		stats := worker.SchedulerStats()
+		return stats.Written+stats.Lost+stats.Failed == uint64(due)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	active := faults.Active()
+	assert.Len(r.T(), active, 1)
+
+	// a changed output rate restarts the generation, the sensor is stuck still
+	sensor.DataOutputRate++
+	worker.SensorUpdated(*sensor)
+	assert.Equal(r.T(), active, faults.Active())
+
+	worker.SensorDeleted(sensor.ID)
+	assert.Empty(r.T(), faults.Active())
+}
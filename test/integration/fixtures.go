@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
 	"github.com/PavelDonchenko/sensor-go/internal/storage"
 	"github.com/PavelDonchenko/sensor-go/pkg/utils"
 )
@@ -21,7 +22,17 @@ func SeedData(db storage.Database) error {
 	}
 
 	for _, sensor := range sensors {
-		err = db.UpdateSensorData(ctx, sensor)
+		measurement := domain.Measurement{
+			SensorID:     sensor.ID,
+			Temperature:  sensor.Temperature,
+			Transparency: sensor.Transparency,
+			DetectedFish: []domain.DetectedFish{
+				{Name: "Atlantic Cod", Count: 12},
+				{Name: "Sailfish", Count: 4},
+			},
+		}
+
+		_, err = db.SaveMeasurement(ctx, measurement)
 		if err != nil {
 			fmt.Print(err)
 			return err
@@ -32,7 +43,7 @@ func SeedData(db storage.Database) error {
 }
 
 func Truncate(db storage.Database) error {
-	_, err := db.DB.Exec(context.Background(), "TRUNCATE table sensor_group, sensor, detected_fish, temperature")
+	_, err := db.DB.Exec(context.Background(), "TRUNCATE table sensor_group, sensor, detected_fish, measurement, temperature_rollup, transparency_rollup, species_rollup, rollup_watermark, region, alert_rule, alert, webhook, webhook_delivery, webhook_dead_letter, anomaly")
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,138 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/PavelDonchenko/sensor-go/internal/handler"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type IngestionTestSuite struct {
+	TestSuite
+}
+
+func TestIngestionSuite(t *testing.T) {
+	suite.Run(t, new(IngestionTestSuite))
+}
+
+func (r *IngestionTestSuite) TestIngestMeasurements() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+
+	}()
+
+	now := time.Now()
+
+	testCases := []struct {
+		name               string
+		codename           string
+		apiKey             string
+		body               string
+		expectedStatusCode int
+	}{
+		{
+			name:               "OK single measurement",
+			codename:           "alpha1",
+			apiKey:             "ingestion-secret",
+			body:               `{"temperature": 12.5, "transparency": 80, "detected_fish": [{"name": "Atlantic Cod", "count": 3}]}`,
+			expectedStatusCode: 201,
+		},
+		{
+			name:               "OK batch",
+			codename:           "alpha1",
+			apiKey:             "ingestion-secret",
+			body:               fmt.Sprintf(`[{"temperature": 12.5, "transparency": 80, "timestamp": %d}, {"temperature": 13, "transparency": 75}]`, now.Add(-time.Hour).Unix()),
+			expectedStatusCode: 201,
+		},
+		{
+			name:               "error missing API key",
+			codename:           "alpha1",
+			body:               `{"temperature": 12.5, "transparency": 80}`,
+			expectedStatusCode: 401,
+		},
+		{
+			name:               "error unknown sensor",
+			codename:           "alpha99",
+			apiKey:             "ingestion-secret",
+			body:               `{"temperature": 12.5, "transparency": 80}`,
+			expectedStatusCode: 404,
+		},
+		{
+			name:               "error transparency out of range",
+			codename:           "alpha1",
+			apiKey:             "ingestion-secret",
+			body:               `{"temperature": 12.5, "transparency": 120}`,
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "error negative fish count",
+			codename:           "alpha1",
+			apiKey:             "ingestion-secret",
+			body:               `{"temperature": 12.5, "transparency": 80, "detected_fish": [{"name": "Sailfish", "count": -1}]}`,
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "error timestamp in the future",
+			codename:           "alpha1",
+			apiKey:             "ingestion-secret",
+			body:               fmt.Sprintf(`{"temperature": 12.5, "transparency": 80, "timestamp": %d}`, now.Add(24*time.Hour).Unix()),
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "error timestamp too old",
+			codename:           "alpha1",
+			apiKey:             "ingestion-secret",
+			body:               fmt.Sprintf(`{"temperature": 12.5, "transparency": 80, "timestamp": %d}`, now.AddDate(-1, 0, 0).Unix()),
+			expectedStatusCode: 422,
+		},
+	}
+
+	for _, test := range testCases {
+		r.Run(test.name, func() {
+			app := fiber.New()
+
+			url := fmt.Sprintf("/api/v1/sensor/%s/measurements", test.codename)
+
+			req, _ := http.NewRequest(http.MethodPost, url, strings.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/json")
+			if test.apiKey != "" {
+				req.Header.Set("X-API-Key", test.apiKey)
+			}
+
+			r.handler.Register(app)
+
+			resp, _ := app.Test(req, -1)
+
+			assert.Equal(r.T(), test.expectedStatusCode, resp.StatusCode)
+		})
+	}
+}
+
+func (r *IngestionTestSuite) TestIngestionDisabledWithoutAPIKey() {
+	cfg := config.GetConfig("../../config.yaml")
+	cfg.Ingestion.APIKey = ""
+
+	app := fiber.New()
+
+	handler.NewHandler(context.Background(), *cfg, r.sensorService).Register(app)
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/sensor/alpha1/measurements", strings.NewReader(`{"temperature": 12.5, "transparency": 80}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", "")
+
+	resp, _ := app.Test(req, -1)
+
+	assert.Equal(r.T(), http.StatusNotFound, resp.StatusCode)
+}
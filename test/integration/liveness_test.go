@@ -0,0 +1,232 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/pkg/generations"
+	"github.com/PavelDonchenko/sensor-go/pkg/logging"
+	"github.com/PavelDonchenko/sensor-go/workers"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type LivenessTestSuite struct {
+	TestSuite
+}
+
+func TestLivenessSuite(t *testing.T) {
+	suite.Run(t, new(LivenessTestSuite))
+}
+
+func (r *LivenessTestSuite) TestSensorStatuses() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+
+	}()
+
+	testCases := []struct {
+		name               string
+		url                string
+		expectedStatusCode int
+	}{
+		{
+			name:               "OK statuses",
+			url:                "/api/v1/sensors/status",
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "OK offline sensors of a group",
+			url:                "/api/v1/sensors/status?group=alpha&status=offline",
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "OK temperature of online sensors",
+			url:                "/api/v1/group/alpha/temperature/average?excludeOffline=true",
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "OK transparency of online sensors",
+			url:                "/api/v1/group/alpha/transparency/average?excludeOffline=true",
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "error unknown group",
+			url:                "/api/v1/sensors/status?group=omega",
+			expectedStatusCode: 404,
+		},
+		{
+			name:               "error wrong status",
+			url:                "/api/v1/sensors/status?status=dead",
+			expectedStatusCode: 422,
+		},
+	}
+
+	for _, test := range testCases {
+		r.Run(test.name, func() {
+			app := fiber.New()
+
+			req, _ := http.NewRequest(http.MethodGet, test.url, http.NoBody)
+
+			r.handler.Register(app)
+
+			resp, _ := app.Test(req, -1)
+
+			assert.Equal(r.T(), test.expectedStatusCode, resp.StatusCode)
+		})
+	}
+
+	r.assertTransitions()
+}
+
+// assertTransitions runs the tracker of the suite by its manual clock: alpha1 turns late after liveness.late_after
+// and offline after liveness.offline_after missed intervals, and online again with its next reading.
+func (r *LivenessTestSuite) assertTransitions() {
+	ctx := context.Background()
+	cfg := config.GetConfig("../../config.yaml")
+
+	app := fiber.New()
+	r.handler.Register(app)
+
+	status := func() domain.LivenessStatus {
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/sensors/status?group=alpha", http.NoBody)
+
+		resp, _ := app.Test(req, -1)
+		assert.Equal(r.T(), http.StatusOK, resp.StatusCode)
+
+		var body struct {
+			Statuses []domain.SensorStatus `json:"statuses"`
+		}
+		err := json.NewDecoder(resp.Body).Decode(&body)
+		assert.NoError(r.T(), err)
+
+		for _, s := range body.Statuses {
+			if s.Codename == "alpha1" {
+				return s.Status
+			}
+		}
+
+		return ""
+	}
+
+	events := r.hub.SubscribeEvents(100)
+	defer r.hub.UnsubscribeEvents(events)
+
+	awaitEvent := func(eventType domain.EventType) {
+		timeout := time.After(time.Second)
+
+		for {
+			select {
+			case event := <-events.C():
+				if s, ok := event.Data.(domain.SensorStatus); ok && event.Type == eventType && s.Codename == "alpha1" {
+					return
+				}
+			case <-timeout:
+				r.T().Fatalf("no %s event of alpha1", eventType)
+			}
+		}
+	}
+
+	sensor, err := r.sensorService.GetSensor(ctx, "alpha1")
+	assert.NoError(r.T(), err)
+
+	go r.tracker.Process()
+	r.clock.BlockUntil(1)
+
+	assert.Equal(r.T(), domain.StatusOnline, status())
+
+	// the status is checked every liveness.check_interval, it changes on the first check past the missed intervals
+	check := cfg.Liveness.CheckInterval
+	interval := time.Duration(sensor.DataOutputRate) * time.Second
+	firstCheck := func(missed float64) time.Duration {
+		d := time.Duration(missed * float64(interval))
+		return (d + check - 1) / check * check
+	}
+
+	lateAt := firstCheck(cfg.Liveness.LateAfter)
+	offlineAt := firstCheck(cfg.Liveness.OfflineAfter)
+
+	r.clock.Advance(lateAt - check)
+	assert.Equal(r.T(), domain.StatusOnline, status())
+
+	r.clock.Advance(check)
+	assert.Eventually(r.T(), func() bool { return status() == domain.StatusLate }, time.Second, 10*time.Millisecond)
+
+	r.clock.Advance(offlineAt - lateAt - check)
+	assert.Equal(r.T(), domain.StatusLate, status())
+
+	r.clock.Advance(check)
+	assert.Eventually(r.T(), func() bool { return status() == domain.StatusOffline }, time.Second, 10*time.Millisecond)
+	awaitEvent(domain.EventSensorOffline)
+
+	temperature := 12.5
+	_, err = r.sensorService.IngestMeasurements(ctx, "alpha1", []domain.IngestMeasurement{
+		{Temperature: &temperature, Transparency: new(int)},
+	})
+	assert.NoError(r.T(), err)
+
+	assert.Eventually(r.T(), func() bool { return status() == domain.StatusOnline }, time.Second, 10*time.Millisecond)
+	awaitEvent(domain.EventSensorOnline)
+}
+
+func (r *LivenessTestSuite) TestSensorGoesOfflineAndOnline() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := r.hub.SubscribeEvents(100)
+	defer r.hub.UnsubscribeEvents(events)
+
+	// every sensor misses its interval many times within a few checks
+	cfg := config.GetConfig("../../config.yaml")
+	cfg.Liveness.CheckInterval = 10 * time.Millisecond
+	cfg.Liveness.LateAfter = 0.001
+	cfg.Liveness.OfflineAfter = 0.002
+
+	tracker := workers.NewLivenessTracker(ctx, r.sensorStorage, logging.GetLogger(), *cfg, r.hub, generations.RealClock{})
+	go tracker.Process()
+
+	sensor, err := r.sensorService.GetSensor(ctx, "alpha1")
+	assert.NoError(r.T(), err)
+
+	assert.Eventually(r.T(), func() bool {
+		return tracker.SensorStatus(sensor.ID) == domain.StatusOffline
+	}, time.Second, 10*time.Millisecond)
+
+	temperature := 12.5
+	_, err = r.sensorService.IngestMeasurements(ctx, "alpha1", []domain.IngestMeasurement{
+		{Temperature: &temperature, Transparency: new(int)},
+	})
+	assert.NoError(r.T(), err)
+
+	online := false
+	timeout := time.After(time.Second)
+
+	for !online {
+		select {
+		case event := <-events.C():
+			status, ok := event.Data.(domain.SensorStatus)
+			online = ok && event.Type == domain.EventSensorOnline && status.SensorID == sensor.ID
+		case <-timeout:
+			r.T().Fatal("the sensor did not come back online")
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ManagementTestSuite struct {
+	TestSuite
+}
+
+func TestManagementSuite(t *testing.T) {
+	suite.Run(t, new(ManagementTestSuite))
+}
+
+func (r *ManagementTestSuite) TestCreateGroup() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+
+	}()
+
+	testCases := []struct {
+		name               string
+		body               string
+		expectedStatusCode int
+	}{
+		{
+			name:               "OK",
+			body:               `{"name": "zeta"}`,
+			expectedStatusCode: 201,
+		},
+		{
+			name:               "error group already exists",
+			body:               `{"name": "alpha"}`,
+			expectedStatusCode: 409,
+		},
+		{
+			name:               "error invalid group name",
+			body:               `{"name": "zeta7"}`,
+			expectedStatusCode: 422,
+		},
+	}
+
+	for _, test := range testCases {
+		r.Run(test.name, func() {
+			app := fiber.New()
+
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/groups", strings.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-API-Key", "admin-secret")
+
+			r.handler.Register(app)
+
+			resp, _ := app.Test(req, -1)
+
+			assert.Equal(r.T(), test.expectedStatusCode, resp.StatusCode)
+		})
+	}
+}
+
+func (r *ManagementTestSuite) TestCreateSensor() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+
+	}()
+
+	testCases := []struct {
+		name               string
+		body               string
+		expectedStatusCode int
+	}{
+		{
+			name:               "OK",
+			body:               `{"group_name": "alpha", "data_output_rate": 10, "coordinates": {"x": 1, "y": 2, "z": -3}}`,
+			expectedStatusCode: 201,
+		},
+		{
+			name:               "error unknown group",
+			body:               `{"group_name": "omega", "data_output_rate": 10, "coordinates": {"x": 1, "y": 2, "z": -3}}`,
+			expectedStatusCode: 404,
+		},
+		{
+			name:               "error wrong data output rate",
+			body:               `{"group_name": "alpha", "data_output_rate": 0, "coordinates": {"x": 1, "y": 2, "z": -3}}`,
+			expectedStatusCode: 422,
+		},
+	}
+
+	for _, test := range testCases {
+		r.Run(test.name, func() {
+			app := fiber.New()
+
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/sensors", strings.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-API-Key", "admin-secret")
+
+			r.handler.Register(app)
+
+			resp, _ := app.Test(req, -1)
+
+			assert.Equal(r.T(), test.expectedStatusCode, resp.StatusCode)
+		})
+	}
+}
+
+func (r *ManagementTestSuite) TestRenameGroup() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+
+	}()
+
+	app := fiber.New()
+
+	req, _ := http.NewRequest(http.MethodPatch, "/api/v1/groups/alpha", strings.NewReader(`{"name": "omega"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", "admin-secret")
+
+	r.handler.Register(app)
+
+	resp, _ := app.Test(req, -1)
+	assert.Equal(r.T(), 200, resp.StatusCode)
+
+	var body struct {
+		Group domain.SensorGroup `json:"group"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	assert.NoError(r.T(), err)
+
+	assert.Equal(r.T(), "omega", body.Group.Name)
+	assert.NotEmpty(r.T(), body.Group.Sensors)
+	for _, sensor := range body.Group.Sensors {
+		assert.Equal(r.T(), "omega", sensor.Codename.Name)
+	}
+}
+
+func (r *ManagementTestSuite) TestUpdateAndDeleteSensor() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+
+	}()
+
+	testCases := []struct {
+		name               string
+		method             string
+		codename           string
+		body               string
+		expectedStatusCode int
+	}{
+		{
+			name:               "OK update",
+			method:             http.MethodPatch,
+			codename:           "alpha1",
+			body:               `{"data_output_rate": 20}`,
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "OK move to another group",
+			method:             http.MethodPatch,
+			codename:           "alpha2",
+			body:               `{"group_name": "gamma"}`,
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "OK delete",
+			method:             http.MethodDelete,
+			codename:           "alpha3",
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "error sensor not found",
+			method:             http.MethodDelete,
+			codename:           "alpha3",
+			expectedStatusCode: 404,
+		},
+	}
+
+	for _, test := range testCases {
+		r.Run(test.name, func() {
+			app := fiber.New()
+
+			url := fmt.Sprintf("/api/v1/sensors/%s", test.codename)
+
+			req, _ := http.NewRequest(test.method, url, strings.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-API-Key", "admin-secret")
+
+			r.handler.Register(app)
+
+			resp, _ := app.Test(req, -1)
+
+			assert.Equal(r.T(), test.expectedStatusCode, resp.StatusCode)
+		})
+	}
+}
+
+func (r *ManagementTestSuite) TestManagementWithoutAPIKey() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+
+	}()
+
+	testCases := []struct {
+		name   string
+		method string
+		url    string
+		body   string
+	}{
+		{
+			name:   "error create group",
+			method: http.MethodPost,
+			url:    "/api/v1/groups",
+			body:   `{"name": "zeta"}`,
+		},
+		{
+			name:   "error rename group",
+			method: http.MethodPatch,
+			url:    "/api/v1/groups/alpha",
+			body:   `{"name": "zeta"}`,
+		},
+		{
+			name:   "error delete group",
+			method: http.MethodDelete,
+			url:    "/api/v1/groups/alpha",
+		},
+		{
+			name:   "error create sensor",
+			method: http.MethodPost,
+			url:    "/api/v1/sensors",
+			body:   `{"group_name": "alpha", "data_output_rate": 10, "coordinates": {"x": 1, "y": 2, "z": -3}}`,
+		},
+		{
+			name:   "error update sensor",
+			method: http.MethodPatch,
+			url:    "/api/v1/sensors/alpha1",
+			body:   `{"data_output_rate": 20}`,
+		},
+		{
+			name:   "error delete sensor",
+			method: http.MethodDelete,
+			url:    "/api/v1/sensors/alpha1",
+		},
+	}
+
+	for _, test := range testCases {
+		r.Run(test.name, func() {
+			app := fiber.New()
+
+			req, _ := http.NewRequest(test.method, test.url, strings.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			r.handler.Register(app)
+
+			resp, _ := app.Test(req, -1)
+
+			assert.Equal(r.T(), http.StatusUnauthorized, resp.StatusCode)
+		})
+	}
+
+	// the sensors are read without the key
+	app := fiber.New()
+	r.handler.Register(app)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/sensors/alpha1", http.NoBody)
+	resp, _ := app.Test(req, -1)
+
+	assert.Equal(r.T(), http.StatusOK, resp.StatusCode)
+}
@@ -0,0 +1,59 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/PavelDonchenko/sensor-go/pkg/logging"
+	"github.com/PavelDonchenko/sensor-go/workers"
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type MQTTTestSuite struct {
+	TestSuite
+}
+
+func TestMQTTSuite(t *testing.T) {
+	suite.Run(t, new(MQTTTestSuite))
+}
+
+func (r *MQTTTestSuite) TestBridgeSavesMeasurements() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+
+	}()
+
+	cfg := config.GetConfig("../../config.yaml")
+	cfg.MQTT.Broker = "tcp://localhost:1883"
+	cfg.MQTT.ClientID = "sensor-go-test"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bridge := workers.NewMQTTBridge(ctx, r.sensorService, logging.GetLogger(), *cfg)
+	go bridge.Process()
+
+	publisher := paho.NewClient(paho.NewClientOptions().AddBroker(cfg.MQTT.Broker).SetClientID("sensor-go-test-publisher"))
+	token := publisher.Connect()
+	token.Wait()
+	assert.NoError(r.T(), token.Error())
+	defer publisher.Disconnect(250)
+
+	payload := `{"temperature": 21.5, "transparency": 42, "detected_fish": [{"name": "Sailfish", "count": 2}]}`
+
+	assert.Eventually(r.T(), func() bool {
+		token := publisher.Publish("sensors/alpha/1/data", 1, false, payload)
+		token.Wait()
+
+		sensor, err := r.sensorService.GetSensor(ctx, "alpha1")
+		return err == nil && sensor.Temperature == 21.5 && sensor.Transparency == 42
+	}, 10*time.Second, 500*time.Millisecond)
+}
@@ -0,0 +1,358 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RegionTestSuite struct {
+	TestSuite
+}
+
+func TestRegionSuite(t *testing.T) {
+	suite.Run(t, new(RegionTestSuite))
+}
+
+func (r *RegionTestSuite) TestRegionQueries() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+
+	}()
+
+	everywhere := "xMin=-100000&xMax=100000&yMin=-100000&yMax=100000&zMin=-100000&zMax=100000"
+	sphere := "x=0&y=0&z=0&radius=100000"
+	nowhere := "xMin=500000&xMax=500001&yMin=500000&yMax=500001&zMin=500000&zMax=500001"
+
+	testCases := []struct {
+		name               string
+		url                string
+		expectedStatusCode int
+	}{
+		{
+			name:               "OK min temperature",
+			url:                "/api/v1/region/temperature/min?" + everywhere,
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "OK average temperature for period",
+			url:                "/api/v1/region/temperature/average?from=0&till=4102444800&" + everywhere,
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "OK temperature percentile",
+			url:                "/api/v1/region/temperature/percentile?p=90&" + everywhere,
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "OK average transparency",
+			url:                "/api/v1/region/transparency/average?" + everywhere,
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "OK species",
+			url:                "/api/v1/region/species?" + everywhere,
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "OK top species for period",
+			url:                "/api/v1/region/species/top/1?from=0&till=4102444800&" + everywhere,
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "OK sensors",
+			url:                "/api/v1/region/sensors?" + everywhere,
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "OK sphere average temperature",
+			url:                "/api/v1/region/temperature/average?" + sphere,
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "OK sphere species",
+			url:                "/api/v1/region/species?" + sphere,
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "error negative radius",
+			url:                "/api/v1/region/sensors?x=0&y=0&z=0&radius=-1",
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "error no sensors in the region",
+			url:                "/api/v1/region/temperature/max?" + nowhere,
+			expectedStatusCode: 404,
+		},
+		{
+			name:               "error missing bound",
+			url:                "/api/v1/region/temperature/max?xMin=0&xMax=1",
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "error wrong percentile",
+			url:                "/api/v1/region/temperature/percentile?p=120&" + everywhere,
+			expectedStatusCode: 422,
+		},
+	}
+
+	for _, test := range testCases {
+		r.Run(test.name, func() {
+			app := fiber.New()
+
+			req, _ := http.NewRequest(http.MethodGet, test.url, http.NoBody)
+
+			r.handler.Register(app)
+
+			resp, _ := app.Test(req, -1)
+
+			assert.Equal(r.T(), test.expectedStatusCode, resp.StatusCode)
+		})
+	}
+}
+
+func (r *RegionTestSuite) TestNearestSensors() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+
+	}()
+
+	testCases := []struct {
+		name               string
+		url                string
+		expectedStatusCode int
+	}{
+		{
+			name:               "OK",
+			url:                "/api/v1/sensors/nearest?x=0&y=0&z=0&k=3",
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "error missing coordinate",
+			url:                "/api/v1/sensors/nearest?x=0&y=0&k=3",
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "error wrong k",
+			url:                "/api/v1/sensors/nearest?x=0&y=0&z=0&k=0",
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "error NaN coordinate",
+			url:                "/api/v1/sensors/nearest?x=NaN&y=0&z=0&k=3",
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "error infinite coordinate",
+			url:                "/api/v1/sensors/nearest?x=0&y=-Inf&z=0&k=3",
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "error NaN region bound",
+			url:                "/api/v1/region/sensors?xMin=NaN&xMax=1&yMin=0&yMax=1&zMin=0&zMax=1",
+			expectedStatusCode: 422,
+		},
+	}
+
+	for _, test := range testCases {
+		r.Run(test.name, func() {
+			app := fiber.New()
+
+			req, _ := http.NewRequest(http.MethodGet, test.url, http.NoBody)
+
+			r.handler.Register(app)
+
+			resp, _ := app.Test(req, -1)
+
+			assert.Equal(r.T(), test.expectedStatusCode, resp.StatusCode)
+		})
+	}
+}
+
+func (r *RegionTestSuite) TestNearestSensorsOrder() {
+	app := fiber.New()
+	r.handler.Register(app)
+
+	nearest := func(url string) []domain.SensorDistance {
+		req, _ := http.NewRequest(http.MethodGet, url, http.NoBody)
+
+		resp, _ := app.Test(req, -1)
+		assert.Equal(r.T(), http.StatusOK, resp.StatusCode)
+
+		var body struct {
+			Sensors []domain.SensorDistance `json:"sensors"`
+		}
+		err := json.NewDecoder(resp.Body).Decode(&body)
+		assert.NoError(r.T(), err)
+
+		// no sensors are an empty list, not null
+		assert.NotNil(r.T(), body.Sensors)
+
+		return body.Sensors
+	}
+
+	err := Truncate(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	assert.Empty(r.T(), nearest("/api/v1/sensors/nearest?x=0&y=0&z=0&k=3"))
+
+	err = SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+
+	}()
+
+	sensors, err := r.sensorStorage.GetAllSensors(context.Background())
+	assert.NoError(r.T(), err)
+
+	point := domain.Coordinates{X: 1, Y: -2, Z: 3}
+
+	expected := make([]domain.SensorDistance, 0, len(sensors))
+	for _, sensor := range sensors {
+		expected = append(expected, domain.SensorDistance{
+			Sensor: sensor,
+			Distance: math.Sqrt(math.Pow(sensor.Coordinates.X-point.X, 2) + math.Pow(sensor.Coordinates.Y-point.Y, 2) +
+				math.Pow(sensor.Coordinates.Z-point.Z, 2)),
+		})
+	}
+
+	sort.Slice(expected, func(i, j int) bool { return expected[i].Distance < expected[j].Distance })
+
+	k := 5
+	got := nearest(fmt.Sprintf("/api/v1/sensors/nearest?x=%g&y=%g&z=%g&k=%d", point.X, point.Y, point.Z, k))
+
+	assert.Len(r.T(), got, k)
+
+	for i := range got {
+		assert.Equal(r.T(), expected[i].Sensor.ID, got[i].Sensor.ID)
+		assert.InDelta(r.T(), expected[i].Distance, got[i].Distance, 1e-9)
+
+		if i > 0 {
+			assert.LessOrEqual(r.T(), got[i-1].Distance, got[i].Distance)
+		}
+	}
+}
+
+func (r *RegionTestSuite) TestNamedRegions() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+
+	}()
+
+	period := fmt.Sprintf("from=%d&till=%d", time.Now().Add(-time.Hour).Unix(), time.Now().Add(time.Hour).Unix())
+
+	testCases := []struct {
+		name               string
+		method             string
+		url                string
+		body               string
+		expectedStatusCode int
+	}{
+		{
+			name:               "OK create box",
+			method:             http.MethodPost,
+			url:                "/api/v1/regions",
+			body:               `{"name": "north-trench", "box": {"x_min": -100000, "x_max": 100000, "y_min": -100000, "y_max": 100000, "z_min": -100000, "z_max": 100000}}`,
+			expectedStatusCode: 201,
+		},
+		{
+			name:               "OK create sphere",
+			method:             http.MethodPost,
+			url:                "/api/v1/regions",
+			body:               `{"name": "reef", "sphere": {"center": {"x": 0, "y": 0, "z": 0}, "radius": 100000}}`,
+			expectedStatusCode: 201,
+		},
+		{
+			name:               "error region already exists",
+			method:             http.MethodPost,
+			url:                "/api/v1/regions",
+			body:               `{"name": "reef", "sphere": {"center": {"x": 0, "y": 0, "z": 0}, "radius": 1}}`,
+			expectedStatusCode: 409,
+		},
+		{
+			name:               "error invalid region name",
+			method:             http.MethodPost,
+			url:                "/api/v1/regions",
+			body:               `{"name": "north trench", "sphere": {"center": {"x": 0, "y": 0, "z": 0}, "radius": 1}}`,
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "error region without shape",
+			method:             http.MethodPost,
+			url:                "/api/v1/regions",
+			body:               `{"name": "void"}`,
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "OK average temperature in named region",
+			method:             http.MethodGet,
+			url:                "/api/v1/region/temperature/average?region=north-trench",
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "OK named region series",
+			method:             http.MethodGet,
+			url:                "/api/v1/regions/reef/series?metric=temperature&bucket=1m&" + period,
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "error unknown named region",
+			method:             http.MethodGet,
+			url:                "/api/v1/region/species?region=south-trench",
+			expectedStatusCode: 404,
+		},
+		{
+			name:               "OK delete",
+			method:             http.MethodDelete,
+			url:                "/api/v1/regions/reef",
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "error delete unknown region",
+			method:             http.MethodDelete,
+			url:                "/api/v1/regions/reef",
+			expectedStatusCode: 404,
+		},
+	}
+
+	for _, test := range testCases {
+		r.Run(test.name, func() {
+			app := fiber.New()
+
+			req, _ := http.NewRequest(test.method, test.url, strings.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			r.handler.Register(app)
+
+			resp, _ := app.Test(req, -1)
+
+			assert.Equal(r.T(), test.expectedStatusCode, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package test
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/internal/stream"
+	"github.com/PavelDonchenko/sensor-go/pkg/generations"
+	"github.com/PavelDonchenko/sensor-go/pkg/logging"
+	"github.com/PavelDonchenko/sensor-go/workers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReplayTestSuite struct {
+	TestSuite
+}
+
+func TestReplaySuite(t *testing.T) {
+	suite.Run(t, new(ReplayTestSuite))
+}
+
+func (r *ReplayTestSuite) TestRecordAndReplay() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+	}()
+
+	ctx := context.Background()
+
+	cfg := config.GetConfig("../../config.yaml")
+	cfg.Recorder.Path = filepath.Join(r.T().TempDir(), "readings.ndjson")
+	cfg.Recorder.FlushInterval = 10 * time.Millisecond
+	cfg.Replay.KeepTime = true
+
+	recordCtx, stop := context.WithCancel(ctx)
+	recorded := make(chan struct{})
+
+	// the recorder subscribes when it is created, so it records the readings published before it runs
+	recorder := workers.NewRecorder(recordCtx, logging.GetLogger(), *cfg, r.hub)
+
+	go func() {
+		recorder.Process()
+		close(recorded)
+	}()
+
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	temperatures := []float64{12.5, 13, 13.5}
+
+	for i := range temperatures {
+		timestamp := start.Add(time.Duration(i) * 10 * time.Second).Unix()
+
+		_, err = r.sensorService.IngestMeasurements(ctx, "alpha1", []domain.IngestMeasurement{
+			{Temperature: &temperatures[i], Transparency: new(int), Timestamp: &timestamp},
+		})
+		assert.NoError(r.T(), err)
+	}
+
+	stop()
+	<-recorded
+
+	file, err := os.Open(cfg.Recorder.Path)
+	assert.NoError(r.T(), err)
+	defer file.Close()
+
+	lines := 0
+	for scanner := bufio.NewScanner(file); scanner.Scan(); {
+		lines++
+	}
+	assert.Equal(r.T(), len(temperatures), lines)
+
+	recordedSensor, err := r.sensorStorage.GetSensor(ctx, "alpha", 1)
+	assert.NoError(r.T(), err)
+
+	// the replay creates the sensors of the recording in an empty database
+	err = Truncate(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	sub := r.hub.Subscribe(stream.Filter{}, len(temperatures))
+	defer r.hub.Unsubscribe(sub)
+
+	_, err = file.Seek(0, 0)
+	assert.NoError(r.T(), err)
+
+	// an hour of the recording passes in a second
+	clock := generations.NewAcceleratedClock(time.Now(), 3600)
+
+	replayed, err := workers.NewReplay(ctx, r.sensorStorage, logging.GetLogger(), *cfg, r.hub, nil, clock).Run(file)
+	assert.NoError(r.T(), err)
+	assert.Equal(r.T(), len(temperatures), replayed)
+
+	sensor, err := r.sensorStorage.GetSensor(ctx, "alpha", 1)
+	assert.NoError(r.T(), err)
+	assert.Equal(r.T(), domain.SourceLive, sensor.Source)
+	assert.Equal(r.T(), recordedSensor.Coordinates, sensor.Coordinates)
+
+	for i, temperature := range temperatures {
+		select {
+		case reading := <-sub.C():
+			assert.Equal(r.T(), "alpha1", reading.Codename)
+			assert.Equal(r.T(), temperature, reading.Temperature)
+			assert.True(r.T(), start.Add(time.Duration(i)*10*time.Second).Equal(reading.CreatedAt))
+		case <-time.After(time.Second):
+			r.T().Fatalf("received %d of %d replayed readings", i, len(temperatures))
+		}
+	}
+
+	var saved int
+	err = r.sensorStorage.DB.QueryRow(ctx, `SELECT count(*) FROM measurement WHERE sensorid = $1`, sensor.ID).Scan(&saved)
+	assert.NoError(r.T(), err)
+	assert.Equal(r.T(), len(temperatures), saved)
+}
+
+func (r *ReplayTestSuite) TestReplayMalformedRecording() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+	}()
+
+	cfg := config.GetConfig("../../config.yaml")
+
+	recording := strings.NewReader(`{"codename": "alpha1", "temperature": 12.5, "transparency": 80, "created_at": "2023-07-14T12:00:00Z"}
+{"codename": "alpha1", "temperature":
+`)
+
+	replayed, err := workers.NewReplay(context.Background(), r.sensorStorage, logging.GetLogger(), *cfg, nil, nil, generations.RealClock{}).Run(recording)
+	assert.ErrorContains(r.T(), err, "recording line 2")
+	assert.Equal(r.T(), 1, replayed)
+}
@@ -0,0 +1,190 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RollupTestSuite struct {
+	TestSuite
+}
+
+func TestRollupSuite(t *testing.T) {
+	suite.Run(t, new(RollupTestSuite))
+}
+
+func (r *RollupTestSuite) aggregate() {
+	for _, bucket := range domain.Buckets {
+		err := r.sensorStorage.AggregateRollups(context.Background(), bucket, time.Now())
+		assert.NoError(r.T(), err)
+	}
+}
+
+func (r *RollupTestSuite) TestAggregateRollups() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+	}()
+
+	ctx := context.Background()
+
+	sensor, err := r.sensorStorage.GetSensor(ctx, "alpha", 1)
+	assert.NoError(r.T(), err)
+
+	start := time.Date(2023, time.July, 14, 12, 0, 0, 0, time.UTC)
+
+	// the last measurement is at the end of the first minute, so it belongs to the second one
+	measurements := []struct {
+		at           time.Duration
+		temperature  float64
+		transparency int
+	}{
+		{at: 10 * time.Second, temperature: 10, transparency: 40},
+		{at: 50 * time.Second, temperature: 20, transparency: 60},
+		{at: time.Minute, temperature: 30, transparency: 80},
+	}
+
+	for _, m := range measurements {
+		_, err = r.sensorStorage.SaveMeasurement(ctx, domain.Measurement{
+			SensorID:     sensor.ID,
+			Temperature:  m.temperature,
+			Transparency: m.transparency,
+			DetectedFish: []domain.DetectedFish{{Name: "Atlantic Cod", Count: 1}},
+			CreatedAt:    start.Add(m.at),
+		})
+		assert.NoError(r.T(), err)
+	}
+
+	r.aggregate()
+
+	for _, bucket := range domain.Buckets {
+		watermark, err := r.sensorStorage.GetRollupWatermark(ctx, bucket)
+		assert.NoError(r.T(), err)
+		assert.NotNil(r.T(), watermark, bucket)
+	}
+
+	tests := []struct {
+		name                 string
+		bucket               domain.Bucket
+		till                 time.Time
+		expectedTemperature  float64
+		expectedTransparency float64
+		expectedCod          int
+	}{
+		{
+			name:                 "minute excludes its end",
+			bucket:               domain.BucketMinute,
+			till:                 start.Add(time.Minute),
+			expectedTemperature:  15,
+			expectedTransparency: 50,
+			expectedCod:          2,
+		},
+		{
+			name:                 "hour",
+			bucket:               domain.BucketHour,
+			till:                 start.Add(time.Hour),
+			expectedTemperature:  20,
+			expectedTransparency: 60,
+			expectedCod:          3,
+		},
+		{
+			name:                 "day",
+			bucket:               domain.BucketDay,
+			till:                 start.Add(12 * time.Hour),
+			expectedTemperature:  20,
+			expectedTransparency: 60,
+			expectedCod:          3,
+		},
+	}
+
+	for _, test := range tests {
+		r.Run(test.name, func() {
+			from := test.bucket.Truncate(start).Format(utils.TimeLayout)
+			till := test.till.Format(utils.TimeLayout)
+
+			temperature, err := r.sensorStorage.GetSensorAverageTemperatureRollup(ctx, 1, "alpha", test.bucket, from, till)
+			assert.NoError(r.T(), err)
+			assert.NotNil(r.T(), temperature)
+			assert.InDelta(r.T(), test.expectedTemperature, *temperature, 1e-9)
+
+			transparency, err := r.sensorStorage.GetGroupAverageTransparencyRollup(ctx, "alpha", test.bucket, from, till)
+			assert.NoError(r.T(), err)
+			assert.NotNil(r.T(), transparency)
+			assert.InDelta(r.T(), test.expectedTransparency, *transparency, 1e-9)
+
+			species, err := r.sensorStorage.GetTopSpeciesRollup(ctx, "alpha", test.bucket, from, till, 1)
+			assert.NoError(r.T(), err)
+			assert.Equal(r.T(), []domain.DetectedFish{{Name: "Atlantic Cod", Count: test.expectedCod}}, species)
+
+			// the rollups and the raw data agree on the same bounds
+			raw, err := r.sensorStorage.GetSensorAverageTemperature(ctx, 1, "alpha", from, till)
+			assert.NoError(r.T(), err)
+			assert.NotNil(r.T(), raw)
+			assert.InDelta(r.T(), *raw, *temperature, 1e-9)
+
+			rawTransparency, err := r.sensorStorage.GetGroupAverageTransparency(ctx, "alpha", from, till)
+			assert.NoError(r.T(), err)
+			assert.NotNil(r.T(), rawTransparency)
+			assert.InDelta(r.T(), *rawTransparency, *transparency, 1e-9)
+		})
+	}
+
+	// the service serves the aligned and aggregated periods from the rollups
+	temperature, err := r.sensorService.GetSensorTemperature(ctx, 1, "alpha",
+		start.Format(utils.TimeLayout), start.Add(time.Minute).Format(utils.TimeLayout))
+	assert.NoError(r.T(), err)
+	assert.NotNil(r.T(), temperature)
+	assert.InDelta(r.T(), 15, *temperature, 1e-9)
+}
+
+func (r *RollupTestSuite) TestAggregateLateMeasurement() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+	}()
+
+	ctx := context.Background()
+
+	sensor, err := r.sensorStorage.GetSensor(ctx, "alpha", 1)
+	assert.NoError(r.T(), err)
+
+	r.aggregate()
+
+	watermark, err := r.sensorStorage.GetRollupWatermark(ctx, domain.BucketMinute)
+	assert.NoError(r.T(), err)
+	assert.NotNil(r.T(), watermark)
+
+	// a measurement stamped by the database is committed after its minute was aggregated
+	lastMinute := watermark.Add(-time.Minute)
+
+	_, err = r.sensorStorage.DB.Exec(ctx, `INSERT INTO measurement (sensorid, temperature, transparency, created_at)
+		VALUES ($1, 42, 50, $2)`, sensor.ID, lastMinute.Add(30*time.Second))
+	assert.NoError(r.T(), err)
+
+	r.aggregate()
+
+	from := lastMinute.Format(utils.TimeLayout)
+	till := watermark.Format(utils.TimeLayout)
+
+	temperature, err := r.sensorStorage.GetSensorAverageTemperatureRollup(ctx, 1, "alpha", domain.BucketMinute, from, till)
+	assert.NoError(r.T(), err)
+
+	raw, err := r.sensorStorage.GetSensorAverageTemperature(ctx, 1, "alpha", from, till)
+	assert.NoError(r.T(), err)
+
+	assert.NotNil(r.T(), temperature)
+	assert.NotNil(r.T(), raw)
+	assert.InDelta(r.T(), *raw, *temperature, 1e-9)
+}
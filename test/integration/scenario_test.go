@@ -0,0 +1,148 @@
+package test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/PavelDonchenko/sensor-go/pkg/generations"
+	"github.com/PavelDonchenko/sensor-go/pkg/logging"
+	"github.com/PavelDonchenko/sensor-go/pkg/utils"
+	"github.com/PavelDonchenko/sensor-go/workers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ScenarioTestSuite struct {
+	TestSuite
+}
+
+func TestScenarioSuite(t *testing.T) {
+	suite.Run(t, new(ScenarioTestSuite))
+}
+
+func (r *ScenarioTestSuite) TestLoadScenario() {
+	cfg := config.GetConfig("../../config.yaml")
+
+	scenario, err := generations.LoadScenario("../../scenarios/warm-current.yaml", *cfg)
+	assert.NoError(r.T(), err)
+	assert.Len(r.T(), scenario.Sensors(generations.NewSeeds(1).Rand("sensors"), time.Now()), 24)
+
+	testCases := []struct {
+		name     string
+		scenario string
+		problems []string
+	}{
+		{
+			name:     "error unknown field",
+			scenario: "groups:\n  - name: alpha\n    sensors: 3\n",
+			problems: []string{"field sensors not found"},
+		},
+		{
+			name:     "error wrong offset",
+			scenario: "groups:\n  - name: alpha\n    placements:\n      - at: {x: 0, y: 0, z: -1}\nevents:\n  - at: soon\n    type: offline\n",
+			problems: []string{`offset "soon"`},
+		},
+		{
+			name: "error every problem reported",
+			scenario: `groups:
+  - name: alpha1
+    temperature:
+      surfce: 20
+    placements:
+      - grid: {origin: {x: 0, y: 0, z: -1}, spacing: 1, rows: 0, columns: 2}
+      - at: {x: 0, y: 0, z: -1}
+        mooring: {x: 0, y: 0, top: -1, bottom: -5, count: 2}
+  - name: betta
+events:
+  - at: t+10m
+    type: flood
+    group: gamma
+  - at: 1h
+    type: temperature
+    sensor: betta1
+`,
+			problems: []string{
+				"groups[0]: name \"alpha1\"",
+				"groups[0].temperature: line 4: unknown field surfce",
+				"groups[0].placements[0]: grid: rows and columns",
+				"groups[0].placements[1]: placement must have exactly one",
+				"groups[1]: group betta must have at least one placement",
+				"events[0]: type \"flood\"",
+				"events[0]: group gamma is not declared",
+				"events[1]: temperature event must change it by a non-zero delta",
+				"events[1]: sensor betta1 is not placed",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		r.Run(test.name, func() {
+			path := filepath.Join(r.T().TempDir(), "scenario.yaml")
+			err := os.WriteFile(path, []byte(test.scenario), 0o644)
+			assert.NoError(r.T(), err)
+
+			_, err = generations.LoadScenario(path, *cfg)
+			assert.Error(r.T(), err)
+
+			for _, problem := range test.problems {
+				assert.ErrorContains(r.T(), err, problem)
+			}
+		})
+	}
+}
+
+func (r *ScenarioTestSuite) TestScenarioBackfill() {
+	err := Truncate(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+	}()
+
+	ctx := context.Background()
+
+	cfg := config.GetConfig("../../config.yaml")
+	cfg.Faults.Rules = nil
+
+	scenario, err := generations.LoadScenario("../../scenarios/warm-current.yaml", *cfg)
+	assert.NoError(r.T(), err)
+
+	scenario.Apply(cfg)
+
+	till := time.Date(2023, time.July, 14, 2, 0, 0, 0, time.UTC)
+	from := till.Add(-2 * time.Hour)
+
+	r.sensorStorage.Cfg = *cfg
+	r.sensorStorage.Clock = generations.NewManualClock(from)
+
+	err = utils.GenerateScenarioSensors(ctx, *r.sensorStorage, scenario)
+	assert.NoError(r.T(), err)
+
+	sensor, err := r.sensorStorage.GetSensor(ctx, "gamma", 8)
+	assert.NoError(r.T(), err)
+	assert.Equal(r.T(), -20.0, sensor.Coordinates.Z)
+
+	species, err := generations.LoadSpecies(cfg.Species.Catalogue)
+	assert.NoError(r.T(), err)
+
+	_, err = workers.NewBackfill(ctx, r.sensorStorage, logging.GetLogger(), *cfg, species, nil, scenario).Run(from, till)
+	assert.NoError(r.T(), err)
+
+	// betta goes offline an hour after the start
+	var offline int
+	err = r.sensorStorage.DB.QueryRow(ctx, `SELECT count(*) FROM measurement m JOIN sensor s ON s.id = m.sensorid
+		WHERE s.group_name = 'betta' AND m.created_at > $1`, from.Add(time.Hour)).Scan(&offline)
+	assert.NoError(r.T(), err)
+	assert.Zero(r.T(), offline)
+
+	var online int
+	err = r.sensorStorage.DB.QueryRow(ctx, `SELECT count(*) FROM measurement m JOIN sensor s ON s.id = m.sensorid
+		WHERE s.group_name = 'betta' AND m.created_at <= $1`, from.Add(time.Hour)).Scan(&online)
+	assert.NoError(r.T(), err)
+	assert.NotZero(r.T(), online)
+}
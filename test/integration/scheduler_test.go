@@ -0,0 +1,180 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/pkg/generations"
+	"github.com/PavelDonchenko/sensor-go/pkg/logging"
+	"github.com/PavelDonchenko/sensor-go/workers"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type SchedulerTestSuite struct {
+	TestSuite
+}
+
+func TestSchedulerSuite(t *testing.T) {
+	suite.Run(t, new(SchedulerTestSuite))
+}
+
+func (r *SchedulerTestSuite) TestSchedulerEndpoints() {
+	testCases := []struct {
+		name               string
+		url                string
+		apiKey             string
+		expectedStatusCode int
+	}{
+		{
+			name:               "error scheduler not running",
+			url:                "/api/v1/admin/scheduler",
+			apiKey:             "admin-secret",
+			expectedStatusCode: 404,
+		},
+		{
+			name:               "OK tasks",
+			url:                "/api/v1/admin/tasks",
+			apiKey:             "admin-secret",
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "error scheduler without API key",
+			url:                "/api/v1/admin/scheduler",
+			expectedStatusCode: 401,
+		},
+	}
+
+	for _, test := range testCases {
+		r.Run(test.name, func() {
+			app := fiber.New()
+
+			req, _ := http.NewRequest(http.MethodGet, test.url, nil)
+			if test.apiKey != "" {
+				req.Header.Set("X-API-Key", test.apiKey)
+			}
+
+			r.handler.Register(app)
+
+			resp, _ := app.Test(req, -1)
+
+			assert.Equal(r.T(), test.expectedStatusCode, resp.StatusCode)
+		})
+	}
+}
+
+func (r *SchedulerTestSuite) TestDeletedSensorLosesOnlyItsReadings() {
+	err := Truncate(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+	}()
+
+	r.sensorStorage.Cfg.Simulation.Seed = 7
+	r.sensorStorage.Clock = generations.NewManualClock(simulationStart)
+
+	err = SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sensors, err := r.sensorStorage.GetAllSensors(ctx)
+	assert.NoError(r.T(), err)
+
+	deleted, err := r.sensorStorage.GetSensor(ctx, "alpha", 1)
+	assert.NoError(r.T(), err)
+
+	expected, lost := 0, 0
+	for _, sensor := range sensors {
+		readings := int(time.Minute / (time.Duration(sensor.DataOutputRate) * time.Second))
+
+		if sensor.ID == deleted.ID {
+			lost = readings
+			continue
+		}
+
+		expected += readings
+	}
+
+	cfg := config.GetConfig("../../config.yaml")
+	cfg.Simulation.Seed = 7
+	cfg.Faults.Rules = nil
+	// a single writer writes the readings of every sensor by the same bulk inserts
+	cfg.Scheduler.Workers = 1
+	cfg.Scheduler.BatchSize = 1000
+
+	species, err := generations.LoadSpecies(cfg.Species.Catalogue)
+	assert.NoError(r.T(), err)
+
+	clock := generations.NewManualClock(simulationStart)
+
+	worker := workers.NewWorker(ctx, r.sensorStorage, logging.GetLogger(), *cfg, nil, nil, species, nil, nil, clock, nil)
+	worker.Process()
+
+	// the sensor is deleted behind the back of the scheduler, which still generates its readings
+	err = r.sensorStorage.DeleteSensor(ctx, deleted.ID)
+	assert.NoError(r.T(), err)
+
+	clock.Advance(time.Minute)
+
+	assert.Eventually(r.T(), func() bool {
+		stats := worker.SchedulerStats()
+		return stats.Written+stats.Lost+stats.Failed == uint64(expected+lost)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	stats := worker.SchedulerStats()
+	assert.Equal(r.T(), uint64(expected), stats.Written)
+	assert.Equal(r.T(), uint64(lost), stats.Lost)
+	assert.Zero(r.T(), stats.Failed)
+
+	var saved int
+	err = r.sensorStorage.DB.QueryRow(ctx, `SELECT count(*) FROM measurement WHERE created_at > $1 AND created_at <= $2`,
+		simulationStart, simulationStart.Add(time.Minute)).Scan(&saved)
+	assert.NoError(r.T(), err)
+	assert.Equal(r.T(), expected, saved)
+}
+
+func (r *SchedulerTestSuite) TestCopyKeepsAssignedIDs() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+	}()
+
+	ctx := context.Background()
+
+	sensor, err := r.sensorStorage.GetSensor(ctx, "alpha", 1)
+	assert.NoError(r.T(), err)
+
+	// the writer assigns the ids before the first attempt, so a retried write returns what the first one saved
+	measurement := domain.Measurement{
+		ID:           uuid.New(),
+		SensorID:     sensor.ID,
+		Temperature:  12.5,
+		Transparency: 80,
+		CreatedAt:    simulationStart,
+	}
+	measurement.DetectedFish = []domain.DetectedFish{
+		{ID: uuid.New(), SensorID: sensor.ID, MeasurementID: measurement.ID, Name: "Atlantic Cod", Count: 3},
+	}
+
+	saved, err := r.sensorStorage.CopyMeasurements(ctx, []domain.Measurement{measurement})
+	assert.NoError(r.T(), err)
+	assert.Equal(r.T(), []domain.Measurement{measurement}, saved)
+
+	var fishID uuid.UUID
+	err = r.sensorStorage.DB.QueryRow(ctx, `SELECT id FROM detected_fish WHERE measurementid = $1`, measurement.ID).Scan(&fishID)
+	assert.NoError(r.T(), err)
+	assert.Equal(r.T(), measurement.DetectedFish[0].ID, fishID)
+}
@@ -0,0 +1,175 @@
+package test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/pkg/generations"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// SchoolsTestSuite checks the fish schools simulation, it needs neither the database nor the cache.
+type SchoolsTestSuite struct {
+	suite.Suite
+	cfg *config.Config
+}
+
+func TestSchoolsSuite(t *testing.T) {
+	suite.Run(t, new(SchoolsTestSuite))
+}
+
+func (r *SchoolsTestSuite) SetupTest() {
+	r.cfg = config.GetConfig("../../config.yaml")
+}
+
+// herring schools anywhere in the water, so the schools keep their depth
+var herring = []domain.Species{{
+	Name:      "Atlantic Herring",
+	Depth:     domain.Range{Min: 0, Max: 1000},
+	Schooling: true,
+	MaxCount:  40,
+	Rarity:    domain.RarityCommon,
+}}
+
+func (r *SchoolsTestSuite) TestStepMustBePositive() {
+	for _, step := range []time.Duration{0, -time.Second} {
+		r.cfg.Schools.Step = step
+		assert.Error(r.T(), r.cfg.Validate(), step)
+	}
+
+	r.cfg.Schools.Step = time.Second
+	assert.NoError(r.T(), r.cfg.Validate())
+}
+
+func (r *SchoolsTestSuite) TestSchoolsMove() {
+	start := time.Date(2023, time.July, 14, 12, 0, 0, 0, time.UTC)
+
+	schools := generations.NewSchools(rand.New(rand.NewSource(1)), *r.cfg, herring, start)
+
+	previous := schools.Snapshot(start)
+	assert.Len(r.T(), previous, r.cfg.Schools.Count)
+
+	for at := start.Add(time.Minute); at.Before(start.Add(2 * time.Hour)); at = at.Add(time.Minute) {
+		current := schools.Snapshot(at)
+
+		for i, school := range current {
+			assert.Equal(r.T(), previous[i].ID, school.ID)
+			assert.Equal(r.T(), previous[i].Species, school.Species)
+			assert.Equal(r.T(), previous[i].Size, school.Size)
+
+			// a school swims at its speed, a turn at an edge only shortens the way
+			moved := math.Hypot(school.Position.X-previous[i].Position.X, school.Position.Y-previous[i].Position.Y)
+			assert.LessOrEqual(r.T(), moved, r.cfg.Schools.Speed*time.Minute.Seconds()+1e-9)
+			assert.InDelta(r.T(), r.cfg.Schools.Speed, math.Hypot(school.Velocity.X, school.Velocity.Y), 1e-9)
+
+			assert.LessOrEqual(r.T(), math.Abs(school.Position.X), r.cfg.Schools.FieldSize)
+			assert.LessOrEqual(r.T(), math.Abs(school.Position.Y), r.cfg.Schools.FieldSize)
+			assert.LessOrEqual(r.T(), school.Position.Z, 0.0)
+			assert.GreaterOrEqual(r.T(), school.Position.Z, -r.cfg.Schools.MaxDepth)
+		}
+
+		previous = current
+	}
+}
+
+func (r *SchoolsTestSuite) TestSchoolDetected() {
+	start := time.Date(2023, time.July, 14, 12, 0, 0, 0, time.UTC)
+
+	// a single school swims straight and far from the edges
+	r.cfg.Schools.Count = 1
+	r.cfg.Schools.TurnRate = 0
+	r.cfg.Schools.FieldSize = 1000
+	r.cfg.Schools.DetectionRadius = 0.2
+
+	schools := generations.NewSchools(rand.New(rand.NewSource(1)), *r.cfg, herring, start)
+
+	school := schools.Snapshot(start)[0]
+	assert.Zero(r.T(), school.Velocity.Z)
+
+	// the school passes the sensor on its way at the tenth step and is out of its radius by the twentieth
+	onTheWay := uuid.New()
+	schools.Follow(onTheWay, domain.Coordinates{
+		X: school.Position.X + 10*school.Velocity.X,
+		Y: school.Position.Y + 10*school.Velocity.Y,
+		Z: school.Position.Z,
+	})
+
+	farAway := uuid.New()
+	schools.Follow(farAway, domain.Coordinates{X: -school.Position.X, Y: -school.Position.Y, Z: school.Position.Z})
+
+	// the closest sighting counts, the school passed through the position of the sensor
+	expected := []domain.DetectedFish{{Name: "Atlantic Herring", Count: school.Size}}
+
+	assert.Equal(r.T(), expected, schools.Detect(onTheWay, start.Add(20*time.Second)))
+	assert.Empty(r.T(), schools.Detect(farAway, start.Add(20*time.Second)))
+
+	// the school is reported once, the next reading sees it gone
+	assert.Empty(r.T(), schools.Detect(onTheWay, start.Add(30*time.Second)))
+
+	// a forgotten sensor sees nothing
+	schools.Forget(onTheWay)
+	assert.Empty(r.T(), schools.Detect(onTheWay, start.Add(40*time.Second)))
+}
+
+func (r *SchoolsTestSuite) TestSchoolsDeterministic() {
+	start := time.Date(2023, time.July, 14, 12, 0, 0, 0, time.UTC)
+
+	// the sensors are inside the field of the config, so they meet the schools now and then
+	sensors := []struct {
+		id       uuid.UUID
+		position domain.Coordinates
+	}{
+		{id: uuid.New(), position: domain.Coordinates{X: 0, Y: 0, Z: -2}},
+		{id: uuid.New(), position: domain.Coordinates{X: 4, Y: -3, Z: -5}},
+		{id: uuid.New(), position: domain.Coordinates{X: -6, Y: 7, Z: -8}},
+	}
+
+	// run reads the sensors every ten seconds, the first run in their order, the second in the reverse one
+	run := func(reverse bool) [][]domain.DetectedFish {
+		schools := generations.NewSchools(rand.New(rand.NewSource(7)), *r.cfg, herring, start)
+
+		for _, sensor := range sensors {
+			schools.Follow(sensor.id, sensor.position)
+		}
+
+		detected := make([][]domain.DetectedFish, 0)
+
+		for at := start; at.Before(start.Add(time.Hour)); at = at.Add(10 * time.Second) {
+			readings := make([][]domain.DetectedFish, len(sensors))
+
+			for i := range sensors {
+				j := i
+				if reverse {
+					j = len(sensors) - 1 - i
+				}
+
+				readings[j] = schools.Detect(sensors[j].id, at)
+			}
+
+			detected = append(detected, readings...)
+		}
+
+		return detected
+	}
+
+	first := run(false)
+	assert.Equal(r.T(), first, run(true))
+
+	sightings := 0
+	for _, detected := range first {
+		sightings += len(detected)
+	}
+
+	assert.NotZero(r.T(), sightings)
+
+	// the same seed spawns the same schools in the same places
+	a := generations.NewSchools(rand.New(rand.NewSource(7)), *r.cfg, herring, start).Snapshot(start.Add(time.Hour))
+	b := generations.NewSchools(rand.New(rand.NewSource(7)), *r.cfg, herring, start).Snapshot(start.Add(time.Hour))
+	assert.Equal(r.T(), a, b)
+}
@@ -1,10 +1,14 @@
 package test
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
@@ -31,6 +35,7 @@ func (r *SensorTestSuite) TestGetTransparency() {
 	testCases := []struct {
 		name               string
 		groupName          string
+		query              string
 		expectedStatusCode int
 	}{
 		{
@@ -38,6 +43,19 @@ func (r *SensorTestSuite) TestGetTransparency() {
 			groupName:          "alpha",
 			expectedStatusCode: 200,
 		},
+		{
+			name:               "OK period",
+			groupName:          "alpha",
+			query:              fmt.Sprintf("?from=%d&till=%d", time.Now().Add(-time.Hour).Unix(), time.Now().Add(time.Hour).Unix()),
+			expectedStatusCode: 200,
+		},
+		{
+			// the seeded readings are saved at the wall clock time, after the time of the simulation
+			name:               "error no data for the period till the simulation time",
+			groupName:          "alpha",
+			query:              fmt.Sprintf("?from=%d", simulationStart.Add(-time.Hour).Unix()),
+			expectedStatusCode: 404,
+		},
 		{
 			name:               "error wrong group name",
 			groupName:          "wrong name",
@@ -48,7 +66,7 @@ func (r *SensorTestSuite) TestGetTransparency() {
 		r.Run(test.name, func() {
 			app := fiber.New()
 
-			url := fmt.Sprintf("/api/v1/group/%s/transparency/average", test.groupName)
+			url := fmt.Sprintf("/api/v1/group/%s/transparency/average%s", test.groupName, test.query)
 
 			req, _ := http.NewRequest(http.MethodGet, url, http.NoBody)
 
@@ -193,3 +211,258 @@ func (r *SensorTestSuite) TestGetTopSpecies() {
 		})
 	}
 }
+
+func (r *SensorTestSuite) TestGetTopSpeciesForPeriod() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+
+	}()
+
+	ctx := context.Background()
+
+	sensor, err := r.sensorStorage.GetSensor(ctx, "alpha", 1)
+	assert.NoError(r.T(), err)
+
+	sensors, err := r.sensorStorage.GetAllSensors(ctx)
+	assert.NoError(r.T(), err)
+
+	// every seeded sensor detected 12 cods and 4 sailfish now
+	seeded := 0
+	for _, s := range sensors {
+		if s.Codename.Name == "alpha" {
+			seeded++
+		}
+	}
+
+	start := time.Date(2023, time.July, 14, 12, 0, 0, 0, time.UTC)
+
+	measurements := []struct {
+		at   time.Duration
+		fish domain.DetectedFish
+	}{
+		{at: 30 * time.Minute, fish: domain.DetectedFish{Name: "Blue Marlin", Count: 50}},
+		{at: -time.Minute, fish: domain.DetectedFish{Name: "Swordfish", Count: 70}},
+		{at: time.Hour, fish: domain.DetectedFish{Name: "Swordfish", Count: 90}},
+	}
+
+	for _, m := range measurements {
+		_, err = r.sensorStorage.SaveMeasurement(ctx, domain.Measurement{
+			SensorID:     sensor.ID,
+			Temperature:  sensor.Temperature,
+			Transparency: sensor.Transparency,
+			DetectedFish: []domain.DetectedFish{m.fish},
+			CreatedAt:    start.Add(m.at),
+		})
+		assert.NoError(r.T(), err)
+	}
+
+	testCases := []struct {
+		name            string
+		from            time.Time
+		till            time.Time
+		expectedSpecies []domain.ResponseDetectedFish
+	}{
+		{
+			name:            "OK period excludes the readings before and at its end",
+			from:            start,
+			till:            start.Add(time.Hour),
+			expectedSpecies: []domain.ResponseDetectedFish{{Name: "Blue Marlin", Count: 50}},
+		},
+		{
+			name: "OK period of the seeded readings",
+			from: time.Now().Add(-time.Hour),
+			till: time.Now().Add(time.Hour),
+			expectedSpecies: []domain.ResponseDetectedFish{
+				{Name: "Atlantic Cod", Count: 12 * seeded},
+				{Name: "Sailfish", Count: 4 * seeded},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		r.Run(test.name, func() {
+			app := fiber.New()
+
+			url := fmt.Sprintf("/api/v1/group/alpha/species/top/3?from=%d&till=%d", test.from.Unix(), test.till.Unix())
+
+			req, _ := http.NewRequest(http.MethodGet, url, http.NoBody)
+
+			r.handler.Register(app)
+
+			resp, _ := app.Test(req, -1)
+
+			assert.Equal(r.T(), http.StatusOK, resp.StatusCode)
+
+			var body struct {
+				Species []domain.ResponseDetectedFish `json:"species"`
+			}
+			err := json.NewDecoder(resp.Body).Decode(&body)
+			assert.NoError(r.T(), err)
+
+			assert.Equal(r.T(), test.expectedSpecies, body.Species)
+		})
+	}
+}
+
+func (r *SensorTestSuite) TestGetSensorTransparency() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+
+	}()
+
+	testCases := []struct {
+		name               string
+		codename           string
+		from               string
+		till               string
+		expectedStatusCode int
+	}{
+		{
+			name:               "OK",
+			codename:           "alpha1",
+			from:               "0",
+			till:               "4102444800",
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "error no data for the period",
+			codename:           "alpha1",
+			from:               "1",
+			till:               "2",
+			expectedStatusCode: 404,
+		},
+	}
+
+	for _, test := range testCases {
+		r.Run(test.name, func() {
+			app := fiber.New()
+
+			url := fmt.Sprintf("/api/v1/sensor/%s/transparency/average?from=%s&till=%s", test.codename, test.from, test.till)
+
+			req, _ := http.NewRequest(http.MethodGet, url, http.NoBody)
+
+			r.handler.Register(app)
+
+			resp, _ := app.Test(req, -1)
+
+			assert.Equal(r.T(), test.expectedStatusCode, resp.StatusCode)
+		})
+	}
+}
+
+func (r *SensorTestSuite) TestGetSensorTemperature() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+
+	}()
+
+	testCases := []struct {
+		name               string
+		codename           string
+		from               string
+		till               string
+		expectedStatusCode int
+	}{
+		{
+			name:               "OK",
+			codename:           "alpha1",
+			from:               "0",
+			till:               "4102444800",
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "error no data for the period",
+			codename:           "alpha1",
+			from:               "1",
+			till:               "2",
+			expectedStatusCode: 404,
+		},
+	}
+
+	for _, test := range testCases {
+		r.Run(test.name, func() {
+			app := fiber.New()
+
+			url := fmt.Sprintf("/api/v1/sensor/%s/temperature/average?from=%s&till=%s", test.codename, test.from, test.till)
+
+			req, _ := http.NewRequest(http.MethodGet, url, http.NoBody)
+
+			r.handler.Register(app)
+
+			resp, _ := app.Test(req, -1)
+
+			assert.Equal(r.T(), test.expectedStatusCode, resp.StatusCode)
+		})
+	}
+}
+
+func (r *SensorTestSuite) TestGetSeries() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+
+	}()
+
+	period := fmt.Sprintf("from=%d&till=%d", time.Now().Add(-time.Hour).Unix(), time.Now().Add(time.Hour).Unix())
+
+	testCases := []struct {
+		name               string
+		url                string
+		expectedStatusCode int
+	}{
+		{
+			name:               "OK sensor",
+			url:                "/api/v1/sensor/alpha1/series?metric=temperature&bucket=1m&agg=max&" + period,
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "OK group percentile",
+			url:                "/api/v1/group/alpha/series?metric=transparency&bucket=1h&agg=p95&" + period,
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "error unknown sensor",
+			url:                "/api/v1/sensor/alpha99/series?metric=temperature&" + period,
+			expectedStatusCode: 404,
+		},
+		{
+			name:               "error wrong metric",
+			url:                "/api/v1/group/alpha/series?metric=salinity&" + period,
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "error missing period",
+			url:                "/api/v1/group/alpha/series?metric=temperature",
+			expectedStatusCode: 422,
+		},
+	}
+
+	for _, test := range testCases {
+		r.Run(test.name, func() {
+			app := fiber.New()
+
+			req, _ := http.NewRequest(http.MethodGet, test.url, http.NoBody)
+
+			r.handler.Register(app)
+
+			resp, _ := app.Test(req, -1)
+
+			assert.Equal(r.T(), test.expectedStatusCode, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package test
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/internal/stream"
+	"github.com/PavelDonchenko/sensor-go/pkg/generations"
+	"github.com/PavelDonchenko/sensor-go/pkg/logging"
+	"github.com/PavelDonchenko/sensor-go/workers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type SimulationTestSuite struct {
+	TestSuite
+}
+
+func TestSimulationSuite(t *testing.T) {
+	suite.Run(t, new(SimulationTestSuite))
+}
+
+var simulationStart = time.Date(2023, time.July, 14, 12, 0, 0, 0, time.UTC)
+
+// seed recreates the sensors of the seed as of the simulation start.
+func (r *SimulationTestSuite) seed(seed int64) []domain.Sensor {
+	err := Truncate(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	r.sensorStorage.Cfg.Simulation.Seed = seed
+	r.sensorStorage.Clock = generations.NewManualClock(simulationStart)
+
+	err = SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	sensors, err := r.sensorStorage.GetAllSensors(context.Background())
+	assert.NoError(r.T(), err)
+
+	sort.Slice(sensors, func(i, j int) bool { return sensors[i].Codename.String() < sensors[j].Codename.String() })
+
+	return sensors
+}
+
+// run generates the data of the seeded sensors for the period and returns the readings of every sensor.
+func (r *SimulationTestSuite) run(seed int64, period time.Duration) map[string][]domain.Reading {
+	sensors := r.seed(seed)
+
+	expected := 0
+	for _, sensor := range sensors {
+		expected += int(period / (time.Duration(sensor.DataOutputRate) * time.Second))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := config.GetConfig("../../config.yaml")
+	cfg.Simulation.Seed = seed
+
+	species, err := generations.LoadSpecies(cfg.Species.Catalogue)
+	assert.NoError(r.T(), err)
+
+	sub := r.hub.Subscribe(stream.Filter{}, expected)
+	defer r.hub.Unsubscribe(sub)
+
+	clock := generations.NewManualClock(simulationStart)
+
+	worker := workers.NewWorker(ctx, r.sensorStorage, logging.GetLogger(), *cfg, r.hub, nil, species, nil, nil, clock, nil)
+	worker.Process()
+
+	clock.Advance(period)
+
+	readings := make(map[string][]domain.Reading)
+	timeout := time.After(5 * time.Second)
+
+	for received := 0; received < expected; received++ {
+		select {
+		case reading := <-sub.C():
+			readings[reading.Codename] = append(readings[reading.Codename], reading)
+		case <-timeout:
+			r.T().Fatalf("received %d of %d readings", received, expected)
+		}
+	}
+
+	return readings
+}
+
+func (r *SimulationTestSuite) TestSeededSensors() {
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+	}()
+
+	first := r.seed(42)
+	second := r.seed(42)
+
+	assert.Equal(r.T(), len(first), len(second))
+
+	for i := range first {
+		assert.Equal(r.T(), first[i].Codename, second[i].Codename)
+		assert.Equal(r.T(), first[i].Coordinates, second[i].Coordinates)
+		assert.Equal(r.T(), first[i].DataOutputRate, second[i].DataOutputRate)
+		assert.Equal(r.T(), first[i].Temperature, second[i].Temperature)
+		assert.Equal(r.T(), first[i].Transparency, second[i].Transparency)
+	}
+
+	// the seed fixes the sensors, not only makes two runs alike
+	assert.Equal(r.T(), "alpha1", first[0].Codename.String())
+	assert.Equal(r.T(), domain.Coordinates{X: -8.038523330949664, Y: -3.8360733167738035, Z: -1.8729950909370139},
+		first[0].Coordinates)
+	assert.Equal(r.T(), 15, first[0].DataOutputRate)
+	assert.Equal(r.T(), 21.612, first[0].Temperature)
+	assert.Equal(r.T(), 30, first[0].Transparency)
+
+	assert.Equal(r.T(), "alpha2", first[1].Codename.String())
+	assert.Equal(r.T(), domain.Coordinates{X: -4.822694226054818, Y: 6.677833261082245, Z: -9.89600597653821},
+		first[1].Coordinates)
+	assert.Equal(r.T(), 25, first[1].DataOutputRate)
+	assert.Equal(r.T(), 13.034, first[1].Temperature)
+	assert.Equal(r.T(), 31, first[1].Transparency)
+
+	other := r.seed(43)
+	assert.NotEqual(r.T(), first[0].Coordinates, other[0].Coordinates)
+}
+
+func (r *SimulationTestSuite) TestSeededReadings() {
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+	}()
+
+	first := r.run(7, time.Minute)
+	second := r.run(7, time.Minute)
+
+	assert.NotEmpty(r.T(), first)
+	assert.Equal(r.T(), len(first), len(second))
+
+	for codename, readings := range first {
+		assert.Equal(r.T(), len(readings), len(second[codename]), codename)
+
+		for i, reading := range readings {
+			if i >= len(second[codename]) {
+				break
+			}
+
+			other := second[codename][i]
+
+			assert.Equal(r.T(), reading.Temperature, other.Temperature, codename)
+			assert.Equal(r.T(), reading.Transparency, other.Transparency, codename)
+			assert.Equal(r.T(), fishCounts(reading.DetectedFish), fishCounts(other.DetectedFish), codename)
+		}
+	}
+
+	// the seed fixes the readings, not only makes two runs alike
+	expected := map[string][]struct {
+		at           time.Duration
+		temperature  float64
+		transparency int
+		fish         map[string]int
+	}{
+		"alpha1": {
+			{at: 25 * time.Second, temperature: 18.942, transparency: 10, fish: map[string]int{"Common Dolphinfish": 8}},
+			{at: 50 * time.Second, temperature: 18.972, transparency: 8,
+				fish: map[string]int{"Sailfish": 4, "Red Lionfish": 2, "Common Dolphinfish": 10}},
+		},
+		"gamma3": {
+			{at: 5 * time.Second, temperature: 28.781, transparency: 49, fish: map[string]int{}},
+			{at: 10 * time.Second, temperature: 28.777, transparency: 47, fish: map[string]int{}},
+			{at: 15 * time.Second, temperature: 28.808, transparency: 44, fish: map[string]int{"Sailfish": 1}},
+			{at: 20 * time.Second, temperature: 28.828, transparency: 35, fish: map[string]int{"Queen Angelfish": 1}},
+			{at: 40 * time.Second, temperature: 28.826, transparency: 39,
+				fish: map[string]int{"Clown Anemonefish": 2, "Red Lionfish": 2}},
+		},
+	}
+
+	for codename, readings := range expected {
+		got := make(map[time.Time]domain.Reading, len(first[codename]))
+		for _, reading := range first[codename] {
+			got[reading.CreatedAt.UTC()] = reading
+		}
+
+		for _, want := range readings {
+			reading, ok := got[simulationStart.Add(want.at)]
+			if !assert.True(r.T(), ok, "%s at %s", codename, want.at) {
+				continue
+			}
+
+			assert.Equal(r.T(), want.temperature, reading.Temperature, "%s at %s", codename, want.at)
+			assert.Equal(r.T(), want.transparency, reading.Transparency, "%s at %s", codename, want.at)
+			assert.Equal(r.T(), want.fish, fishCounts(reading.DetectedFish), "%s at %s", codename, want.at)
+		}
+	}
+}
+
+func (r *SimulationTestSuite) TestSchedulerStats() {
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+	}()
+
+	sensors := r.seed(7)
+
+	expected := 0
+	for _, sensor := range sensors {
+		expected += int(time.Minute / (time.Duration(sensor.DataOutputRate) * time.Second))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := config.GetConfig("../../config.yaml")
+	cfg.Simulation.Seed = 7
+	cfg.Faults.Rules = nil
+	// the readings of a tick are written by several bulk inserts
+	cfg.Scheduler.Workers = 2
+	cfg.Scheduler.BatchSize = 3
+
+	species, err := generations.LoadSpecies(cfg.Species.Catalogue)
+	assert.NoError(r.T(), err)
+
+	clock := generations.NewManualClock(simulationStart)
+
+	worker := workers.NewWorker(ctx, r.sensorStorage, logging.GetLogger(), *cfg, nil, nil, species, nil, nil, clock, nil)
+	worker.Process()
+
+	clock.Advance(time.Minute)
+
+	assert.Eventually(r.T(), func() bool {
+		return worker.SchedulerStats().Written == uint64(expected)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	stats := worker.SchedulerStats()
+	assert.Equal(r.T(), len(sensors), stats.Sensors)
+	assert.Equal(r.T(), 2, stats.Workers)
+	assert.Zero(r.T(), stats.Queued)
+	assert.Zero(r.T(), stats.Failed)
+	assert.GreaterOrEqual(r.T(), stats.Batches, uint64(expected/3))
+	assert.GreaterOrEqual(r.T(), stats.LagMax, stats.LagAvg)
+
+	var saved int
+	err = r.sensorStorage.DB.QueryRow(ctx, `SELECT count(*) FROM measurement WHERE created_at > $1 AND created_at <= $2`,
+		simulationStart, simulationStart.Add(time.Minute)).Scan(&saved)
+	assert.NoError(r.T(), err)
+	assert.Equal(r.T(), expected, saved)
+}
+
+func fishCounts(fish []domain.ResponseDetectedFish) map[string]int {
+	counts := make(map[string]int, len(fish))
+	for _, f := range fish {
+		counts[f.Name] = f.Count
+	}
+
+	return counts
+}
@@ -0,0 +1,72 @@
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type SpeciesTestSuite struct {
+	TestSuite
+}
+
+func TestSpeciesSuite(t *testing.T) {
+	suite.Run(t, new(SpeciesTestSuite))
+}
+
+func (r *SpeciesTestSuite) TestGetSpeciesCatalogue() {
+	testCases := []struct {
+		name               string
+		url                string
+		expectedStatusCode int
+		habitat            string
+	}{
+		{
+			name:               "OK catalogue",
+			url:                "/api/v1/species",
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "OK species of a habitat",
+			url:                "/api/v1/species?habitat=reef",
+			expectedStatusCode: 200,
+			habitat:            "reef",
+		},
+	}
+
+	for _, test := range testCases {
+		r.Run(test.name, func() {
+			app := fiber.New()
+
+			req, _ := http.NewRequest(http.MethodGet, test.url, http.NoBody)
+
+			r.handler.Register(app)
+
+			resp, _ := app.Test(req, -1)
+
+			assert.Equal(r.T(), test.expectedStatusCode, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			assert.NoError(r.T(), err)
+
+			var catalogue struct {
+				Species []domain.Species `json:"species"`
+			}
+			assert.NoError(r.T(), json.Unmarshal(body, &catalogue))
+			assert.NotEmpty(r.T(), catalogue.Species)
+
+			for _, species := range catalogue.Species {
+				assert.NotEmpty(r.T(), species.ScientificName)
+				if test.habitat != "" {
+					assert.Equal(r.T(), test.habitat, species.Habitat)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/PavelDonchenko/sensor-go/internal/handler"
+	"github.com/PavelDonchenko/sensor-go/internal/stream"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type StreamTestSuite struct {
+	TestSuite
+}
+
+func TestStreamSuite(t *testing.T) {
+	suite.Run(t, new(StreamTestSuite))
+}
+
+func (r *StreamTestSuite) TestStreamIngestedReadings() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+
+	}()
+
+	alpha := r.sensorService.SubscribeReadings(stream.Filter{GroupName: "alpha"})
+	defer r.sensorService.UnsubscribeReadings(alpha)
+
+	other := r.sensorService.SubscribeReadings(stream.Filter{Codename: "alpha2"})
+	defer r.sensorService.UnsubscribeReadings(other)
+
+	app := fiber.New()
+	r.handler.Register(app)
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/sensor/alpha1/measurements",
+		strings.NewReader(`{"temperature": 12.5, "transparency": 80}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", "ingestion-secret")
+
+	resp, _ := app.Test(req, -1)
+	assert.Equal(r.T(), 201, resp.StatusCode)
+
+	select {
+	case reading := <-alpha.C():
+		assert.Equal(r.T(), "alpha1", reading.Codename)
+		assert.Equal(r.T(), 12.5, reading.Temperature)
+	case <-time.After(time.Second):
+		r.T().Error("the ingested reading was not streamed")
+	}
+
+	select {
+	case reading := <-other.C():
+		r.T().Errorf("reading of %s does not match the filter", reading.Codename)
+	default:
+	}
+}
+
+func (r *StreamTestSuite) TestStreamFilters() {
+	testCases := []struct {
+		name               string
+		url                string
+		expectedStatusCode int
+	}{
+		{
+			name:               "error wrong metric",
+			url:                "/api/v1/stream?metric=temperature,salinity",
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "error wrong region",
+			url:                "/api/v1/stream?xMin=a",
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "error unknown named region",
+			url:                "/api/v1/stream?region=nowhere",
+			expectedStatusCode: 404,
+		},
+	}
+
+	for _, test := range testCases {
+		r.Run(test.name, func() {
+			app := fiber.New()
+
+			req, _ := http.NewRequest(http.MethodGet, test.url, http.NoBody)
+
+			r.handler.Register(app)
+
+			resp, _ := app.Test(req, -1)
+
+			assert.Equal(r.T(), test.expectedStatusCode, resp.StatusCode)
+		})
+	}
+}
+
+func (r *StreamTestSuite) TestShutdownWithOpenStream() {
+	cfg := config.GetConfig("../../config.yaml")
+	cfg.Stream.Heartbeat = 10 * time.Millisecond
+
+	routes := handler.NewHandler(context.Background(), *cfg, r.sensorService)
+
+	app := fiber.New()
+	routes.Register(app)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(r.T(), err)
+
+	go func() {
+		_ = app.Listener(listener)
+	}()
+
+	// the response headers come with the first heartbeat
+	resp, err := http.Get(fmt.Sprintf("http://%s/api/v1/stream", listener.Addr()))
+	assert.NoError(r.T(), err)
+	defer resp.Body.Close()
+
+	shutdown := make(chan error, 1)
+	go func() {
+		routes.CloseStreams()
+		shutdown <- app.Shutdown()
+	}()
+
+	select {
+	case err := <-shutdown:
+		assert.NoError(r.T(), err)
+	case <-time.After(2 * time.Second):
+		r.T().Fatal("the server did not shut down with an open stream")
+	}
+
+	_, err = io.ReadAll(resp.Body)
+	assert.NoError(r.T(), err)
+}
@@ -8,9 +8,12 @@ import (
 	"github.com/PavelDonchenko/sensor-go/internal/handler"
 	"github.com/PavelDonchenko/sensor-go/internal/service"
 	"github.com/PavelDonchenko/sensor-go/internal/storage"
+	"github.com/PavelDonchenko/sensor-go/internal/stream"
 	"github.com/PavelDonchenko/sensor-go/pkg/cache"
+	"github.com/PavelDonchenko/sensor-go/pkg/generations"
 	"github.com/PavelDonchenko/sensor-go/pkg/logging"
 	"github.com/PavelDonchenko/sensor-go/pkg/postgres"
+	"github.com/PavelDonchenko/sensor-go/workers"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -19,6 +22,13 @@ type TestSuite struct {
 	sensorService service.SensorService
 	sensorStorage *storage.Database
 	handler       *handler.Handler
+	hub           *stream.Hub
+	tracker       *workers.LivenessTracker
+	detector      *workers.AnomalyDetector
+	faults        *generations.Faults
+	// clock is the clock of the tracker and the service, it starts at simulationStart
+	clock  *generations.ManualClock
+	cancel context.CancelFunc
 }
 
 func (s *TestSuite) SetupTest() {
@@ -29,8 +39,11 @@ func (s *TestSuite) SetupTest() {
 	cfg.Postgres.Database = "test_sensor"
 	cfg.Postgres.Host = "localhost"
 	cfg.Redis.Address = "localhost:6379"
+	cfg.Ingestion.APIKey = "ingestion-secret"
+	cfg.Admin.APIKey = "admin-secret"
 
-	ctx := context.Background()
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
 
 	pClient, err := postgres.NewClient(ctx, cfg)
 	if err != nil {
@@ -44,7 +57,25 @@ func (s *TestSuite) SetupTest() {
 
 	s.sensorStorage = storage.NewDatabase(pClient, *cfg, logger)
 
-	s.sensorService = service.NewService(ctx, s.sensorStorage, logger, *cfg, redis)
+	s.hub = stream.NewHub()
+
+	s.clock = generations.NewManualClock(simulationStart)
+
+	s.tracker = workers.NewLivenessTracker(ctx, s.sensorStorage, logger, *cfg, s.hub, s.clock)
+
+	s.detector = workers.NewAnomalyDetector(ctx, s.sensorStorage, logger, *cfg, s.hub)
+
+	species, err := generations.LoadSpecies(cfg.Species.Catalogue)
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	s.faults, err = generations.NewFaults(*cfg)
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	s.sensorService = service.NewService(ctx, s.sensorStorage, logger, *cfg, redis, nil, s.hub, s.detector, s.tracker, species, s.faults, nil, s.clock)
 
 	s.handler = handler.NewHandler(ctx, *cfg, s.sensorService)
 
@@ -53,3 +84,8 @@ func (s *TestSuite) SetupTest() {
 		logger.Panic("error migration", err)
 	}
 }
+
+// TearDownTest stops the workers the test started with the context of the suite.
+func (s *TestSuite) TearDownTest() {
+	s.cancel()
+}
@@ -0,0 +1,135 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/pkg/generations"
+	"github.com/PavelDonchenko/sensor-go/pkg/logging"
+	"github.com/PavelDonchenko/sensor-go/workers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type SupervisorTestSuite struct {
+	TestSuite
+}
+
+func TestSupervisorSuite(t *testing.T) {
+	suite.Run(t, new(SupervisorTestSuite))
+}
+
+func (r *SupervisorTestSuite) TestRestarts() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := config.GetConfig("../../config.yaml")
+	cfg.Supervisor.RestartBackoff = time.Millisecond
+	cfg.Supervisor.MaxBackoff = 10 * time.Millisecond
+	cfg.Supervisor.MaxRestarts = 2
+
+	supervisor := workers.NewSupervisor(ctx, logging.GetLogger(), *cfg)
+
+	// the flaky task panics once and succeeds after its restart
+	runs := 0
+	supervisor.Go("flaky", func() error {
+		runs++
+		if runs == 1 {
+			panic("storage is gone")
+		}
+
+		return nil
+	})
+
+	// the broken task is given up after the restarts in a row
+	supervisor.Go("broken", func() error {
+		return errors.New("broker unreachable")
+	})
+
+	// the lasting task runs until the context is done
+	supervisor.Go("lasting", func() error {
+		<-ctx.Done()
+		return nil
+	})
+
+	assert.Eventually(r.T(), func() bool {
+		tasks := supervisor.Tasks()
+		return tasks[0].State == domain.TaskStopped && tasks[1].State == domain.TaskFailed
+	}, time.Second, time.Millisecond)
+
+	tasks := supervisor.Tasks()
+	assert.Equal(r.T(), []string{"flaky", "broken", "lasting"}, []string{tasks[0].Name, tasks[1].Name, tasks[2].Name})
+
+	assert.Equal(r.T(), 1, tasks[0].Restarts)
+	assert.Equal(r.T(), "panic: storage is gone", tasks[0].LastError)
+
+	assert.Equal(r.T(), 2, tasks[1].Restarts)
+	assert.Equal(r.T(), "broker unreachable", tasks[1].LastError)
+
+	assert.Equal(r.T(), domain.TaskRunning, tasks[2].State)
+	assert.False(r.T(), supervisor.Wait(10*time.Millisecond))
+
+	cancel()
+
+	assert.True(r.T(), supervisor.Wait(time.Second))
+	assert.Equal(r.T(), domain.TaskStopped, supervisor.Tasks()[2].State)
+}
+
+func (r *SupervisorTestSuite) TestDrain() {
+	err := Truncate(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+	}()
+
+	r.sensorStorage.Cfg.Simulation.Seed = 7
+	r.sensorStorage.Clock = generations.NewManualClock(simulationStart)
+
+	err = SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := config.GetConfig("../../config.yaml")
+	cfg.Simulation.Seed = 7
+	cfg.Faults.Rules = nil
+	cfg.Scheduler.QueueSize = 10000
+
+	species, err := generations.LoadSpecies(cfg.Species.Catalogue)
+	assert.NoError(r.T(), err)
+
+	clock := generations.NewManualClock(simulationStart)
+	supervisor := workers.NewSupervisor(ctx, logging.GetLogger(), *cfg)
+
+	worker := workers.NewWorker(ctx, r.sensorStorage, logging.GetLogger(), *cfg, nil, nil, species, nil, nil, clock, supervisor)
+	worker.Process()
+
+	clock.Advance(time.Minute)
+
+	// the readings queued by the time the context is done are still written
+	cancel()
+
+	assert.True(r.T(), supervisor.Wait(5*time.Second))
+
+	stats := worker.SchedulerStats()
+	assert.NotZero(r.T(), stats.Written)
+	assert.Zero(r.T(), stats.Queued)
+	assert.Zero(r.T(), stats.Failed)
+
+	var saved int
+	err = r.sensorStorage.DB.QueryRow(context.Background(), `SELECT count(*) FROM measurement WHERE created_at > $1 AND created_at <= $2`,
+		simulationStart, simulationStart.Add(time.Minute)).Scan(&saved)
+	assert.NoError(r.T(), err)
+	assert.Equal(r.T(), int(stats.Written), saved)
+
+	for _, task := range supervisor.Tasks() {
+		assert.Equal(r.T(), domain.TaskStopped, task.State, task.Name)
+	}
+}
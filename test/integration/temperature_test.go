@@ -0,0 +1,277 @@
+package test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/PavelDonchenko/sensor-go/pkg/generations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// TemperatureTestSuite checks the temperature model, it needs neither the database nor the cache.
+type TemperatureTestSuite struct {
+	suite.Suite
+	cfg *config.Config
+}
+
+func TestTemperatureSuite(t *testing.T) {
+	suite.Run(t, new(TemperatureTestSuite))
+}
+
+func (r *TemperatureTestSuite) SetupTest() {
+	r.cfg = config.GetConfig("../../config.yaml")
+}
+
+// peak is the diurnal peak hour on the seasonal peak day of the config models, both cycles are at their maximum.
+var peak = time.Date(2023, time.August, 8, 15, 0, 0, 0, time.UTC)
+
+func (r *TemperatureTestSuite) TestProfileTemperature() {
+	model := r.cfg.TemperatureModel("alpha")
+
+	tests := []struct {
+		name     string
+		depth    float64
+		at       time.Time
+		expected float64
+	}{
+		{
+			name:     "surface at the peaks",
+			depth:    0,
+			at:       peak,
+			expected: 18 + 4 + 0.8,
+		},
+		{
+			name:     "diurnal swing fades by e over its depth",
+			depth:    2,
+			at:       peak,
+			expected: 22.294,
+		},
+		{
+			name:     "uniform down to the thermocline",
+			depth:    4,
+			at:       peak,
+			expected: 22.108,
+		},
+		{
+			name:     "gradient below the thermocline",
+			depth:    6,
+			at:       peak,
+			expected: 19.04,
+		},
+		{
+			name:     "depth below the surface is negative",
+			depth:    -6,
+			at:       peak,
+			expected: 19.04,
+		},
+		{
+			name:     "deep water",
+			depth:    20,
+			at:       peak,
+			expected: 8,
+		},
+		{
+			name:     "diurnal trough",
+			depth:    0,
+			at:       peak.Add(-12 * time.Hour),
+			expected: 18 + 4 - 0.8,
+		},
+		{
+			name:     "time in another zone",
+			depth:    0,
+			at:       peak.In(time.FixedZone("UTC+3", 3*60*60)),
+			expected: 22.8,
+		},
+	}
+
+	for _, test := range tests {
+		r.Run(test.name, func() {
+			assert.Equal(r.T(), test.expected, generations.ProfileTemperature(model, test.depth, test.at))
+		})
+	}
+}
+
+func (r *TemperatureTestSuite) TestGroupModels() {
+	defaultModel := config.TemperatureModel{
+		Surface:             18,
+		ThermoclineDepth:    4,
+		ThermoclineGradient: 1.5,
+		Deep:                8,
+		DiurnalAmplitude:    0.8,
+		DiurnalPeakHour:     15,
+		DiurnalDepth:        2,
+		SeasonalAmplitude:   4,
+		SeasonalPeakDay:     220,
+		Noise:               0.3,
+		ReversionTime:       10 * time.Minute,
+	}
+
+	override := func(change func(model *config.TemperatureModel)) config.TemperatureModel {
+		model := defaultModel
+		change(&model)
+
+		return model
+	}
+
+	tests := []struct {
+		group         string
+		expectedModel config.TemperatureModel
+		// expectedSurface and expectedDeep are the profile at the peaks at the surface and at 30 meters
+		expectedSurface float64
+		expectedDeep    float64
+	}{
+		{
+			group:           "alpha",
+			expectedModel:   defaultModel,
+			expectedSurface: 22.8,
+			expectedDeep:    8,
+		},
+		{
+			group: "betta",
+			expectedModel: override(func(model *config.TemperatureModel) {
+				model.Surface = 12
+				model.Deep = 4
+				model.SeasonalAmplitude = 6
+			}),
+			expectedSurface: 18.8,
+			expectedDeep:    4,
+		},
+		{
+			group: "gamma",
+			expectedModel: override(func(model *config.TemperatureModel) {
+				model.Surface = 27
+				model.ThermoclineDepth = 6
+				model.ThermoclineGradient = 2
+				model.Deep = 14
+				model.SeasonalAmplitude = 1.5
+			}),
+			expectedSurface: 29.3,
+			expectedDeep:    14,
+		},
+		{
+			group: "delta",
+			expectedModel: override(func(model *config.TemperatureModel) {
+				model.Surface = 22
+				model.DiurnalAmplitude = 1.5
+			}),
+			expectedSurface: 27.5,
+			expectedDeep:    8,
+		},
+		{
+			group: "epsilon",
+			expectedModel: override(func(model *config.TemperatureModel) {
+				model.Surface = 4
+				model.ThermoclineDepth = 2
+				model.ThermoclineGradient = 0.5
+				model.Deep = 1
+				model.DiurnalAmplitude = 0.2
+				model.SeasonalAmplitude = 2
+			}),
+			expectedSurface: 6.2,
+			expectedDeep:    1,
+		},
+		{
+			group:           "omega",
+			expectedModel:   defaultModel,
+			expectedSurface: 22.8,
+			expectedDeep:    8,
+		},
+	}
+
+	for _, test := range tests {
+		r.Run(test.group, func() {
+			model := r.cfg.TemperatureModel(test.group)
+
+			assert.Equal(r.T(), test.expectedModel, model)
+			assert.Equal(r.T(), test.expectedSurface, generations.ProfileTemperature(model, 0, peak))
+			assert.Equal(r.T(), test.expectedDeep, generations.ProfileTemperature(model, 30, peak))
+		})
+	}
+}
+
+func (r *TemperatureTestSuite) TestTemperatureNext() {
+	const seed = 42
+
+	model := r.cfg.TemperatureModel("alpha")
+	depth := 2.0
+	profile := generations.ProfileTemperature(model, depth, peak)
+
+	r.Run("deviation reverts by e over the reversion time", func() {
+		temperature := generations.NewTemperature(rand.New(rand.NewSource(seed)), model, depth, profile+0.6, peak)
+
+		// the same source gives the draw of the step
+		z := rand.New(rand.NewSource(seed)).NormFloat64()
+		at := peak.Add(model.ReversionTime)
+
+		expected := generations.ProfileTemperature(model, depth, at) + 0.6*math.Exp(-1) + model.Noise*math.Sqrt(1-math.Exp(-2))*z
+
+		assert.Equal(r.T(), math.Round(expected*1000)/1000, temperature.Next(at))
+	})
+
+	r.Run("latest far off the profile is not carried over", func() {
+		temperature := generations.NewTemperature(rand.New(rand.NewSource(seed)), model, depth, profile+10, peak)
+
+		z := rand.New(rand.NewSource(seed)).NormFloat64()
+
+		// no time elapsed, the deviation drawn at the start is kept
+		assert.Equal(r.T(), math.Round((profile+model.Noise*z)*1000)/1000, temperature.Next(peak))
+	})
+
+	r.Run("time going back keeps the deviation", func() {
+		temperature := generations.NewTemperature(rand.New(rand.NewSource(seed)), model, depth, profile+0.5, peak)
+
+		earlier := peak.Add(-time.Hour)
+
+		assert.Equal(r.T(), math.Round((generations.ProfileTemperature(model, depth, earlier)+0.5)*1000)/1000,
+			temperature.Next(earlier))
+	})
+
+	r.Run("noise free follows the profile", func() {
+		quiet := model
+		quiet.Noise = 0
+
+		temperature := generations.NewTemperature(rand.New(rand.NewSource(seed)), quiet, depth, profile+1, peak)
+
+		for at := peak; at.Before(peak.Add(24 * time.Hour)); at = at.Add(time.Hour) {
+			assert.Equal(r.T(), generations.ProfileTemperature(quiet, depth, at), temperature.Next(at), at)
+		}
+	})
+
+	continuity := []struct {
+		name string
+		step time.Duration
+		// maxDelta is about six standard deviations of the change over a step
+		maxDelta float64
+	}{
+		{name: "continuous over a second", step: time.Second, maxDelta: 0.1},
+		{name: "continuous over ten seconds", step: 10 * time.Second, maxDelta: 0.35},
+		{name: "continuous over a minute", step: time.Minute, maxDelta: 0.8},
+	}
+
+	for _, test := range continuity {
+		r.Run(test.name, func() {
+			temperature := generations.NewTemperature(rand.New(rand.NewSource(seed)), model, depth, profile, peak)
+
+			last := temperature.Next(peak)
+			var sum float64
+
+			steps := 1000
+			for i := 1; i <= steps; i++ {
+				at := peak.Add(time.Duration(i) * test.step)
+				next := temperature.Next(at)
+
+				assert.LessOrEqual(r.T(), math.Abs(next-last), test.maxDelta, at)
+
+				sum += next - generations.ProfileTemperature(model, depth, at)
+				last = next
+			}
+
+			// the deviation reverts to the profile, it does not wander off
+			assert.Less(r.T(), math.Abs(sum/float64(steps)), 3*model.Noise)
+		})
+	}
+}
@@ -0,0 +1,276 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/pkg/logging"
+	"github.com/PavelDonchenko/sensor-go/workers"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type WebhookTestSuite struct {
+	TestSuite
+}
+
+func TestWebhookSuite(t *testing.T) {
+	suite.Run(t, new(WebhookTestSuite))
+}
+
+func (r *WebhookTestSuite) TestWebhooks() {
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+
+	}()
+
+	testCases := []struct {
+		name               string
+		method             string
+		url                string
+		apiKey             string
+		body               string
+		expectedStatusCode int
+	}{
+		{
+			name:               "OK create webhook",
+			method:             http.MethodPost,
+			url:                "/api/v1/webhooks",
+			apiKey:             "admin-secret",
+			body:               `{"url": "http://localhost:9000/hook", "secret": "s3cr3t", "events": ["alert.firing", "alert.resolved"]}`,
+			expectedStatusCode: 201,
+		},
+		{
+			name:               "OK webhooks",
+			method:             http.MethodGet,
+			url:                "/api/v1/webhooks",
+			apiKey:             "admin-secret",
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "error wrong url",
+			method:             http.MethodPost,
+			url:                "/api/v1/webhooks",
+			apiKey:             "admin-secret",
+			body:               `{"url": "ftp://localhost/hook", "secret": "s3cr3t"}`,
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "error missing secret",
+			method:             http.MethodPost,
+			url:                "/api/v1/webhooks",
+			apiKey:             "admin-secret",
+			body:               `{"url": "http://localhost:9000/hook"}`,
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "error unknown event type",
+			method:             http.MethodPost,
+			url:                "/api/v1/webhooks",
+			apiKey:             "admin-secret",
+			body:               `{"url": "http://localhost:9000/hook", "secret": "s3cr3t", "events": ["sensor.exploded"]}`,
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "error deliveries of unknown webhook",
+			method:             http.MethodGet,
+			url:                "/api/v1/webhooks/100000/deliveries",
+			apiKey:             "admin-secret",
+			expectedStatusCode: 404,
+		},
+		{
+			name:               "error unknown webhook",
+			method:             http.MethodDelete,
+			url:                "/api/v1/webhooks/100000",
+			apiKey:             "admin-secret",
+			expectedStatusCode: 404,
+		},
+		{
+			name:               "error missing API key",
+			method:             http.MethodGet,
+			url:                "/api/v1/webhooks",
+			expectedStatusCode: 401,
+		},
+		{
+			name:               "error wrong API key",
+			method:             http.MethodPost,
+			url:                "/api/v1/webhooks",
+			apiKey:             "wrong",
+			body:               `{"url": "http://localhost:9000/hook", "secret": "s3cr3t"}`,
+			expectedStatusCode: 401,
+		},
+	}
+
+	for _, test := range testCases {
+		r.Run(test.name, func() {
+			app := fiber.New()
+
+			req, _ := http.NewRequest(test.method, test.url, strings.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/json")
+			if test.apiKey != "" {
+				req.Header.Set("X-API-Key", test.apiKey)
+			}
+
+			r.handler.Register(app)
+
+			resp, _ := app.Test(req, -1)
+
+			assert.Equal(r.T(), test.expectedStatusCode, resp.StatusCode)
+
+			// the secret is never sent back
+			body, err := io.ReadAll(resp.Body)
+			assert.NoError(r.T(), err)
+			assert.NotContains(r.T(), string(body), "s3cr3t")
+		})
+	}
+}
+
+func (r *WebhookTestSuite) TestWebhookDelivery() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+
+	}()
+
+	received := make(chan domain.Event, 10)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		if req.Header.Get(domain.HeaderWebhookSignature) != domain.SignPayload("s3cr3t", body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var event domain.Event
+		_ = json.Unmarshal(body, &event)
+		received <- event
+	}))
+	defer receiver.Close()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err = r.sensorService.CreateWebhook(ctx, domain.CreateWebhook{
+		URL:    receiver.URL,
+		Secret: "s3cr3t",
+		Events: []domain.EventType{domain.EventReadingCreated},
+	})
+	assert.NoError(r.T(), err)
+
+	dead, err := r.sensorService.CreateWebhook(ctx, domain.CreateWebhook{URL: failing.URL, Secret: "s3cr3t"})
+	assert.NoError(r.T(), err)
+
+	cfg := config.GetConfig("../../config.yaml")
+	cfg.Webhooks.MaxAttempts = 2
+	cfg.Webhooks.RetryDelay = 10 * time.Millisecond
+
+	dispatcher := workers.NewWebhookDispatcher(ctx, r.sensorStorage, logging.GetLogger(), *cfg, r.hub)
+	go dispatcher.Process()
+
+	// the dispatcher subscribes before it loads the webhooks
+	time.Sleep(100 * time.Millisecond)
+
+	temperature := 12.5
+	_, err = r.sensorService.IngestMeasurements(ctx, "alpha1", []domain.IngestMeasurement{
+		{Temperature: &temperature, Transparency: new(int)},
+	})
+	assert.NoError(r.T(), err)
+
+	select {
+	case event := <-received:
+		assert.Equal(r.T(), domain.EventReadingCreated, event.Type)
+	case <-time.After(time.Second):
+		r.T().Error("the reading was not delivered")
+	}
+
+	assert.Eventually(r.T(), func() bool {
+		letters, err := r.sensorService.GetDeadLetters(ctx, dead.ID, 10)
+		return err == nil && len(letters) == 1 && letters[0].Attempts == 2
+	}, time.Second, 10*time.Millisecond)
+
+	deliveries, err := r.sensorService.GetWebhookDeliveries(ctx, dead.ID, 10)
+	assert.NoError(r.T(), err)
+	assert.Len(r.T(), deliveries, 2)
+}
+
+func (r *WebhookTestSuite) TestWebhookRetriesDeadLetteredOnShutdown() {
+	err := SeedData(*r.sensorStorage)
+	assert.NoError(r.T(), err)
+
+	defer func() {
+		err := Truncate(*r.sensorStorage)
+		assert.NoError(r.T(), err)
+
+	}()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	webhook, err := r.sensorService.CreateWebhook(context.Background(), domain.CreateWebhook{
+		URL:    failing.URL,
+		Secret: "s3cr3t",
+		Events: []domain.EventType{domain.EventReadingCreated},
+	})
+	assert.NoError(r.T(), err)
+
+	cfg := config.GetConfig("../../config.yaml")
+	cfg.Webhooks.MaxAttempts = 5
+	cfg.Webhooks.RetryDelay = time.Minute
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dispatcher := workers.NewWebhookDispatcher(ctx, r.sensorStorage, logging.GetLogger(), *cfg, r.hub)
+
+	stopped := make(chan struct{})
+	go func() {
+		dispatcher.Process()
+		close(stopped)
+	}()
+
+	// the dispatcher subscribes before it loads the webhooks
+	time.Sleep(100 * time.Millisecond)
+
+	temperature := 12.5
+	_, err = r.sensorService.IngestMeasurements(ctx, "alpha1", []domain.IngestMeasurement{
+		{Temperature: &temperature, Transparency: new(int)},
+	})
+	assert.NoError(r.T(), err)
+
+	// the first attempt fails, the retry waits for a minute
+	assert.Eventually(r.T(), func() bool {
+		deliveries, err := r.sensorService.GetWebhookDeliveries(context.Background(), webhook.ID, 10)
+		return err == nil && len(deliveries) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-stopped
+
+	letters, err := r.sensorService.GetDeadLetters(context.Background(), webhook.ID, 10)
+	assert.NoError(r.T(), err)
+	if assert.Len(r.T(), letters, 1) {
+		assert.Equal(r.T(), 1, letters[0].Attempts)
+		assert.Equal(r.T(), "the dispatcher stopped", letters[0].Error)
+	}
+}
@@ -0,0 +1,56 @@
+package workers
+
+import (
+	"context"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/internal/storage"
+	"github.com/PavelDonchenko/sensor-go/pkg/generations"
+	"github.com/PavelDonchenko/sensor-go/pkg/logging"
+)
+
+// Aggregator rolls the sensor data up into statistics buckets by the simulation clock.
+type Aggregator struct {
+	DB    storage.SensorPostgres
+	ctx   context.Context
+	log   logging.Logger
+	cfg   config.Config
+	clock generations.Clock
+}
+
+func NewAggregator(ctx context.Context, DB storage.SensorPostgres, log logging.Logger, cfg config.Config, clock generations.Clock) *Aggregator {
+	return &Aggregator{
+		DB:    DB,
+		ctx:   ctx,
+		log:   log,
+		cfg:   cfg,
+		clock: clock,
+	}
+}
+
+func (a *Aggregator) Process() {
+	ticker := a.clock.NewTicker(a.cfg.Aggregation.Interval)
+	defer ticker.Stop()
+
+	a.aggregate()
+
+	for {
+		select {
+		case <-ticker.C():
+			a.aggregate()
+		case <-a.ctx.Done():
+			return
+		}
+	}
+}
+
+func (a *Aggregator) aggregate() {
+	for _, bucket := range domain.Buckets {
+		err := a.DB.AggregateRollups(a.ctx, bucket, a.clock.Now())
+		if err != nil {
+			a.log.Error(err)
+			return
+		}
+	}
+}
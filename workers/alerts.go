@@ -0,0 +1,246 @@
+package workers
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/internal/storage"
+	"github.com/PavelDonchenko/sensor-go/internal/stream"
+	"github.com/PavelDonchenko/sensor-go/pkg/logging"
+	"github.com/google/uuid"
+)
+
+// alertKey identifies the rule state, the sensor is uuid.Nil for the aggregated rules.
+type alertKey struct {
+	ruleID   int
+	sensorID uuid.UUID
+}
+
+// AlertEvaluator evaluates the alert rules on every new reading.
+type AlertEvaluator struct {
+	DB  storage.SensorPostgres
+	ctx context.Context
+	log logging.Logger
+	cfg config.Config
+	hub *stream.Hub
+
+	rules   []domain.AlertRule
+	areas   map[int]*domain.Area
+	latest  map[uuid.UUID]domain.Reading
+	streaks map[alertKey]int
+	firing  map[alertKey]domain.Alert
+}
+
+func NewAlertEvaluator(ctx context.Context, DB storage.SensorPostgres, log logging.Logger, cfg config.Config, hub *stream.Hub) *AlertEvaluator {
+	return &AlertEvaluator{
+		DB:      DB,
+		ctx:     ctx,
+		log:     log,
+		cfg:     cfg,
+		hub:     hub,
+		areas:   make(map[int]*domain.Area),
+		latest:  make(map[uuid.UUID]domain.Reading),
+		streaks: make(map[alertKey]int),
+		firing:  make(map[alertKey]domain.Alert),
+	}
+}
+
+func (e *AlertEvaluator) Process() {
+	sub := e.hub.Subscribe(stream.Filter{}, e.cfg.Alerts.BufferSize)
+	defer e.hub.Unsubscribe(sub)
+
+	// alerts fired before a restart keep firing until their condition stops holding
+	firing, err := e.DB.GetAlerts(e.ctx, domain.AlertQuery{State: domain.AlertFiring})
+	if err != nil {
+		e.log.Error(err)
+	}
+
+	for _, alert := range firing {
+		key := alertKey{ruleID: alert.RuleID}
+		if alert.SensorID != nil {
+			key.sensorID = *alert.SensorID
+		}
+		e.firing[key] = alert
+	}
+
+	e.reloadRules()
+
+	ticker := time.NewTicker(e.cfg.Alerts.ReloadInterval)
+	defer ticker.Stop()
+
+	var dropped uint64
+
+	for {
+		select {
+		case reading, ok := <-sub.C():
+			if !ok {
+				return
+			}
+			e.evaluate(reading)
+		case <-ticker.C:
+			e.reloadRules()
+			e.forgetDeletedSensors()
+
+			if d := sub.Dropped(); d > dropped {
+				e.log.Errorf("alert evaluation lags behind, %d readings are not evaluated", d-dropped)
+				dropped = d
+			}
+		case <-e.ctx.Done():
+			return
+		}
+	}
+}
+
+func (e *AlertEvaluator) reloadRules() {
+	rules, err := e.DB.GetAlertRules(e.ctx)
+	if err != nil {
+		e.log.Error(err)
+		return
+	}
+
+	areas := make(map[int]*domain.Area)
+	ids := make(map[int]bool)
+
+	for _, rule := range rules {
+		ids[rule.ID] = true
+
+		if rule.Region == "" {
+			continue
+		}
+
+		region, err := e.DB.GetRegion(e.ctx, rule.Region)
+		if err != nil {
+			e.log.Error(err)
+			continue
+		}
+
+		area := region.Area()
+		areas[rule.ID] = &area
+	}
+
+	for key := range e.streaks {
+		if !ids[key.ruleID] {
+			delete(e.streaks, key)
+		}
+	}
+
+	for key := range e.firing {
+		if !ids[key.ruleID] {
+			delete(e.firing, key)
+		}
+	}
+
+	e.rules = rules
+	e.areas = areas
+}
+
+// forgetDeletedSensors drops the state of the deleted sensors.
+func (e *AlertEvaluator) forgetDeletedSensors() {
+	sensors, err := e.DB.GetAllSensors(e.ctx)
+	if err != nil {
+		e.log.Error(err)
+		return
+	}
+
+	ids := make(map[uuid.UUID]bool, len(sensors))
+	for _, sensor := range sensors {
+		ids[sensor.ID] = true
+	}
+
+	for id := range e.latest {
+		if !ids[id] {
+			delete(e.latest, id)
+		}
+	}
+
+	for key := range e.streaks {
+		if key.sensorID != uuid.Nil && !ids[key.sensorID] {
+			delete(e.streaks, key)
+		}
+	}
+
+	for key := range e.firing {
+		if key.sensorID != uuid.Nil && !ids[key.sensorID] {
+			delete(e.firing, key)
+		}
+	}
+}
+
+func (e *AlertEvaluator) evaluate(reading domain.Reading) {
+	e.latest[reading.SensorID] = reading
+
+	for _, rule := range e.rules {
+		area := e.areas[rule.ID]
+
+		if !rule.InScope(reading, area) {
+			continue
+		}
+
+		if rule.Aggregation == "" {
+			if math.IsNaN(rule.Value(reading)) {
+				continue
+			}
+
+			sensorID := reading.SensorID
+			e.transition(rule, alertKey{ruleID: rule.ID, sensorID: sensorID}, &sensorID, rule.Value(reading), reading.CreatedAt)
+			continue
+		}
+
+		values := make([]float64, 0)
+		for _, latest := range e.latest {
+			if rule.InScope(latest, area) && !math.IsNaN(rule.Value(latest)) {
+				values = append(values, rule.Value(latest))
+			}
+		}
+
+		if len(values) == 0 {
+			continue
+		}
+
+		e.transition(rule, alertKey{ruleID: rule.ID}, nil, rule.Aggregation.Apply(values, 0), reading.CreatedAt)
+	}
+}
+
+// transition fires the alert after the required readings in a row.
+func (e *AlertEvaluator) transition(rule domain.AlertRule, key alertKey, sensorID *uuid.UUID, value float64, at time.Time) {
+	alert, isFiring := e.firing[key]
+
+	if !rule.Operator.Compare(value, rule.Threshold) {
+		delete(e.streaks, key)
+
+		if isFiring {
+			if err := e.DB.ResolveAlert(e.ctx, alert.ID, value, at); err != nil {
+				return
+			}
+
+			delete(e.firing, key)
+			e.log.Infof("alert %q resolved, value %v", rule.Name, value)
+
+			alert.State = domain.AlertResolved
+			alert.ResolvedValue = &value
+			alert.ResolvedAt = &at
+			e.hub.PublishEvent(domain.NewEvent(domain.EventAlertResolved, alert))
+		}
+
+		return
+	}
+
+	e.streaks[key]++
+
+	if isFiring || e.streaks[key] < rule.Consecutive {
+		return
+	}
+
+	fired, err := e.DB.FireAlert(e.ctx, domain.Alert{RuleID: rule.ID, SensorID: sensorID, Value: value, FiredAt: at})
+	if err != nil {
+		return
+	}
+
+	e.firing[key] = *fired
+	e.log.Infof("alert %q is firing %s, value %v", rule.Name, fired.Codename, value)
+
+	e.hub.PublishEvent(domain.NewEvent(domain.EventAlertFiring, *fired))
+}
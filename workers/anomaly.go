@@ -0,0 +1,286 @@
+package workers
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/internal/storage"
+	"github.com/PavelDonchenko/sensor-go/internal/stream"
+	"github.com/PavelDonchenko/sensor-go/pkg/logging"
+	"github.com/google/uuid"
+)
+
+var anomalyMetrics = []domain.Metric{domain.MetricTemperature, domain.MetricTransparency}
+
+// window keeps the latest values of a sensor metric.
+type window struct {
+	values     []float64
+	next       int
+	sum, sumSq float64
+}
+
+func (w *window) add(value float64, size int) {
+	if len(w.values) < size {
+		w.values = append(w.values, value)
+	} else {
+		old := w.values[w.next]
+		w.sum -= old
+		w.sumSq -= old * old
+		w.values[w.next] = value
+		w.next = (w.next + 1) % size
+	}
+
+	w.sum += value
+	w.sumSq += value * value
+}
+
+func (w *window) stats() (mean, stdDev float64) {
+	n := float64(len(w.values))
+	mean = w.sum / n
+
+	return mean, math.Sqrt(math.Max(w.sumSq/n-mean*mean, 0))
+}
+
+// Scorer scores the measurements before they are saved and flags the anomalous ones after.
+type Scorer interface {
+	Score(measurement *domain.Measurement) []domain.Anomaly
+	Flag(reading domain.Reading, anomalies []domain.Anomaly)
+}
+
+// AnomalyDetector scores the measurements against their sensor and its nearest sensors.
+type AnomalyDetector struct {
+	DB  storage.SensorPostgres
+	ctx context.Context
+	log logging.Logger
+	cfg config.Config
+	hub *stream.Hub
+
+	flags chan domain.Anomaly
+
+	mu         sync.Mutex
+	windows    map[uuid.UUID]map[domain.Metric]*window
+	latest     map[uuid.UUID]domain.Measurement
+	neighbours map[uuid.UUID][]uuid.UUID
+}
+
+func NewAnomalyDetector(ctx context.Context, DB storage.SensorPostgres, log logging.Logger, cfg config.Config, hub *stream.Hub) *AnomalyDetector {
+	return &AnomalyDetector{
+		DB:         DB,
+		ctx:        ctx,
+		log:        log,
+		cfg:        cfg,
+		hub:        hub,
+		flags:      make(chan domain.Anomaly, cfg.Anomaly.BufferSize),
+		windows:    make(map[uuid.UUID]map[domain.Metric]*window),
+		latest:     make(map[uuid.UUID]domain.Measurement),
+		neighbours: make(map[uuid.UUID][]uuid.UUID),
+	}
+}
+
+func (a *AnomalyDetector) Process() {
+	a.LoadNeighbours()
+
+	ticker := time.NewTicker(a.cfg.Anomaly.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case anomaly := <-a.flags:
+			a.flag(anomaly)
+		case <-ticker.C:
+			a.LoadNeighbours()
+		case <-a.ctx.Done():
+			return
+		}
+	}
+}
+
+// LoadNeighbours loads the nearest sensors of every sensor.
+func (a *AnomalyDetector) LoadNeighbours() {
+	sensors, err := a.DB.GetAllSensors(a.ctx)
+	if err != nil {
+		a.log.Error(err)
+		return
+	}
+
+	neighbours := make(map[uuid.UUID][]uuid.UUID, len(sensors))
+	known := make(map[uuid.UUID]bool, len(sensors))
+
+	for _, sensor := range sensors {
+		known[sensor.ID] = true
+
+		others := make([]domain.Sensor, 0, len(sensors)-1)
+		for _, other := range sensors {
+			if other.ID != sensor.ID {
+				others = append(others, other)
+			}
+		}
+
+		sort.Slice(others, func(i, j int) bool {
+			return sensor.Coordinates.Distance(others[i].Coordinates) < sensor.Coordinates.Distance(others[j].Coordinates)
+		})
+
+		if len(others) > a.cfg.Anomaly.Neighbours {
+			others = others[:a.cfg.Anomaly.Neighbours]
+		}
+
+		for _, other := range others {
+			neighbours[sensor.ID] = append(neighbours[sensor.ID], other.ID)
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for id := range a.latest {
+		if !known[id] {
+			delete(a.latest, id)
+			delete(a.windows, id)
+		}
+	}
+
+	a.neighbours = neighbours
+}
+
+// Score sets the anomaly score of the measurement and returns its anomalous metrics.
+func (a *AnomalyDetector) Score(measurement *domain.Measurement) []domain.Anomaly {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	windows, ok := a.windows[measurement.SensorID]
+	if !ok {
+		windows = make(map[domain.Metric]*window)
+		a.windows[measurement.SensorID] = windows
+	}
+
+	var score float64
+	var anomalies []domain.Anomaly
+
+	for _, metric := range anomalyMetrics {
+		value := metricValue(*measurement, metric)
+
+		if math.IsNaN(value) {
+			continue
+		}
+
+		w, ok := windows[metric]
+		if !ok {
+			w = &window{}
+			windows[metric] = w
+		}
+
+		anomaly := domain.Anomaly{
+			SensorID: measurement.SensorID,
+			Metric:   metric,
+			Value:    value,
+		}
+
+		// the measurement is scored before it joins the statistics it is compared to
+		if len(w.values) >= a.cfg.Anomaly.MinSamples {
+			mean, stdDev := w.stats()
+			anomaly.ZScore = a.score(value, mean, stdDev)
+		}
+
+		anomaly.SpatialScore = a.spatialScore(measurement.SensorID, metric, value)
+
+		w.add(value, a.cfg.Anomaly.Window)
+
+		for _, s := range []*float64{anomaly.ZScore, anomaly.SpatialScore} {
+			if s != nil {
+				anomaly.Score = math.Max(anomaly.Score, math.Abs(*s))
+			}
+		}
+
+		score = math.Max(score, anomaly.Score)
+
+		if anomaly.Score >= a.cfg.Anomaly.Threshold {
+			anomalies = append(anomalies, anomaly)
+		}
+	}
+
+	a.latest[measurement.SensorID] = *measurement
+	measurement.AnomalyScore = &score
+
+	return anomalies
+}
+
+// Flag queues the anomalies of the saved reading.
+func (a *AnomalyDetector) Flag(reading domain.Reading, anomalies []domain.Anomaly) {
+	for _, anomaly := range anomalies {
+		anomaly = ofReading(anomaly, reading)
+
+		select {
+		case a.flags <- anomaly:
+		default:
+			a.log.Errorf("anomaly detector dropped the anomalous %s of sensor %s", anomaly.Metric, anomaly.Codename)
+		}
+	}
+}
+
+func (a *AnomalyDetector) spatialScore(id uuid.UUID, metric domain.Metric, value float64) *float64 {
+	values := make([]float64, 0, len(a.neighbours[id]))
+
+	for _, neighbour := range a.neighbours[id] {
+		if measurement, ok := a.latest[neighbour]; ok && !math.IsNaN(metricValue(measurement, metric)) {
+			values = append(values, metricValue(measurement, metric))
+		}
+	}
+
+	if len(values) < 2 {
+		return nil
+	}
+
+	var sum, sumSq float64
+	for _, v := range values {
+		sum += v
+		sumSq += v * v
+	}
+
+	n := float64(len(values))
+	mean := sum / n
+
+	return a.score(value, mean, math.Sqrt(math.Max(sumSq/n-mean*mean, 0)))
+}
+
+func (a *AnomalyDetector) score(value, mean, stdDev float64) *float64 {
+	score := (value - mean) / math.Max(stdDev, a.cfg.Anomaly.MinStdDev)
+
+	return &score
+}
+
+func (a *AnomalyDetector) flag(anomaly domain.Anomaly) {
+	saved, err := a.DB.SaveAnomaly(a.ctx, anomaly)
+	if err != nil {
+		return
+	}
+
+	a.log.Infof("anomalous %s %v of sensor %s, score %.2f", saved.Metric, saved.Value, saved.Codename, saved.Score)
+
+	if a.hub == nil {
+		return
+	}
+
+	a.hub.PublishEvent(domain.NewEvent(domain.EventAnomalyDetected, *saved))
+}
+
+func ofReading(anomaly domain.Anomaly, reading domain.Reading) domain.Anomaly {
+	anomaly.MeasurementID = reading.MeasurementID
+	anomaly.Codename = reading.Codename
+	anomaly.GroupName = reading.GroupName
+	anomaly.CreatedAt = reading.CreatedAt
+
+	return anomaly
+}
+
+func metricValue(measurement domain.Measurement, metric domain.Metric) float64 {
+	if metric == domain.MetricTemperature {
+		return measurement.Temperature
+	}
+
+	return float64(measurement.Transparency)
+}
@@ -0,0 +1,135 @@
+package workers
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/internal/storage"
+	"github.com/PavelDonchenko/sensor-go/pkg/generations"
+	"github.com/PavelDonchenko/sensor-go/pkg/logging"
+	"github.com/google/uuid"
+)
+
+var ErrorWrongBackfillPeriod = errors.New("backfill till must be after from")
+
+// Backfill generates the history of the simulated sensors over a past period.
+type Backfill struct {
+	DB       storage.SensorPostgres
+	ctx      context.Context
+	log      logging.Logger
+	cfg      config.Config
+	species  []domain.Species
+	faults   *generations.Faults
+	scenario *generations.Scenario
+}
+
+// NewBackfill creates the backfill, the faults and scenario may be nil.
+func NewBackfill(ctx context.Context, DB storage.SensorPostgres, log logging.Logger, cfg config.Config, species []domain.Species,
+	faults *generations.Faults, scenario *generations.Scenario) *Backfill {
+	return &Backfill{
+		DB:       DB,
+		ctx:      ctx,
+		log:      log,
+		cfg:      cfg,
+		species:  species,
+		faults:   faults,
+		scenario: scenario,
+	}
+}
+
+// Run generates and saves the measurements of the period and returns their number.
+func (b *Backfill) Run(from, till time.Time) (int, error) {
+	if !till.After(from) {
+		return 0, ErrorWrongBackfillPeriod
+	}
+
+	sensors, err := b.DB.GetAllSensors(b.ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	g := newGenerator(b.cfg, b.species, b.faults, b.scenario, from)
+
+	detector := NewAnomalyDetector(b.ctx, b.DB, b.log, b.cfg, nil)
+	detector.LoadNeighbours()
+
+	sensorsByID := make(map[uuid.UUID]domain.Sensor, len(sensors))
+	for _, sensor := range sensors {
+		sensorsByID[sensor.ID] = sensor
+	}
+
+	var pending schedule
+
+	for _, sensor := range sensors {
+		if sensor.Source == domain.SourceLive || sensor.DataOutputRate <= 0 {
+			continue
+		}
+
+		rate := time.Duration(sensor.DataOutputRate) * time.Second
+
+		heap.Push(&pending, &scheduled{
+			data: g.follow(sensor, from),
+			rate: rate,
+			next: from.Add(rate),
+		})
+	}
+
+	batch := make([]domain.Measurement, 0, b.cfg.Backfill.BatchSize)
+	anomalies := make([][]domain.Anomaly, 0, b.cfg.Backfill.BatchSize)
+	total := 0
+
+	write := func() error {
+		saved, err := b.DB.CopyMeasurements(b.ctx, batch)
+		if err != nil {
+			return err
+		}
+
+		for i := range saved {
+			reading := domain.NewReading(sensorsByID[saved[i].SensorID], saved[i])
+
+			for _, anomaly := range anomalies[i] {
+				detector.flag(ofReading(anomaly, reading))
+			}
+		}
+
+		total += len(batch)
+		batch = batch[:0]
+		anomalies = anomalies[:0]
+
+		return nil
+	}
+
+	for len(pending) > 0 && !pending[0].next.After(till) {
+		due := pending[0]
+		at := due.next
+
+		due.next = due.next.Add(due.rate)
+		heap.Fix(&pending, 0)
+
+		measurement, ok := due.data.measure(at)
+		if !ok {
+			continue
+		}
+
+		anomalies = append(anomalies, detector.Score(&measurement))
+		batch = append(batch, measurement)
+
+		if len(batch) >= b.cfg.Backfill.BatchSize {
+			if err = write(); err != nil {
+				return total, err
+			}
+
+			b.log.Infof("backfilled %d measurements till %s", total, at.Format(time.RFC3339))
+		}
+	}
+
+	if err = write(); err != nil {
+		return total, err
+	}
+
+	return total, nil
+}
@@ -0,0 +1,132 @@
+package workers
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/pkg/generations"
+	"github.com/google/uuid"
+)
+
+// generator generates the measurements for both the worker and the backfill.
+type generator struct {
+	cfg      config.Config
+	seeds    generations.Seeds
+	species  []domain.Species
+	schools  *generations.Schools
+	solitary []domain.Species
+	faults   *generations.Faults
+	scenario *generations.Scenario
+	start    time.Time
+}
+
+func newGenerator(cfg config.Config, species []domain.Species, faults *generations.Faults, scenario *generations.Scenario,
+	start time.Time) *generator {
+	g := &generator{
+		cfg:      cfg,
+		seeds:    generations.NewSeeds(cfg.Simulation.Seed),
+		species:  species,
+		faults:   faults,
+		scenario: scenario,
+		start:    start,
+	}
+
+	if cfg.Schools.Enabled {
+		g.schools = generations.NewSchools(g.seeds.Rand("schools"), cfg, species, start)
+
+		for _, s := range species {
+			if !s.Schooling {
+				g.solitary = append(g.solitary, s)
+			}
+		}
+	}
+
+	return g
+}
+
+func (g *generator) follow(sensor domain.Sensor, start time.Time) *sensorData {
+	if g.schools != nil {
+		g.schools.Follow(sensor.ID, sensor.Coordinates)
+	}
+
+	// a source per sensor, the readings do not depend on the order the sensors tick in
+	r := g.seeds.Rand(sensor.Codename.String())
+
+	return &sensorData{
+		generator: g,
+		sensor:    sensor,
+		rand:      r,
+		faultRand: g.seeds.Rand("faults/" + sensor.Codename.String()),
+		temperature: generations.NewTemperature(r, g.cfg.TemperatureModel(sensor.Codename.Name), sensor.Coordinates.Z,
+			sensor.Temperature, start),
+	}
+}
+
+func (g *generator) forget(id uuid.UUID) {
+	if g.schools != nil {
+		g.schools.Forget(id)
+	}
+
+	if g.faults != nil {
+		g.faults.Forget(id)
+	}
+}
+
+type sensorData struct {
+	*generator
+	sensor      domain.Sensor
+	rand        *rand.Rand
+	faultRand   *rand.Rand
+	temperature *generations.Temperature
+}
+
+// measure returns false if the measurement is lost.
+func (s *sensorData) measure(at time.Time) (domain.Measurement, bool) {
+	elapsed := at.Sub(s.start)
+
+	temperature := s.scenario.Temperature(s.sensor, elapsed, s.temperature.Next(at))
+
+	fishes := s.generateFishData(temperature, at)
+
+	s.sensor.Transparency = generations.GenerateTransparency(s.rand, s.sensor.Transparency)
+
+	measurement := domain.Measurement{
+		SensorID:     s.sensor.ID,
+		Temperature:  temperature,
+		Transparency: s.scenario.Transparency(s.sensor, elapsed, s.sensor.Transparency),
+		DetectedFish: fishes,
+		CreatedAt:    at.UTC(),
+	}
+
+	// an offline sensor still draws its readings, so the later ones do not depend on the outage
+	if s.scenario.Offline(s.sensor, elapsed) {
+		return measurement, false
+	}
+
+	if s.faults == nil {
+		return measurement, true
+	}
+
+	return measurement, s.faults.Apply(s.faultRand, s.sensor, &measurement)
+}
+
+func (s *sensorData) generateFishData(temperature float64, now time.Time) []domain.DetectedFish {
+	if s.schools == nil {
+		return s.detected(generations.DetectFish(s.rand, s.species, s.sensor.Coordinates.Z, temperature))
+	}
+
+	detectedFish := generations.DetectFish(s.rand, s.solitary, s.sensor.Coordinates.Z, temperature)
+	detectedFish = append(detectedFish, s.schools.Detect(s.sensor.ID, now)...)
+
+	return s.detected(detectedFish)
+}
+
+func (s *sensorData) detected(detectedFish []domain.DetectedFish) []domain.DetectedFish {
+	for i := range detectedFish {
+		detectedFish[i].SensorID = s.sensor.ID
+	}
+
+	return detectedFish
+}
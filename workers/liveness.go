@@ -0,0 +1,199 @@
+package workers
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/internal/storage"
+	"github.com/PavelDonchenko/sensor-go/internal/stream"
+	"github.com/PavelDonchenko/sensor-go/pkg/generations"
+	"github.com/PavelDonchenko/sensor-go/pkg/logging"
+	"github.com/google/uuid"
+)
+
+// liveness is the tracked state of a sensor, heartbeat is the time of its latest reading.
+type liveness struct {
+	status    domain.SensorStatus
+	codename  domain.Codename
+	heartbeat time.Time
+}
+
+// LivenessTracker marks the sensors which miss their data output intervals as late and then offline.
+type LivenessTracker struct {
+	DB    storage.SensorPostgres
+	ctx   context.Context
+	log   logging.Logger
+	cfg   config.Config
+	hub   *stream.Hub
+	clock generations.Clock
+
+	mu      sync.RWMutex
+	sensors map[uuid.UUID]*liveness
+}
+
+func NewLivenessTracker(ctx context.Context, DB storage.SensorPostgres, log logging.Logger, cfg config.Config, hub *stream.Hub,
+	clock generations.Clock) *LivenessTracker {
+	return &LivenessTracker{
+		DB:      DB,
+		ctx:     ctx,
+		log:     log,
+		cfg:     cfg,
+		hub:     hub,
+		clock:   clock,
+		sensors: make(map[uuid.UUID]*liveness),
+	}
+}
+
+func (t *LivenessTracker) Process() {
+	sub := t.hub.Subscribe(stream.Filter{}, t.cfg.Liveness.BufferSize)
+	defer t.hub.Unsubscribe(sub)
+
+	t.reloadSensors(t.clock.Now())
+
+	ticker := t.clock.NewTicker(t.cfg.Liveness.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case reading, ok := <-sub.C():
+			if !ok {
+				return
+			}
+			t.seen(reading.SensorID, t.clock.Now())
+		case now := <-ticker.C():
+			t.reloadSensors(now)
+			t.check(now)
+		case <-t.ctx.Done():
+			return
+		}
+	}
+}
+
+// SensorStatuses returns the liveness of every sensor ordered by the codename.
+func (t *LivenessTracker) SensorStatuses() []domain.SensorStatus {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	tracked := make([]*liveness, 0, len(t.sensors))
+	for _, sensor := range t.sensors {
+		tracked = append(tracked, sensor)
+	}
+
+	sort.Slice(tracked, func(i, j int) bool {
+		if tracked[i].codename.Name != tracked[j].codename.Name {
+			return tracked[i].codename.Name < tracked[j].codename.Name
+		}
+		return tracked[i].codename.SensorGroupID < tracked[j].codename.SensorGroupID
+	})
+
+	statuses := make([]domain.SensorStatus, 0, len(tracked))
+	for _, sensor := range tracked {
+		statuses = append(statuses, sensor.status)
+	}
+
+	return statuses
+}
+
+// SensorStatus returns the liveness of a sensor.
+func (t *LivenessTracker) SensorStatus(id uuid.UUID) domain.LivenessStatus {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if sensor, ok := t.sensors[id]; ok {
+		return sensor.status.Status
+	}
+
+	return domain.StatusOnline
+}
+
+func (t *LivenessTracker) reloadSensors(now time.Time) {
+	sensors, err := t.DB.GetAllSensors(t.ctx)
+	if err != nil {
+		t.log.Error(err)
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	current := make(map[uuid.UUID]*liveness, len(sensors))
+
+	for _, sensor := range sensors {
+		tracked, ok := t.sensors[sensor.ID]
+		if !ok {
+			// a sensor gets the whole grace period after the start or after it was registered
+			tracked = &liveness{
+				status:    domain.SensorStatus{SensorID: sensor.ID, Status: domain.StatusOnline, Since: now},
+				heartbeat: now,
+			}
+		}
+
+		tracked.codename = sensor.Codename
+		tracked.status.Codename = sensor.Codename.String()
+		tracked.status.GroupName = sensor.Codename.Name
+		tracked.status.Source = sensor.Source
+		tracked.status.DataOutputRate = sensor.DataOutputRate
+
+		current[sensor.ID] = tracked
+	}
+
+	t.sensors = current
+}
+
+func (t *LivenessTracker) seen(id uuid.UUID, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	sensor, ok := t.sensors[id]
+	if !ok {
+		return
+	}
+
+	sensor.heartbeat = now
+	sensor.status.LastSeen = &now
+
+	t.transition(sensor, domain.StatusOnline, now)
+}
+
+func (t *LivenessTracker) check(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, sensor := range t.sensors {
+		interval := time.Duration(sensor.status.DataOutputRate) * time.Second
+		missed := float64(now.Sub(sensor.heartbeat)) / float64(interval)
+
+		status := domain.StatusOnline
+		switch {
+		case missed >= t.cfg.Liveness.OfflineAfter:
+			status = domain.StatusOffline
+		case missed >= t.cfg.Liveness.LateAfter:
+			status = domain.StatusLate
+		}
+
+		t.transition(sensor, status, now)
+	}
+}
+
+func (t *LivenessTracker) transition(sensor *liveness, status domain.LivenessStatus, now time.Time) {
+	previous := sensor.status.Status
+	if previous == status {
+		return
+	}
+
+	sensor.status.Status = status
+	sensor.status.Since = now
+
+	switch {
+	case status == domain.StatusOffline:
+		t.log.Infof("sensor %s is offline", sensor.status.Codename)
+		t.hub.PublishEvent(domain.NewEvent(domain.EventSensorOffline, sensor.status))
+	case previous == domain.StatusOffline:
+		t.log.Infof("sensor %s is online again", sensor.status.Codename)
+		t.hub.PublishEvent(domain.NewEvent(domain.EventSensorOnline, sensor.status))
+	}
+}
@@ -0,0 +1,123 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/pkg/logging"
+	"github.com/PavelDonchenko/sensor-go/pkg/mqtt"
+	paho "github.com/eclipse/paho.mqtt.golang"
+)
+
+const (
+	groupPlaceholder = "{group}"
+	indexPlaceholder = "{index}"
+)
+
+var ErrTopicMismatch = errors.New("topic does not match the sensor topic pattern")
+
+// Ingester saves the measurements reported by a sensor.
+type Ingester interface {
+	IngestMeasurements(ctx context.Context, codename string, measurements []domain.IngestMeasurement) ([]domain.Measurement, error)
+}
+
+// MQTTBridge saves the measurements received on the sensor topics of the MQTT broker.
+type MQTTBridge struct {
+	ingester Ingester
+	ctx      context.Context
+	log      logging.Logger
+	cfg      config.Config
+	pattern  []string
+}
+
+func NewMQTTBridge(ctx context.Context, ingester Ingester, log logging.Logger, cfg config.Config) *MQTTBridge {
+	return &MQTTBridge{
+		ingester: ingester,
+		ctx:      ctx,
+		log:      log,
+		cfg:      cfg,
+		pattern:  strings.Split(cfg.MQTT.Topic, "/"),
+	}
+}
+
+// Process consumes the sensor topics until the context is done.
+func (b *MQTTBridge) Process() error {
+	client, err := mqtt.NewClient(&b.cfg, b.subscribe)
+	if err != nil {
+		b.log.Error(err)
+		return err
+	}
+
+	<-b.ctx.Done()
+
+	client.Disconnect(250)
+
+	return nil
+}
+
+// subscribe is called after every reconnection, the broker may have dropped the subscription.
+func (b *MQTTBridge) subscribe(client paho.Client) {
+	filter := strings.NewReplacer(groupPlaceholder, "+", indexPlaceholder, "+").Replace(b.cfg.MQTT.Topic)
+
+	token := client.Subscribe(filter, byte(b.cfg.MQTT.QoS), b.handleMessage)
+	go func() {
+		<-token.Done()
+		if err := token.Error(); err != nil {
+			b.log.Errorf("failed to subscribe to %s: %v", filter, err)
+			return
+		}
+		b.log.Infof("subscribed to %s", filter)
+	}()
+}
+
+func (b *MQTTBridge) handleMessage(_ paho.Client, msg paho.Message) {
+	codename, err := b.parseTopic(msg.Topic())
+	if err != nil {
+		b.log.Error(err)
+		return
+	}
+
+	measurements, err := domain.DecodeIngestMeasurements(msg.Payload())
+	if err != nil {
+		b.log.Errorf("invalid payload from %s: %v", codename, err)
+		return
+	}
+
+	_, err = b.ingester.IngestMeasurements(b.ctx, codename, measurements)
+	if err != nil {
+		b.log.Errorf("failed to ingest measurements from %s: %v", codename, err)
+	}
+}
+
+func (b *MQTTBridge) parseTopic(topic string) (string, error) {
+	segments := strings.Split(topic, "/")
+	if len(segments) != len(b.pattern) {
+		return "", fmt.Errorf("%w: %s", ErrTopicMismatch, topic)
+	}
+
+	var group, index string
+
+	for i, segment := range b.pattern {
+		switch segment {
+		case groupPlaceholder:
+			group = strings.ToLower(segments[i])
+		case indexPlaceholder:
+			index = segments[i]
+		default:
+			if segment != segments[i] {
+				return "", fmt.Errorf("%w: %s", ErrTopicMismatch, topic)
+			}
+		}
+	}
+
+	if _, err := strconv.Atoi(index); err != nil || group == "" {
+		return "", fmt.Errorf("%w: %s", ErrTopicMismatch, topic)
+	}
+
+	return group + index, nil
+}
@@ -0,0 +1,100 @@
+package workers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/internal/stream"
+	"github.com/PavelDonchenko/sensor-go/pkg/logging"
+)
+
+// Recorder appends every reading published to the hub to an NDJSON file.
+type Recorder struct {
+	ctx context.Context
+	log logging.Logger
+	cfg config.Config
+	hub *stream.Hub
+	sub *stream.Subscription
+}
+
+// NewRecorder subscribes to the hub, so the readings published before Process runs are recorded too.
+func NewRecorder(ctx context.Context, log logging.Logger, cfg config.Config, hub *stream.Hub) *Recorder {
+	return &Recorder{
+		ctx: ctx,
+		log: log,
+		cfg: cfg,
+		hub: hub,
+		sub: hub.Subscribe(stream.Filter{}, cfg.Recorder.BufferSize),
+	}
+}
+
+// Process records the readings until the context is done.
+func (r *Recorder) Process() error {
+	file, err := os.OpenFile(r.cfg.Recorder.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		r.log.Errorf("open recording: %v", err)
+		return err
+	}
+	defer file.Close()
+
+	sub := r.sub
+	defer r.hub.Unsubscribe(sub)
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+
+	flush := func() {
+		if err := writer.Flush(); err != nil {
+			r.log.Errorf("write recording: %v", err)
+		}
+	}
+	defer flush()
+
+	record := func(reading domain.Reading) {
+		if err := encoder.Encode(reading); err != nil {
+			r.log.Errorf("record reading of %s: %v", reading.Codename, err)
+		}
+	}
+
+	ticker := time.NewTicker(r.cfg.Recorder.FlushInterval)
+	defer ticker.Stop()
+
+	var dropped uint64
+
+	for {
+		select {
+		case reading, ok := <-sub.C():
+			if !ok {
+				return nil
+			}
+
+			record(reading)
+		case <-ticker.C:
+			flush()
+
+			if d := sub.Dropped(); d > dropped {
+				r.log.Errorf("recording lags behind, %d readings are not recorded", d-dropped)
+				dropped = d
+			}
+		case <-r.ctx.Done():
+			// the readings published before the shutdown are recorded still
+			for {
+				select {
+				case reading, ok := <-sub.C():
+					if !ok {
+						return nil
+					}
+
+					record(reading)
+				default:
+					return nil
+				}
+			}
+		}
+	}
+}
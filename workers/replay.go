@@ -0,0 +1,192 @@
+package workers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/internal/storage"
+	"github.com/PavelDonchenko/sensor-go/internal/stream"
+	"github.com/PavelDonchenko/sensor-go/pkg/generations"
+	"github.com/PavelDonchenko/sensor-go/pkg/logging"
+	"github.com/PavelDonchenko/sensor-go/pkg/utils"
+)
+
+// maxRecordingLine is the longest reading a recording may hold.
+const maxRecordingLine = 1 << 20
+
+// Replay saves and publishes the readings of a recording at its pace by the clock.
+type Replay struct {
+	DB        storage.SensorPostgres
+	ctx       context.Context
+	log       logging.Logger
+	cfg       config.Config
+	publisher stream.Publisher
+	scorer    Scorer
+	clock     generations.Clock
+
+	sensors map[string]domain.Sensor
+}
+
+// NewReplay creates the replay, the publisher and scorer may be nil.
+func NewReplay(ctx context.Context, DB storage.SensorPostgres, log logging.Logger, cfg config.Config, publisher stream.Publisher,
+	scorer Scorer, clock generations.Clock) *Replay {
+	return &Replay{
+		DB:        DB,
+		ctx:       ctx,
+		log:       log,
+		cfg:       cfg,
+		publisher: publisher,
+		scorer:    scorer,
+		clock:     clock,
+		sensors:   make(map[string]domain.Sensor),
+	}
+}
+
+// Run replays the recording and returns the number of the replayed readings.
+// The missing sensors are created as live sensors, so the simulation leaves them alone.
+func (r *Replay) Run(recording io.Reader) (int, error) {
+	scanner := bufio.NewScanner(recording)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRecordingLine)
+
+	start := r.clock.Now()
+	var first time.Time
+
+	replayed := 0
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var reading domain.Reading
+		if err := json.Unmarshal(scanner.Bytes(), &reading); err != nil {
+			return replayed, fmt.Errorf("recording line %d: %w", line, err)
+		}
+
+		if reading.Codename == "" || reading.CreatedAt.IsZero() {
+			return replayed, fmt.Errorf("recording line %d: a reading must have the codename and the created_at time", line)
+		}
+
+		if first.IsZero() {
+			first = reading.CreatedAt
+		}
+
+		due := start.Add(reading.CreatedAt.Sub(first))
+		if !r.wait(due) {
+			return replayed, r.ctx.Err()
+		}
+
+		sensor, err := r.sensor(reading)
+		if err != nil {
+			return replayed, fmt.Errorf("recording line %d: %w", line, err)
+		}
+
+		measurement := domain.Measurement{
+			SensorID:     sensor.ID,
+			Temperature:  reading.Temperature,
+			Transparency: reading.Transparency,
+			DetectedFish: make([]domain.DetectedFish, 0, len(reading.DetectedFish)),
+			CreatedAt:    reading.CreatedAt.UTC(),
+			Fault:        reading.Fault,
+		}
+
+		if !r.cfg.Replay.KeepTime {
+			measurement.CreatedAt = due.UTC()
+		}
+
+		for _, fish := range reading.DetectedFish {
+			measurement.DetectedFish = append(measurement.DetectedFish, domain.DetectedFish{Name: fish.Name, Count: fish.Count})
+		}
+
+		var anomalies []domain.Anomaly
+		if r.scorer != nil {
+			anomalies = r.scorer.Score(&measurement)
+		}
+
+		saved, err := r.DB.SaveMeasurement(r.ctx, measurement)
+		if err != nil {
+			return replayed, fmt.Errorf("recording line %d: %w", line, err)
+		}
+
+		replayedReading := domain.NewReading(sensor, *saved)
+
+		if r.publisher != nil {
+			r.publisher.Publish(replayedReading)
+		}
+
+		if r.scorer != nil {
+			r.scorer.Flag(replayedReading, anomalies)
+		}
+
+		replayed++
+	}
+
+	if err := scanner.Err(); err != nil {
+		return replayed, fmt.Errorf("read recording: %w", err)
+	}
+
+	return replayed, nil
+}
+
+// wait returns false if the replay is cancelled before the time.
+func (r *Replay) wait(due time.Time) bool {
+	wait := due.Sub(r.clock.Now())
+	if wait <= 0 {
+		return r.ctx.Err() == nil
+	}
+
+	ticker := r.clock.NewTicker(wait)
+	defer ticker.Stop()
+
+	select {
+	case <-ticker.C():
+		return true
+	case <-r.ctx.Done():
+		return false
+	}
+}
+
+// sensor returns the sensor of the reading, creating it if it does not exist yet.
+func (r *Replay) sensor(reading domain.Reading) (domain.Sensor, error) {
+	if sensor, ok := r.sensors[reading.Codename]; ok {
+		return sensor, nil
+	}
+
+	group, inGroupID := utils.ParseCodename(reading.Codename)
+
+	sensor, err := r.DB.GetSensor(r.ctx, group, inGroupID)
+	if errors.Is(err, storage.ErrSensorNotFound) {
+		sensor, err = r.createSensor(reading, group, inGroupID)
+	}
+	if err != nil {
+		return domain.Sensor{}, err
+	}
+
+	r.sensors[reading.Codename] = *sensor
+
+	return *sensor, nil
+}
+
+func (r *Replay) createSensor(reading domain.Reading, group string, inGroupID int) (*domain.Sensor, error) {
+	if _, err := r.DB.CreateGroup(r.ctx, group); err != nil && !errors.Is(err, storage.ErrGroupExists) {
+		return nil, err
+	}
+
+	r.log.Infof("creating sensor %s of the recording", reading.Codename)
+
+	return r.DB.CreateSensor(r.ctx, domain.Sensor{
+		Source:         domain.SourceLive,
+		Codename:       domain.Codename{Name: group, SensorGroupID: inGroupID},
+		Coordinates:    reading.Coordinates,
+		DataOutputRate: r.cfg.Replay.DataOutputRate,
+		Temperature:    reading.Temperature,
+		Transparency:   reading.Transparency,
+	})
+}
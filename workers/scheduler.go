@@ -0,0 +1,127 @@
+package workers
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+)
+
+// scheduled is a simulated sensor in the schedule.
+type scheduled struct {
+	data    *sensorData
+	rate    time.Duration
+	next    time.Time
+	index   int
+	shard   int
+	stopped atomic.Bool
+}
+
+// schedule is the min-heap of the sensors by the time their next reading is due.
+type schedule []*scheduled
+
+func (s schedule) Len() int { return len(s) }
+
+func (s schedule) Less(i, j int) bool { return s[i].next.Before(s[j].next) }
+
+func (s schedule) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+	s[i].index = i
+	s[j].index = j
+}
+
+func (s *schedule) Push(x any) {
+	sensor := x.(*scheduled)
+	sensor.index = len(*s)
+	*s = append(*s, sensor)
+}
+
+func (s *schedule) Pop() any {
+	old := *s
+	sensor := old[len(old)-1]
+	old[len(old)-1] = nil
+	*s = old[:len(old)-1]
+
+	return sensor
+}
+
+type due struct {
+	sensor *scheduled
+	at     time.Time
+}
+
+type schedulerStats struct {
+	mu      sync.Mutex
+	written uint64
+	lost    uint64
+	failed  uint64
+	retries uint64
+	batches uint64
+	lagSum  time.Duration
+	lagMax  time.Duration
+	lagLast time.Duration
+}
+
+func (s *schedulerStats) batch(lags []time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.batches++
+	s.lagLast = 0
+
+	for _, lag := range lags {
+		s.written++
+		s.lagSum += lag
+
+		if lag > s.lagLast {
+			s.lagLast = lag
+		}
+
+		if lag > s.lagMax {
+			s.lagMax = lag
+		}
+	}
+}
+
+func (s *schedulerStats) lose(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lost += uint64(n)
+}
+
+func (s *schedulerStats) retry() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.retries++
+}
+
+func (s *schedulerStats) fail(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failed += uint64(n)
+}
+
+func (s *schedulerStats) fill(stats *domain.SchedulerStats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats.Written = s.written
+	stats.Lost = s.lost
+	stats.Failed = s.failed
+	stats.Retries = s.retries
+	stats.Batches = s.batches
+	stats.LagLast = milliseconds(s.lagLast)
+	stats.LagMax = milliseconds(s.lagMax)
+
+	if s.written > 0 {
+		stats.LagAvg = milliseconds(s.lagSum / time.Duration(s.written))
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
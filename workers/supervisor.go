@@ -0,0 +1,162 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/pkg/logging"
+)
+
+// Supervisor runs the long-lived tasks of the service and restarts the failed ones after a backoff.
+type Supervisor struct {
+	ctx context.Context
+	log logging.Logger
+	cfg config.Config
+
+	mu    sync.Mutex
+	tasks []*domain.TaskStatus
+	wg    sync.WaitGroup
+}
+
+func NewSupervisor(ctx context.Context, log logging.Logger, cfg config.Config) *Supervisor {
+	return &Supervisor{
+		ctx: ctx,
+		log: log,
+		cfg: cfg,
+	}
+}
+
+// Go runs the task until it returns nil, a nil supervisor runs it once.
+func (s *Supervisor) Go(name string, run func() error) {
+	if s == nil {
+		go func() {
+			_ = run()
+		}()
+		return
+	}
+
+	status := &domain.TaskStatus{Name: name, State: domain.TaskRunning, Since: time.Now()}
+
+	s.mu.Lock()
+	s.tasks = append(s.tasks, status)
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+		s.supervise(status, run)
+	}()
+}
+
+func (s *Supervisor) supervise(status *domain.TaskStatus, run func() error) {
+	backoff := s.cfg.Supervisor.RestartBackoff
+	failures := 0
+
+	for {
+		started := time.Now()
+
+		err := runTask(run)
+		if err == nil || s.ctx.Err() != nil {
+			s.update(status, domain.TaskStopped, err)
+			return
+		}
+
+		// a task which ran long enough before it failed starts its backoff over
+		if time.Since(started) > s.cfg.Supervisor.MaxBackoff {
+			backoff = s.cfg.Supervisor.RestartBackoff
+			failures = 0
+		}
+
+		failures++
+
+		if s.cfg.Supervisor.MaxRestarts > 0 && failures > s.cfg.Supervisor.MaxRestarts {
+			s.log.Errorf("task %s failed %d times in a row, giving up: %v", status.Name, failures, err)
+			s.update(status, domain.TaskFailed, err)
+			return
+		}
+
+		s.log.Errorf("task %s failed, restarting in %s: %v", status.Name, backoff, err)
+		s.update(status, domain.TaskRestarting, err)
+
+		timer := time.NewTimer(backoff)
+
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			s.update(status, domain.TaskStopped, err)
+			return
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > s.cfg.Supervisor.MaxBackoff {
+			backoff = s.cfg.Supervisor.MaxBackoff
+		}
+
+		s.mu.Lock()
+		status.Restarts++
+		s.mu.Unlock()
+
+		s.update(status, domain.TaskRunning, err)
+	}
+}
+
+func runTask(run func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return run()
+}
+
+func (s *Supervisor) update(status *domain.TaskStatus, state domain.TaskState, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status.State = state
+	status.Since = time.Now()
+
+	if err != nil {
+		status.LastError = err.Error()
+	}
+}
+
+// Tasks returns the states of the tasks in the order they were started.
+func (s *Supervisor) Tasks() []domain.TaskStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tasks := make([]domain.TaskStatus, 0, len(s.tasks))
+	for _, task := range s.tasks {
+		tasks = append(tasks, *task)
+	}
+
+	return tasks
+}
+
+// Wait reports whether every task returned within the timeout.
+func (s *Supervisor) Wait(timeout time.Duration) bool {
+	done := make(chan struct{})
+
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
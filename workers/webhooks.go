@@ -0,0 +1,307 @@
+package workers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/PavelDonchenko/sensor-go/config"
+	"github.com/PavelDonchenko/sensor-go/internal/domain"
+	"github.com/PavelDonchenko/sensor-go/internal/storage"
+	"github.com/PavelDonchenko/sensor-go/internal/stream"
+	"github.com/PavelDonchenko/sensor-go/pkg/logging"
+)
+
+const maxResponseBody = 64 << 10
+
+type delivery struct {
+	webhook domain.Webhook
+	event   domain.Event
+	payload []byte
+	attempt int
+}
+
+// WebhookDispatcher POSTs the readings and the service events to the subscribed webhooks.
+type WebhookDispatcher struct {
+	DB     storage.SensorPostgres
+	ctx    context.Context
+	log    logging.Logger
+	cfg    config.Config
+	hub    *stream.Hub
+	client *http.Client
+
+	webhooks   []domain.Webhook
+	deliveries chan delivery
+
+	mu      sync.Mutex
+	retries map[*time.Timer]delivery
+}
+
+func NewWebhookDispatcher(ctx context.Context, DB storage.SensorPostgres, log logging.Logger, cfg config.Config,
+	hub *stream.Hub) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		DB:         DB,
+		ctx:        ctx,
+		log:        log,
+		cfg:        cfg,
+		hub:        hub,
+		client:     &http.Client{Timeout: cfg.Webhooks.Timeout},
+		deliveries: make(chan delivery, cfg.Webhooks.BufferSize),
+		retries:    make(map[*time.Timer]delivery),
+	}
+}
+
+func (d *WebhookDispatcher) Process() {
+	readings := d.hub.Subscribe(stream.Filter{}, d.cfg.Webhooks.BufferSize)
+	defer d.hub.Unsubscribe(readings)
+
+	events := d.hub.SubscribeEvents(d.cfg.Webhooks.BufferSize)
+	defer d.hub.UnsubscribeEvents(events)
+
+	var wg sync.WaitGroup
+	defer d.deadLetterPending()
+	defer wg.Wait()
+
+	for i := 0; i < d.cfg.Webhooks.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.deliver()
+		}()
+	}
+
+	d.reloadWebhooks()
+
+	ticker := time.NewTicker(d.cfg.Webhooks.ReloadInterval)
+	defer ticker.Stop()
+
+	var droppedReadings, droppedEvents uint64
+
+	for {
+		select {
+		case reading, ok := <-readings.C():
+			if !ok {
+				return
+			}
+			d.dispatch(domain.EventReadingCreated, reading)
+		case event, ok := <-events.C():
+			if !ok {
+				return
+			}
+			d.dispatch(event.Type, event)
+		case <-ticker.C:
+			d.reloadWebhooks()
+
+			if n := readings.Dropped(); n > droppedReadings {
+				d.log.Errorf("webhook dispatch lags behind, %d readings are not dispatched", n-droppedReadings)
+				droppedReadings = n
+			}
+
+			if n := events.Dropped(); n > droppedEvents {
+				d.log.Errorf("webhook dispatch lags behind, %d events are not dispatched", n-droppedEvents)
+				droppedEvents = n
+			}
+		case <-d.ctx.Done():
+			return
+		}
+	}
+}
+
+func (d *WebhookDispatcher) reloadWebhooks() {
+	webhooks, err := d.DB.GetWebhooks(d.ctx)
+	if err != nil {
+		d.log.Error(err)
+		return
+	}
+
+	d.webhooks = webhooks
+}
+
+func (d *WebhookDispatcher) dispatch(eventType domain.EventType, data any) {
+	var event domain.Event
+	var payload []byte
+
+	for _, webhook := range d.webhooks {
+		if !webhook.Subscribed(eventType) {
+			continue
+		}
+
+		if payload == nil {
+			var ok bool
+			if event, ok = data.(domain.Event); !ok {
+				event = domain.NewEvent(eventType, data)
+			}
+
+			var err error
+			payload, err = json.Marshal(event)
+			if err != nil {
+				d.log.Error(err)
+				return
+			}
+		}
+
+		d.enqueue(delivery{webhook: webhook, event: event, payload: payload, attempt: 1})
+	}
+}
+
+// enqueue never blocks, a delivery which does not fit is dead-lettered.
+func (d *WebhookDispatcher) enqueue(del delivery) {
+	select {
+	case d.deliveries <- del:
+	default:
+		d.deadLetter(d.ctx, del, del.attempt-1, "delivery queue is full")
+	}
+}
+
+func (d *WebhookDispatcher) deliver() {
+	for {
+		select {
+		case del := <-d.deliveries:
+			err := d.post(del)
+			if err == nil {
+				continue
+			}
+
+			if del.attempt >= d.cfg.Webhooks.MaxAttempts {
+				d.deadLetter(d.ctx, del, del.attempt, err.Error())
+				continue
+			}
+
+			retry := del
+			retry.attempt++
+			d.retry(retry, d.backoff(del.attempt))
+		case <-d.ctx.Done():
+			return
+		}
+	}
+}
+
+func (d *WebhookDispatcher) retry(del delivery, delay time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		// dead-lettered by the stopping dispatcher
+		if _, ok := d.retries[timer]; !ok {
+			return
+		}
+
+		delete(d.retries, timer)
+		d.enqueue(del)
+	})
+
+	d.retries[timer] = del
+}
+
+// deadLetterPending dead-letters the queued deliveries and the waiting retries.
+func (d *WebhookDispatcher) deadLetterPending() {
+	d.mu.Lock()
+	retries := d.retries
+	d.retries = make(map[*time.Timer]delivery)
+	d.mu.Unlock()
+
+	// the context of the dispatcher is cancelled by now
+	ctx, cancel := context.WithTimeout(context.Background(), d.cfg.Supervisor.WriteTimeout)
+	defer cancel()
+
+	for timer, del := range retries {
+		timer.Stop()
+		d.deadLetter(ctx, del, del.attempt-1, "the dispatcher stopped")
+	}
+
+	for {
+		select {
+		case del := <-d.deliveries:
+			d.deadLetter(ctx, del, del.attempt-1, "the dispatcher stopped")
+		default:
+			return
+		}
+	}
+}
+
+func (d *WebhookDispatcher) backoff(attempt int) time.Duration {
+	delay := d.cfg.Webhooks.RetryDelay
+
+	for i := 1; i < attempt && delay < d.cfg.Webhooks.MaxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > d.cfg.Webhooks.MaxRetryDelay {
+		delay = d.cfg.Webhooks.MaxRetryDelay
+	}
+
+	return delay
+}
+
+func (d *WebhookDispatcher) post(del delivery) error {
+	record := domain.WebhookDelivery{
+		WebhookID: del.webhook.ID,
+		EventID:   del.event.ID,
+		EventType: del.event.Type,
+		Attempt:   del.attempt,
+	}
+
+	start := time.Now()
+	err := d.send(del, &record)
+	record.DurationMs = time.Since(start).Milliseconds()
+
+	if err != nil {
+		record.Error = err.Error()
+	} else {
+		record.Success = true
+	}
+
+	_ = d.DB.SaveWebhookDelivery(d.ctx, record)
+
+	return err
+}
+
+func (d *WebhookDispatcher) send(del delivery, record *domain.WebhookDelivery) error {
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, del.webhook.URL, bytes.NewReader(del.payload))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(domain.HeaderWebhookEvent, string(del.event.Type))
+	req.Header.Set(domain.HeaderWebhookDelivery, del.event.ID.String())
+	req.Header.Set(domain.HeaderWebhookSignature, domain.SignPayload(del.webhook.Secret, del.payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBody))
+
+	record.StatusCode = &resp.StatusCode
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (d *WebhookDispatcher) deadLetter(ctx context.Context, del delivery, attempts int, reason string) {
+	d.log.Errorf("webhook %d: event %s is not delivered: %s", del.webhook.ID, del.event.ID, reason)
+
+	_ = d.DB.SaveDeadLetter(ctx, domain.DeadLetter{
+		WebhookID: del.webhook.ID,
+		EventID:   del.event.ID,
+		EventType: del.event.Type,
+		Payload:   del.payload,
+		Attempts:  attempts,
+		Error:     reason,
+	})
+}
@@ -1,33 +1,64 @@
 package workers
 
 import (
+	"container/heap"
 	"context"
-	"math/rand"
+	"encoding/binary"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/PavelDonchenko/sensor-go/config"
 	"github.com/PavelDonchenko/sensor-go/internal/domain"
 	"github.com/PavelDonchenko/sensor-go/internal/storage"
+	"github.com/PavelDonchenko/sensor-go/internal/stream"
 	"github.com/PavelDonchenko/sensor-go/pkg/generations"
 	"github.com/PavelDonchenko/sensor-go/pkg/logging"
+	"github.com/PavelDonchenko/sensor-go/pkg/postgres"
+	"github.com/google/uuid"
 )
 
+// Worker generates the data of the simulated sensors.
 type Worker struct {
-	DB        storage.SensorPostgres
-	ctx       context.Context
-	log       logging.Logger
-	cfg       config.Config
-	errorChan chan error
+	DB         storage.SensorPostgres
+	ctx        context.Context
+	log        logging.Logger
+	cfg        config.Config
+	publisher  stream.Publisher
+	scorer     Scorer
+	clock      generations.Clock
+	generator  *generator
+	supervisor *Supervisor
+
+	mu       sync.Mutex
+	schedule schedule
+	sensors  map[uuid.UUID]*scheduled
+
+	queues []chan due
+	stats  schedulerStats
 }
 
-func NewWorker(ctx context.Context, DB storage.SensorPostgres, log logging.Logger, cfg config.Config) *Worker {
-	errChan := make(chan error)
+// NewWorker creates the data generation worker, the publisher, scorer, faults and scenario may be nil.
+func NewWorker(ctx context.Context, DB storage.SensorPostgres, log logging.Logger, cfg config.Config, publisher stream.Publisher,
+	scorer Scorer, species []domain.Species, faults *generations.Faults, scenario *generations.Scenario, clock generations.Clock,
+	supervisor *Supervisor) *Worker {
+	queues := make([]chan due, cfg.Scheduler.Workers)
+	for i := range queues {
+		queues[i] = make(chan due, cfg.Scheduler.QueueSize)
+	}
+
 	return &Worker{
-		DB:        DB,
-		ctx:       ctx,
-		log:       log,
-		cfg:       cfg,
-		errorChan: errChan,
+		DB:         DB,
+		ctx:        ctx,
+		log:        log,
+		cfg:        cfg,
+		publisher:  publisher,
+		scorer:     scorer,
+		clock:      clock,
+		generator:  newGenerator(cfg, species, faults, scenario, clock.Now()),
+		supervisor: supervisor,
+		sensors:    make(map[uuid.UUID]*scheduled),
+		queues:     queues,
 	}
 }
 
@@ -35,85 +66,419 @@ func (w *Worker) Process() {
 	sensors, err := w.DB.GetAllSensors(w.ctx)
 	if err != nil {
 		w.log.Error(err)
-		w.errorChan <- err
+		return
 	}
 
 	for _, sensor := range sensors {
-		go w.generateSensorData(sensor)
+		w.start(sensor)
+	}
+
+	// the ticker starts with the worker, not whenever the scheduler goroutine is scheduled
+	ticker := w.clock.NewTicker(w.cfg.Scheduler.Tick)
+
+	w.supervisor.Go("scheduler", func() error {
+		if ticker == nil {
+			ticker = w.clock.NewTicker(w.cfg.Scheduler.Tick)
+		}
+
+		defer func() {
+			ticker.Stop()
+			ticker = nil
+		}()
+
+		w.dispatch(ticker)
+
+		return nil
+	})
+
+	for i, queue := range w.queues {
+		queue := queue
+
+		w.supervisor.Go(fmt.Sprintf("writer %d", i+1), func() error {
+			w.write(queue)
+			return nil
+		})
+	}
+
+	if w.generator.scenario == nil {
+		return
+	}
+
+	for _, event := range w.generator.scenario.Events {
+		if event.At == 0 {
+			w.log.Infof("scenario event %s", event)
+			continue
+		}
+
+		go w.announce(event, w.clock.NewTicker(time.Duration(event.At)))
+	}
+}
+
+func (w *Worker) announce(event generations.ScenarioEvent, ticker generations.Ticker) {
+	defer ticker.Stop()
+
+	select {
+	case <-w.ctx.Done():
+	case <-ticker.C():
+		w.log.Infof("scenario event %s", event)
+	}
+}
+
+// SensorCreated starts generating data for the new sensor.
+func (w *Worker) SensorCreated(sensor domain.Sensor) {
+	w.start(sensor)
+}
+
+// SensorUpdated restarts the data generation of the sensor.
+func (w *Worker) SensorUpdated(sensor domain.Sensor) {
+	w.stop(sensor.ID)
+
+	if sensor.Source == domain.SourceLive || sensor.DataOutputRate <= 0 {
+		w.generator.forget(sensor.ID)
+		return
+	}
+
+	w.start(sensor)
+}
+
+// SensorDeleted stops generating data for the sensor.
+func (w *Worker) SensorDeleted(id uuid.UUID) {
+	w.stop(id)
+	w.generator.forget(id)
+}
+
+// SchedulerStats returns the metrics of the scheduler.
+func (w *Worker) SchedulerStats() domain.SchedulerStats {
+	w.mu.Lock()
+	stats := domain.SchedulerStats{Sensors: len(w.sensors), Workers: len(w.queues)}
+	w.mu.Unlock()
+
+	for _, queue := range w.queues {
+		stats.Queued += len(queue)
+	}
+
+	w.stats.fill(&stats)
+
+	return stats
+}
+
+func (w *Worker) start(sensor domain.Sensor) {
+	if sensor.Source == domain.SourceLive || sensor.DataOutputRate <= 0 {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.sensors[sensor.ID]; ok {
+		return
 	}
+
+	now := w.clock.Now()
+	rate := time.Duration(sensor.DataOutputRate) * time.Second
+
+	s := &scheduled{
+		data:  w.generator.follow(sensor, now),
+		rate:  rate,
+		next:  now.Add(rate),
+		shard: int(binary.BigEndian.Uint32(sensor.ID[:4]) % uint32(len(w.queues))),
+	}
+
+	w.sensors[sensor.ID] = s
+	heap.Push(&w.schedule, s)
 }
 
-func (w *Worker) generateSensorData(sensor domain.Sensor) {
-	duration := time.Duration(sensor.DataOutputRate) * time.Second
+func (w *Worker) stop(id uuid.UUID) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
 
-	ticker := time.NewTicker(duration)
+	if s, ok := w.sensors[id]; ok {
+		s.stopped.Store(true)
+		heap.Remove(&w.schedule, s.index)
+		delete(w.sensors, id)
+	}
+}
+
+// dispatch queues the due readings, a lagging scheduler catches up rather than skipping them.
+func (w *Worker) dispatch(ticker generations.Ticker) {
+	var dues []due
 
 	for {
 		select {
-		case <-ticker.C:
-			fishes, err := w.generateFishData(sensor)
-			if err != nil {
-				w.log.Error(err)
-				w.errorChan <- err
+		case <-w.ctx.Done():
+			return
+		case now := <-ticker.C():
+			dues = w.due(now, dues[:0])
+
+			for _, d := range dues {
+				select {
+				case w.queues[d.sensor.shard] <- d:
+				case <-w.ctx.Done():
+					return
+				}
 			}
+		}
+	}
+}
 
-			temperature := generations.GenerateTemperature(sensor.Coordinates.Z)
+func (w *Worker) due(now time.Time, dues []due) []due {
+	w.mu.Lock()
+	defer w.mu.Unlock()
 
-			err = w.DB.SaveTemperature(w.ctx, temperature, sensor.ID)
-			if err != nil {
-				w.errorChan <- err
-			}
+	for len(w.schedule) > 0 && !w.schedule[0].next.After(now) {
+		s := w.schedule[0]
+		dues = append(dues, due{sensor: s, at: s.next})
 
-			transparency := generations.GenerateTransparency(sensor.Transparency)
+		s.next = s.next.Add(s.rate)
+		heap.Fix(&w.schedule, 0)
+	}
 
-			toUpdate := domain.Sensor{
-				ID:           sensor.ID,
-				Temperature:  temperature,
-				Transparency: transparency,
-				DetectedFish: fishes,
-				UpdatedAt:    time.Now(),
+	return dues
+}
+
+func (w *Worker) write(queue <-chan due) {
+	batch := make([]due, 0, w.cfg.Scheduler.BatchSize)
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			w.drain(queue, batch)
+			return
+		case d := <-queue:
+			batch = append(batch[:0], d)
+
+		collect:
+			for len(batch) < w.cfg.Scheduler.BatchSize {
+				select {
+				case d = <-queue:
+					batch = append(batch, d)
+				default:
+					break collect
+				}
 			}
 
-			err = w.DB.UpdateSensorData(w.ctx, toUpdate)
-			if err != nil {
-				w.log.Error(err)
-				w.errorChan <- err
+			w.save(batch)
+		}
+	}
+}
+
+func (w *Worker) drain(queue <-chan due, batch []due) {
+	for {
+		batch = batch[:0]
+
+	collect:
+		for len(batch) < w.cfg.Scheduler.BatchSize {
+			select {
+			case d := <-queue:
+				batch = append(batch, d)
+			default:
+				break collect
 			}
-		case <-w.errorChan:
+		}
+
+		if len(batch) == 0 {
 			return
 		}
+
+		w.save(batch)
 	}
 }
 
-func (w *Worker) generateFishData(sensor domain.Sensor) ([]domain.DetectedFish, error) {
-	fishSpecies := []string{"Atlantic Cod", "Sailfish", "Tuna", "Salmon", "Marlin", "Barracuda"}
+func (w *Worker) save(batch []due) {
+	measurements := make([]domain.Measurement, 0, len(batch))
+	sensors := make([]domain.Sensor, 0, len(batch))
+	dueAt := make([]time.Time, 0, len(batch))
+	anomalies := make([][]domain.Anomaly, 0, len(batch))
 
-	var detectedFish []domain.DetectedFish
+	for _, d := range batch {
+		if d.sensor.stopped.Load() {
+			continue
+		}
 
-	numFish := rand.Intn(len(fishSpecies))
+		measurement, ok, err := w.measure(d)
+		if err != nil {
+			w.stats.fail(1)
+			continue
+		}
 
-	uniqueFish := make(map[string]bool)
+		if !ok {
+			w.stats.lose(1)
+			continue
+		}
 
-	for len(uniqueFish) < numFish {
-		fish := fishSpecies[rand.Intn(len(fishSpecies))]
-		if !uniqueFish[fish] {
-			count := rand.Intn(20) + 1
-			detectedFish = append(detectedFish, domain.DetectedFish{Name: fish, Count: count})
-			uniqueFish[fish] = true
+		var flagged []domain.Anomaly
+		if w.scorer != nil {
+			flagged = w.scorer.Score(&measurement)
 		}
+
+		measurements = append(measurements, measurement)
+		sensors = append(sensors, d.sensor.data.sensor)
+		dueAt = append(dueAt, d.at)
+		anomalies = append(anomalies, flagged)
 	}
 
-	var detectedFishes []domain.DetectedFish
+	if len(measurements) == 0 {
+		return
+	}
 
-	for _, fish := range detectedFish {
-		fish.SensorID = sensor.ID
-		detectedFish, err := w.DB.SaveDetectedFish(w.ctx, fish)
-		if err != nil {
+	w.persist(measurements, sensors, dueAt, anomalies)
+}
+
+// measure stops a sensor which panics.
+func (w *Worker) measure(d due) (measurement domain.Measurement, ok bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			sensor := d.sensor.data.sensor
+			err = fmt.Errorf("generate reading: %v", r)
+
+			w.log.Errorf("sensor %s stopped: %v", sensor.Codename, err)
+			w.stop(sensor.ID)
+			w.generator.forget(sensor.ID)
+			w.notify(domain.EventSensorFailed, sensor, err)
+		}
+	}()
+
+	measurement, ok = d.sensor.data.measure(d.at)
+
+	return measurement, ok, nil
+}
+
+// persist retries a failed bulk insert sensor by sensor, so only the readings of a deleted sensor are lost.
+func (w *Worker) persist(measurements []domain.Measurement, sensors []domain.Sensor, dueAt []time.Time,
+	anomalies [][]domain.Anomaly) {
+	saved, err := w.copy(measurements)
+
+	if postgres.IsForeignKeyViolation(err) {
+		groups := bySensor(sensors)
+
+		if len(groups) == 1 {
+			w.log.Warnf("sensor %s is deleted, %d of its readings are lost", sensors[0].Codename, len(measurements))
+			w.stats.lose(len(measurements))
+
+			return
+		}
+
+		for _, indexes := range groups {
+			sensorMeasurements := make([]domain.Measurement, 0, len(indexes))
+			sensorSensors := make([]domain.Sensor, 0, len(indexes))
+			sensorDueAt := make([]time.Time, 0, len(indexes))
+			sensorAnomalies := make([][]domain.Anomaly, 0, len(indexes))
+
+			for _, i := range indexes {
+				sensorMeasurements = append(sensorMeasurements, measurements[i])
+				sensorSensors = append(sensorSensors, sensors[i])
+				sensorDueAt = append(sensorDueAt, dueAt[i])
+				sensorAnomalies = append(sensorAnomalies, anomalies[i])
+			}
+
+			w.persist(sensorMeasurements, sensorSensors, sensorDueAt, sensorAnomalies)
+		}
+
+		return
+	}
+
+	if err != nil {
+		w.stats.fail(len(measurements))
+
+		for _, sensor := range sensors {
+			w.notify(domain.EventStorageError, sensor, err)
+		}
+
+		return
+	}
+
+	written := w.clock.Now()
+	lags := make([]time.Duration, 0, len(saved))
+
+	for i := range saved {
+		lags = append(lags, written.Sub(dueAt[i]))
+
+		reading := domain.NewReading(sensors[i], saved[i])
+
+		if w.publisher != nil {
+			w.publisher.Publish(reading)
+		}
+
+		if w.scorer != nil {
+			w.scorer.Flag(reading, anomalies[i])
+		}
+	}
+
+	w.stats.batch(lags)
+}
+
+func bySensor(sensors []domain.Sensor) [][]int {
+	groups := make([][]int, 0)
+	positions := make(map[uuid.UUID]int)
+
+	for i, sensor := range sensors {
+		position, ok := positions[sensor.ID]
+		if !ok {
+			position = len(groups)
+			positions[sensor.ID] = position
+			groups = append(groups, nil)
+		}
+
+		groups[position] = append(groups[position], i)
+	}
+
+	return groups
+}
+
+// copy retries the transient failures, the readings in flight are still written on a shutdown.
+func (w *Worker) copy(measurements []domain.Measurement) ([]domain.Measurement, error) {
+	// the ids are fixed before the first attempt, so a retry of a committed write does not duplicate it
+	for i := range measurements {
+		m := &measurements[i]
+		m.ID = uuid.New()
+		m.DetectedFish = append([]domain.DetectedFish(nil), m.DetectedFish...)
+
+		for j := range m.DetectedFish {
+			fish := &m.DetectedFish[j]
+			fish.ID = uuid.New()
+			fish.SensorID = m.SensorID
+			fish.MeasurementID = m.ID
+		}
+	}
+
+	backoff := w.cfg.Supervisor.RetryBackoff
+
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), w.cfg.Supervisor.WriteTimeout)
+		saved, err := w.DB.CopyMeasurements(ctx, measurements)
+		cancel()
+
+		// a conflict on its own ids means the failed attempt was committed
+		if attempt > 0 && postgres.IsUniqueViolation(err, "measurement_pkey") {
+			w.log.Warnf("the write of %d readings was committed by the previous attempt", len(measurements))
+			return measurements, nil
+		}
+
+		if err == nil || attempt >= w.cfg.Supervisor.WriteRetries || !postgres.IsTransient(err) {
+			return saved, err
+		}
+
+		w.stats.retry()
+		w.log.Warnf("retrying the write of %d readings in %s: %v", len(measurements), backoff, err)
+
+		select {
+		case <-time.After(backoff):
+		case <-w.ctx.Done():
 			return nil, err
 		}
 
-		detectedFishes = append(detectedFishes, *detectedFish)
+		backoff *= 2
+	}
+}
+
+func (w *Worker) notify(eventType domain.EventType, sensor domain.Sensor, err error) {
+	if w.publisher == nil {
+		return
 	}
 
-	return detectedFishes, nil
+	w.publisher.PublishEvent(domain.NewEvent(eventType, domain.SensorFailure{
+		SensorID: sensor.ID,
+		Codename: sensor.Codename.String(),
+		Error:    err.Error(),
+	}))
 }